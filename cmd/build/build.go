@@ -8,14 +8,20 @@ import (
 	"os"
 	"runtime"
 	"runtime/pprof"
+	"time"
 
+	"github.com/jschaf/jsc/pkg/config"
 	"github.com/jschaf/jsc/pkg/dirs"
 	"github.com/jschaf/jsc/pkg/log"
 	"github.com/jschaf/jsc/pkg/process"
 	"github.com/jschaf/jsc/pkg/sites"
 )
 
-var profileFlag = flag.String("cpu-profile", "", "write cpu profile to file")
+var (
+	profileFlag = flag.String("cpu-profile", "", "write cpu profile to file")
+	draftsFlag  = flag.Bool("drafts", false, "build draft posts under /drafts/")
+	nowFlag     = flag.String("now", "", "build as if the current time is this RFC 3339 time or date, to preview scheduled posts")
+)
 
 func main() {
 	process.RunMain(runMain)
@@ -24,9 +30,14 @@ func main() {
 func runMain(_ context.Context) error {
 	fset := flag.CommandLine
 	logLevel := log.DefineFlags(fset)
+	cfgFlags := config.DefineFlags(fset, config.EnvProd)
 	if err := fset.Parse(os.Args[1:]); err != nil {
 		return fmt.Errorf("parse flags: %w", err)
 	}
+	cfg, err := cfgFlags.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
 
 	slog.SetDefault(slog.New(log.NewDevHandler(os.Stderr, &slog.HandlerOptions{
 		Level: logLevel,
@@ -46,10 +57,32 @@ func runMain(_ context.Context) error {
 		defer pprof.StopCPUProfile()
 	}
 
+	// Clean dist since publish uploads every file in it.
+	opts := sites.RebuildOpts{Drafts: *draftsFlag, Clean: true}
+	if *nowFlag != "" {
+		now, err := parseNow(*nowFlag)
+		if err != nil {
+			return fmt.Errorf("parse now flag: %w", err)
+		}
+		opts.Now = func() time.Time { return now }
+	}
+
 	distDir := dirs.Dist
-	if err := sites.Rebuild(distDir); err != nil {
+	if err := sites.Rebuild(cfg, distDir, opts); err != nil {
 		slog.Error("rebuild site", "error", err)
 		return err
 	}
 	return nil
 }
+
+// parseNow parses an RFC 3339 time or a date in the local time zone.
+func parseNow(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("want RFC 3339 time or date: %w", err)
+	}
+	return t, nil
+}
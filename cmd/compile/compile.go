@@ -8,23 +8,44 @@ import (
 	"os"
 	"time"
 
+	"github.com/jschaf/jsc/pkg/config"
 	"github.com/jschaf/jsc/pkg/dirs"
 	"github.com/jschaf/jsc/pkg/log"
+	"github.com/jschaf/jsc/pkg/markdown/catalog"
 	"github.com/jschaf/jsc/pkg/markdown/compiler"
+	"github.com/jschaf/jsc/pkg/markdown/diag"
 	"github.com/jschaf/jsc/pkg/process"
 )
 
-var postGlobFlag = flag.String("glob", "", "if given, only compile files that match glob")
+var (
+	postGlobFlag = flag.String("glob", "", "if given, only compile files that match glob")
+	draftsFlag   = flag.Bool("drafts", false, "compile draft posts under /drafts/")
+)
 
-func compile(glob string) error {
+func compile(cfg *config.Config, glob string, drafts bool) error {
 	start := time.Now()
 	globStr := *postGlobFlag
 	if globStr == "" {
 		globStr = "all"
 	}
 	slog.Info("start compile", slog.String("glob", globStr))
-	c := compiler.NewDetailCompiler(dirs.Dist)
-	if err := c.Compile(glob); err != nil {
+	diags := &diag.Collector{}
+	cat, err := catalog.Load(glob, time.Now())
+	if cat == nil {
+		return fmt.Errorf("load catalog: %w", err)
+	}
+	diags.Add(err)
+	diags.AddAll(cat.Warnings()...)
+	if err := cat.Validate(); err != nil {
+		diags.Add(err)
+	} else {
+		if !drafts {
+			cat = cat.WithoutDrafts()
+		}
+		c := compiler.NewDetailCompiler(cfg, dirs.Dist)
+		diags.Add(c.Compile(cat))
+	}
+	if err := diag.Report(os.Stderr, diags.List()); err != nil {
 		return fmt.Errorf("compile detail posts: %w", err)
 	}
 	slog.Info("finish compile", slog.Duration("duration", time.Since(start)))
@@ -41,15 +62,20 @@ func runMain(ctx context.Context) error {
 
 	fset := flag.CommandLine
 	logLevel := log.DefineFlags(fset)
+	cfgFlags := config.DefineFlags(fset, config.EnvDev)
 	if err := fset.Parse(os.Args[1:]); err != nil {
 		return fmt.Errorf("parse flags: %w", err)
 	}
+	cfg, err := cfgFlags.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
 
 	slog.SetDefault(slog.New(log.NewDevHandler(os.Stderr, &slog.HandlerOptions{
 		Level: logLevel,
 	})))
 
-	if err := compile(*postGlobFlag); err != nil {
+	if err := compile(cfg, *postGlobFlag, *draftsFlag); err != nil {
 		return fmt.Errorf("compile: %w", err)
 	}
 	return nil
@@ -0,0 +1,107 @@
+// linkcheck checks the external links in posts, records each check in the
+// link archive and reports dead and moved links with the post and line.
+// Records of dead links make posts link to an archived copy.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jschaf/jsc/pkg/dirs"
+	"github.com/jschaf/jsc/pkg/git"
+	"github.com/jschaf/jsc/pkg/markdown"
+	"github.com/jschaf/jsc/pkg/markdown/catalog"
+	"github.com/jschaf/jsc/pkg/markdown/diag"
+	"github.com/jschaf/jsc/pkg/markdown/linkio"
+	"github.com/jschaf/jsc/pkg/process"
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	globFlag        = flag.String("glob", "", "only check links in posts whose path contains this string")
+	concurrencyFlag = flag.Int("concurrency", 8, "most links to check at once")
+	maxAgeFlag      = flag.Duration("max-age", 7*24*time.Hour, "check links again if the last check is older than this; 0 checks every link")
+	timeoutFlag     = flag.Duration("timeout", 15*time.Second, "timeout to check a single link")
+)
+
+// checkLinks checks each link without a record newer than maxAge, at most
+// concurrency links at once, and records the result in the archive. Returns
+// the record of every link.
+func checkLinks(ctx context.Context, checker linkio.Checker, archive *linkio.Archive, urls []string, maxAge time.Duration, concurrency int) (map[string]linkio.ArchiveRecord, error) {
+	records := make(map[string]linkio.ArchiveRecord, len(urls))
+	mu := sync.Mutex{}
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(max(1, concurrency))
+	for _, u := range urls {
+		g.Go(func() error {
+			rec, ok, err := archive.Get(u)
+			if err != nil {
+				return err
+			}
+			if !ok || maxAge == 0 || time.Since(rec.CheckedAt) > maxAge {
+				res := checker.Check(ctx, u)
+				if rec, err = archive.Put(res); err != nil {
+					return err
+				}
+				slog.Debug("checked link", "url", u, "status", rec.StatusCode, "error", rec.Err)
+			}
+			mu.Lock()
+			records[u] = rec
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, fmt.Errorf("check links: %w", err)
+	}
+	return records, nil
+}
+
+func main() {
+	process.RunMain(runMain)
+}
+
+func runMain(ctx context.Context) error {
+	flag.Parse()
+	cat, err := catalog.Load(*globFlag, time.Now())
+	if err != nil {
+		return fmt.Errorf("load catalog: %w", err)
+	}
+
+	links := make(map[*markdown.AST][]linkio.ExternalLink, len(cat.Posts))
+	var urls []string
+	seen := make(map[string]struct{})
+	for _, p := range cat.Posts {
+		links[p] = linkio.ExternalLinks(p.Node, p.Source)
+		for _, l := range links[p] {
+			if _, ok := seen[l.URL]; !ok {
+				seen[l.URL] = struct{}{}
+				urls = append(urls, l.URL)
+			}
+		}
+	}
+
+	archive := linkio.NewArchive(filepath.Join(git.RootDir(), dirs.LinkArchive))
+	checker := linkio.HTTPChecker{Client: &http.Client{Timeout: *timeoutFlag}}
+	start := time.Now()
+	records, err := checkLinks(ctx, checker, archive, urls, *maxAgeFlag, *concurrencyFlag)
+	if err != nil {
+		return err
+	}
+	slog.Info("checked links", "count", len(urls), "duration", time.Since(start))
+
+	diags := &diag.Collector{}
+	for _, p := range cat.Posts {
+		if ds := linkio.Diagnose(links[p], records); len(ds) > 0 {
+			diags.AddFile(p.Path, p.Source, ds)
+		}
+	}
+	return diag.Report(os.Stdout, diags.List())
+}
@@ -0,0 +1,93 @@
+// previews fetches the previews of the http links in posts into the
+// checked-in link cache. Builds only read the cache, so run previews after
+// adding links to show a preview on hover.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/jschaf/jsc/pkg/dirs"
+	"github.com/jschaf/jsc/pkg/git"
+	"github.com/jschaf/jsc/pkg/markdown/catalog"
+	"github.com/jschaf/jsc/pkg/markdown/linkio"
+	"github.com/jschaf/jsc/pkg/process"
+	"github.com/yuin/goldmark/ast"
+)
+
+var (
+	globFlag    = flag.String("glob", "", "only fetch links in posts whose path contains this string")
+	refreshFlag = flag.Bool("refresh", false, "fetch cached previews again")
+)
+
+// missingLinks returns the http links in the node that need a preview, in
+// document order without duplicates. A link needs a preview if it has no
+// preview, or if it has a cached preview and refresh is true. Links with a
+// "::: preview" colon block never need a preview.
+func missingLinks(node ast.Node, cache *linkio.Cache, refresh bool) ([]string, error) {
+	var links []string
+	seen := make(map[string]struct{})
+	err := ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		link, ok := n.(*ast.Link)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		dest := string(link.Destination)
+		if _, ok := seen[dest]; ok || !linkio.IsPreviewable(dest) || path.Ext(dest) == ".pdf" {
+			return ast.WalkContinue, nil
+		}
+		seen[dest] = struct{}{}
+		_, hasPreview := link.AttributeString("data-preview-snippet")
+		_, cached, err := cache.Get(dest)
+		if err != nil {
+			return ast.WalkStop, err
+		}
+		if !hasPreview || (refresh && cached) {
+			links = append(links, dest)
+		}
+		return ast.WalkContinue, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk links: %w", err)
+	}
+	return links, nil
+}
+
+func main() {
+	process.RunMain(runMain)
+}
+
+func runMain(ctx context.Context) error {
+	flag.Parse()
+	cat, err := catalog.Load(*globFlag, time.Now())
+	if err != nil {
+		return fmt.Errorf("load catalog: %w", err)
+	}
+	cache := linkio.NewCache(filepath.Join(git.RootDir(), dirs.LinkCache))
+	snippeter := linkio.NewSnippeter(cache, &http.Client{Timeout: 10 * time.Second})
+
+	fetched, failed := 0, 0
+	for _, p := range cat.Posts {
+		links, err := missingLinks(p.Node, cache, *refreshFlag)
+		if err != nil {
+			return fmt.Errorf("find links in %s: %w", p.Path, err)
+		}
+		for _, link := range links {
+			if _, err := snippeter.Snippet(ctx, link, *refreshFlag); err != nil {
+				// A dead or unsupported link shouldn't stop fetching the rest.
+				slog.Warn("fetch link preview", "post", p.Meta.Slug, "error", err)
+				failed++
+				continue
+			}
+			fetched++
+		}
+	}
+	slog.Info("fetched link previews", "fetched", fetched, "failed", failed)
+	return nil
+}
@@ -6,41 +6,61 @@ import (
 	"fmt"
 	"golang.org/x/oauth2/google"
 	"log/slog"
+	"net/http"
 	"os"
+	"path/filepath"
+	"sort"
 	"time"
 
+	"github.com/jschaf/jsc/pkg/config"
 	"github.com/jschaf/jsc/pkg/dirs"
 	"github.com/jschaf/jsc/pkg/firebase"
 	"github.com/jschaf/jsc/pkg/log"
+	"github.com/jschaf/jsc/pkg/markdown/catalog"
 	"github.com/jschaf/jsc/pkg/process"
 	"golang.org/x/net/context"
 	hosting "google.golang.org/api/firebasehosting/v1beta1"
 )
 
-const (
-	siteName   = "jschaf"
-	siteParent = "sites/" + siteName
-)
-
 func main() {
 	process.RunMain(runMain)
 }
 
+// siteParent returns the Firebase resource name of the hosting site.
+func siteParent(cfg *config.Config) string {
+	return "sites/" + cfg.Firebase.Site
+}
+
 // servingConfig returns the known fields for the Firebase hosting config. This
-// corresponds to the hosting field in firebase.json.
-func servingConfig() *hosting.ServingConfig {
-	return &hosting.ServingConfig{
-		TrailingSlashBehavior: "REMOVE",
-		Rewrites: []*hosting.Rewrite{
-			{
-				Glob: "/_/heap/**",
-				Run: &hosting.CloudRunRewrite{
-					Region:    "us-west2",
-					ServiceId: "track-server",
-				},
+// corresponds to the hosting field in firebase.json. Redirects maps the old URL
+// path of a post, from the aliases front matter, to the current URL path.
+func servingConfig(cfg *config.Config, redirects map[string]string) *hosting.ServingConfig {
+	sc := &hosting.ServingConfig{
+		TrailingSlashBehavior: cfg.Firebase.TrailingSlash,
+	}
+	froms := make([]string, 0, len(redirects))
+	for from := range redirects {
+		froms = append(froms, from)
+	}
+	sort.Strings(froms)
+	for _, from := range froms {
+		sc.Redirects = append(sc.Redirects, &hosting.Redirect{
+			// Match with and without the trailing slash.
+			Glob:       from + "{,/}",
+			Location:   redirects[from],
+			StatusCode: http.StatusMovedPermanently,
+		})
+	}
+	for _, r := range cfg.Firebase.Rewrites {
+		sc.Rewrites = append(sc.Rewrites, &hosting.Rewrite{
+			Glob: r.Glob,
+			Run: &hosting.CloudRunRewrite{
+				Region:    r.Region,
+				ServiceId: r.ServiceID,
 			},
-		},
+		})
 	}
+	return sc
 }
 
 func runMain(ctx context.Context) error {
@@ -49,9 +69,14 @@ func runMain(ctx context.Context) error {
 
 	fset := flag.CommandLine
 	logLevel := log.DefineFlags(fset)
+	cfgFlags := config.DefineFlags(fset, config.EnvProd)
 	if err := fset.Parse(os.Args[1:]); err != nil {
 		return fmt.Errorf("parse flags: %w", err)
 	}
+	cfg, err := cfgFlags.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
 
 	slog.SetDefault(slog.New(log.NewDevHandler(os.Stderr, &slog.HandlerOptions{
 		Level: logLevel,
@@ -71,10 +96,23 @@ func runMain(ctx context.Context) error {
 	}
 	versionSvc := svc.Projects.Sites.Versions
 
+	cat, err := catalog.Load("", start)
+	if err != nil {
+		return fmt.Errorf("load catalog: %w", err)
+	}
+	if err := cat.Validate(); err != nil {
+		return fmt.Errorf("validate catalog: %w", err)
+	}
+
+	if err := logScheduledPosts(ctx, svc, cfg, cat, start); err != nil {
+		// Only informational, so don't block the deploy.
+		slog.Warn("find scheduled posts", "error", err)
+	}
+
 	// Create the version: we'll eventually release this version.
 	createVersionStart := time.Now()
-	createVersion := versionSvc.Create(siteParent, &hosting.Version{
-		Config: servingConfig(),
+	createVersion := versionSvc.Create(siteParent(cfg), &hosting.Version{
+		Config: servingConfig(cfg, cat.Redirects()),
 	})
 	createVersion.Context(ctx)
 	version, err := createVersion.Do()
@@ -126,7 +164,7 @@ func runMain(ctx context.Context) error {
 
 	// Release version: promote a version to release so it's shown on the website.
 	release := hosting.Release{}
-	createRelease := svc.Sites.Releases.Create(siteParent, &release)
+	createRelease := svc.Sites.Releases.Create(siteParent(cfg), &release)
 	createRelease.Context(ctx)
 	createRelease.VersionName(patchVersionResp.Name)
 	createReleaseResp, err := createRelease.Do()
@@ -138,3 +176,44 @@ func runMain(ctx context.Context) error {
 	slog.Info("completed deployment", "duration", time.Since(start))
 	return nil
 }
+
+// logScheduledPosts logs the posts with a publish_at time after the last
+// release, the scheduled posts that become visible with this deploy.
+func logScheduledPosts(ctx context.Context, svc *hosting.Service, cfg *config.Config, cat *catalog.Catalog, now time.Time) error {
+	since, err := lastReleaseTime(ctx, svc, cfg)
+	if err != nil {
+		return err
+	}
+	for _, p := range cat.Scheduled(since, now) {
+		dest := catalog.DetailDest(p)
+		if _, err := os.Stat(filepath.Join(dirs.Dist, dest)); err != nil {
+			// Built before publish_at so the post is still a draft in dist.
+			slog.Warn("scheduled post not in dist; rebuild to publish", "slug", p.Meta.Slug, "publish_at", p.Meta.PublishAt)
+			continue
+		}
+		slog.Info("scheduled post becomes visible", "slug", p.Meta.Slug, "publish_at", p.Meta.PublishAt)
+	}
+	return nil
+}
+
+// lastReleaseTime returns the time of the most recent release of the site, or
+// the zero time if the site has no releases.
+func lastReleaseTime(ctx context.Context, svc *hosting.Service, cfg *config.Config) (time.Time, error) {
+	list := svc.Sites.Releases.List(siteParent(cfg)).PageSize(10)
+	list.Context(ctx)
+	resp, err := list.Do()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("list releases: %w", err)
+	}
+	last := time.Time{}
+	for _, r := range resp.Releases {
+		t, err := time.Parse(time.RFC3339Nano, r.ReleaseTime)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("parse release time of %s: %w", r.Name, err)
+		}
+		if t.After(last) {
+			last = t
+		}
+	}
+	return last, nil
+}
@@ -0,0 +1,82 @@
+// related prints the related posts of each post with how each score adds up,
+// to debug the related posts shown at the end of each post.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jschaf/jsc/pkg/config"
+	"github.com/jschaf/jsc/pkg/markdown"
+	"github.com/jschaf/jsc/pkg/markdown/catalog"
+	"github.com/jschaf/jsc/pkg/process"
+)
+
+var (
+	slugFlag  = flag.String("slug", "", "only print the related posts of the post with this slug")
+	countFlag = flag.Int("count", 0, "most related posts to print for each post; defaults to related.count in the site config")
+)
+
+func writeRelated(w io.Writer, posts []*markdown.AST, related map[*markdown.AST][]catalog.Relation) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "Post\tRelated\tScore")
+	for _, p := range posts {
+		rels := related[p]
+		if len(rels) == 0 {
+			_, _ = fmt.Fprintf(tw, "%s\t-\t\n", p.Meta.Slug)
+			continue
+		}
+		for i, r := range rels {
+			slug := p.Meta.Slug
+			if i > 0 {
+				slug = ""
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", slug, r.Post.Meta.Slug, r.Explain())
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("flush related table: %w", err)
+	}
+	return nil
+}
+
+func main() {
+	process.RunMain(runMain)
+}
+
+func runMain(_ context.Context) error {
+	fset := flag.CommandLine
+	cfgFlags := config.DefineFlags(fset, config.EnvProd)
+	if err := fset.Parse(os.Args[1:]); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+	cfg, err := cfgFlags.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	count := cfg.Related.Count
+	if *countFlag > 0 {
+		count = *countFlag
+	}
+
+	cat, err := catalog.Load("", time.Now())
+	if err != nil {
+		return fmt.Errorf("load catalog: %w", err)
+	}
+	related := cat.Related(count)
+	var posts []*markdown.AST
+	for _, p := range cat.Posts {
+		if *slugFlag == "" || p.Meta.Slug == *slugFlag {
+			posts = append(posts, p)
+		}
+	}
+	if len(posts) == 0 {
+		return fmt.Errorf("no post with slug %q", *slugFlag)
+	}
+	return writeRelated(os.Stdout, posts, related)
+}
@@ -1,20 +1,27 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log/slog"
 	"net/http"
 	"path"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"github.com/jschaf/jsc/pkg/livereload"
 )
 
 type buildRoutesOpts struct {
 	distDir string
+	port    int
 	lr      *livereload.LiveReload
+	// redirects maps the alias URL paths of posts to the post URL path.
+	// Updated by each rebuild.
+	redirects *atomic.Pointer[map[string]string]
 }
 
 func buildRoutes(opts buildRoutesOpts) *http.ServeMux {
@@ -32,11 +39,12 @@ func buildRoutes(opts buildRoutesOpts) *http.ServeMux {
 	distDirHandler := &cleanFileServer{
 		root:        root,
 		baseHandler: http.FileServer(root),
+		redirects:   opts.redirects,
 	}
 
 	lrScript := strings.Join([]string{
-		fmt.Sprintf("<script defer src=%s?port=%s&path=%s type='application/javascript'>",
-			lrJSPath, port, strings.TrimLeft(lrPath, "/")),
+		fmt.Sprintf("<script defer src=%s?port=%d&path=%s type='application/javascript'>",
+			lrJSPath, opts.port, strings.TrimLeft(lrPath, "/")),
 		"</script>",
 	}, "")
 	mux.Handle("/", opts.lr.NewHTMLInjector(lrScript, distDirHandler))
@@ -44,10 +52,12 @@ func buildRoutes(opts buildRoutesOpts) *http.ServeMux {
 }
 
 // cleanFileServer is an http.FileServer that serves directories with an
-// index.html without the trailing slash to match the behavior of Firebase.
+// index.html without the trailing slash and redirects aliases of posts to
+// match the behavior of Firebase.
 type cleanFileServer struct {
 	root        http.FileSystem
 	baseHandler http.Handler
+	redirects   *atomic.Pointer[map[string]string]
 }
 
 func (c *cleanFileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -74,6 +84,16 @@ func (c *cleanFileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	name := path.Clean(upath)
 
 	f, err := c.root.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		// Firebase serves existing content before redirects, so only check
+		// aliases for missing paths.
+		if loc, ok := c.lookupRedirect(name); ok {
+			localRedirect(w, r, loc)
+			return
+		}
+		http.NotFound(w, r)
+		return
+	}
 	if err != nil {
 		http.Error(w, fmt.Errorf("open file: %w", err).Error(), http.StatusInternalServerError)
 		return
@@ -128,6 +148,19 @@ func (c *cleanFileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// lookupRedirect returns the URL path of the post for an alias URL path.
+func (c *cleanFileServer) lookupRedirect(name string) (string, bool) {
+	if c.redirects == nil {
+		return "", false
+	}
+	redirects := c.redirects.Load()
+	if redirects == nil {
+		return "", false
+	}
+	loc, ok := (*redirects)[name]
+	return loc, ok
+}
+
 // localRedirect gives a Moved Permanently response.
 // It does not convert relative paths to absolute paths like Redirect does.
 func localRedirect(w http.ResponseWriter, r *http.Request, newPath string) {
@@ -10,9 +10,12 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/jschaf/jsc/pkg/config"
 	"github.com/jschaf/jsc/pkg/dirs"
 	"github.com/jschaf/jsc/pkg/errs"
 	"github.com/jschaf/jsc/pkg/git"
@@ -25,14 +28,14 @@ import (
 	"golang.org/x/net/http2/h2c"
 )
 
-const port = "2222"
-
 // HTTPS server flags.
 var (
 	tlsCertPath = flag.String("tls-cert-path", "private/cert/localhost_cert.pem", "path to the TLS certificate file; if set, server uses https")
 	tlsKeyPath  = flag.String("tls-key-path", "private/cert/localhost_key.pem", "path to the TLS key file; if set, server uses https")
 )
 
+var draftsFlag = flag.Bool("drafts", false, "serve draft posts under /drafts/")
+
 type Server struct {
 	// Lifecycle context.
 	// Calling serverCancel causes all background goroutines to stop. To stop the
@@ -42,6 +45,7 @@ type Server struct {
 	// TLS
 	tlsCertPath string
 	tlsKeyPath  string
+	port        int
 	// Servers
 	httpSrv       *http.Server
 	liveReloadSrv *livereload.LiveReload
@@ -50,10 +54,13 @@ type Server struct {
 }
 
 type ServerOpts struct {
+	Config      *config.Config
 	DistDir     string
 	Cancel      context.CancelFunc
 	TLSCertPath string
 	TLSKeyPath  string
+	// Drafts builds draft posts under /drafts/.
+	Drafts bool
 }
 
 func InitServer(ctx context.Context, opts ServerOpts) (*Server, error) {
@@ -75,8 +82,15 @@ func InitServer(ctx context.Context, opts ServerOpts) (*Server, error) {
 	}
 
 	// Rebuild in case content changed since last run.
-	if err := sites.Rebuild(opts.DistDir); err != nil {
-		return nil, fmt.Errorf("rebuild site: %w", err)
+	redirects := &atomic.Pointer[map[string]string]{}
+	rebuildOpts := sites.RebuildOpts{Drafts: opts.Drafts, Redirects: redirects}
+	if err := sites.Rebuild(opts.Config, opts.DistDir, rebuildOpts); err != nil {
+		if !isPostErr(err) {
+			return nil, fmt.Errorf("rebuild site: %w", err)
+		}
+		// Serve the posts that compiled; the watcher rebuilds once the author
+		// fixes the reported problems.
+		slog.Error("rebuild site", "error", err)
 	}
 
 	// Live reload.
@@ -84,7 +98,7 @@ func InitServer(ctx context.Context, opts ServerOpts) (*Server, error) {
 	go lr.Start(ctx)
 
 	// File system watcher.
-	watcher := NewFSWatcher(opts.DistDir, lr)
+	watcher := NewFSWatcher(opts.Config, opts.DistDir, rebuildOpts, lr)
 	root := git.RootDir()
 	if err := watcher.watchDirs(
 		filepath.Join(root, dirs.Cmd),
@@ -105,8 +119,10 @@ func InitServer(ctx context.Context, opts ServerOpts) (*Server, error) {
 
 	// HTTP server.
 	routeHandler := buildRoutes(buildRoutesOpts{
-		distDir: opts.DistDir,
-		lr:      lr,
+		distDir:   opts.DistDir,
+		port:      opts.Config.Server.Port,
+		lr:        lr,
+		redirects: redirects,
 	})
 	h2s := &http2.Server{}
 	httpSrv := &http.Server{
@@ -119,6 +135,7 @@ func InitServer(ctx context.Context, opts ServerOpts) (*Server, error) {
 		// TLS
 		tlsCertPath: opts.TLSCertPath,
 		tlsKeyPath:  opts.TLSKeyPath,
+		port:        opts.Config.Server.Port,
 		// Servers
 		httpSrv:       httpSrv,
 		liveReloadSrv: lr,
@@ -132,16 +149,17 @@ func (s *Server) ListenAndServe() (mErr error) {
 		return fmt.Errorf("server context error: %w", err)
 	}
 
-	ln, err := net.Listen("tcp", "0.0.0.0:"+port)
+	addr := net.JoinHostPort("0.0.0.0", strconv.Itoa(s.port))
+	ln, err := net.Listen("tcp", addr)
 	if err != nil {
 		return fmt.Errorf("listen to http port: %w", err)
 	}
 	defer errs.Capture(&mErr, srv.NewListenerCloser(ln), "close http listener")
 
 	isTLS := s.tlsCertPath != ""
-	url := "http://localhost:" + port
+	url := "http://localhost:" + strconv.Itoa(s.port)
 	if isTLS {
-		url = "https://localhost:" + port
+		url = "https://localhost:" + strconv.Itoa(s.port)
 	}
 	slog.Info("ready: dev server listening", slog.String("url", url), slog.Bool("tls", isTLS))
 
@@ -179,9 +197,14 @@ func runMain(ctx context.Context) (mErr error) {
 
 	fset := flag.CommandLine
 	logLevel := log.DefineFlags(fset)
+	cfgFlags := config.DefineFlags(fset, config.EnvDev)
 	if err := fset.Parse(os.Args[1:]); err != nil {
 		return fmt.Errorf("parse flags: %w", err)
 	}
+	cfg, err := cfgFlags.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
 
 	slog.SetDefault(slog.New(log.NewDevHandler(os.Stderr, &slog.HandlerOptions{
 		Level: logLevel,
@@ -190,10 +213,12 @@ func runMain(ctx context.Context) (mErr error) {
 	slog.Info("start dev server", "process.args", os.Args[1:])
 
 	devSrv, err := InitServer(ctx, ServerOpts{
+		Config:      cfg,
 		DistDir:     dirs.Dist,
 		Cancel:      cancel,
 		TLSCertPath: *tlsCertPath,
 		TLSKeyPath:  *tlsKeyPath,
+		Drafts:      *draftsFlag,
 	})
 	if err != nil {
 		return fmt.Errorf("init server: %w", err)
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -13,10 +14,12 @@ import (
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/jschaf/jsc/pkg/config"
 	"github.com/jschaf/jsc/pkg/css"
 	"github.com/jschaf/jsc/pkg/errs"
 	"github.com/jschaf/jsc/pkg/git"
 	"github.com/jschaf/jsc/pkg/livereload"
+	"github.com/jschaf/jsc/pkg/markdown/diag"
 	"github.com/jschaf/jsc/pkg/sites"
 	"github.com/jschaf/jsc/pkg/static"
 )
@@ -26,18 +29,22 @@ import (
 type FSWatcher struct {
 	liveReload *livereload.LiveReload
 	watcher    *fsnotify.Watcher
+	cfg        *config.Config
 	distDir    string
+	opts       sites.RebuildOpts
 	stopOnce   *sync.Once
 	stopC      chan struct{}
 }
 
-func NewFSWatcher(distDir string, lr *livereload.LiveReload) *FSWatcher {
+func NewFSWatcher(cfg *config.Config, distDir string, opts sites.RebuildOpts, lr *livereload.LiveReload) *FSWatcher {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		panic(err)
 	}
 	return &FSWatcher{
+		cfg:        cfg,
 		distDir:    distDir,
+		opts:       opts,
 		liveReload: lr,
 		watcher:    watcher,
 		stopOnce:   &sync.Once{},
@@ -85,14 +92,23 @@ func (f *FSWatcher) Start() (mErr error) {
 
 			case filepath.Ext(rel) == ".md" || filepath.Ext(rel) == ".html":
 				if err := f.compileReloadMd(); err != nil {
-					return fmt.Errorf("failed to compiled changed markdown: %w", err)
+					if !isPostErr(err) {
+						return fmt.Errorf("failed to compiled changed markdown: %w", err)
+					}
+					// Keep serving so the author can fix the post.
+					slog.Error("compile changed markdown", "path", rel, "error", err)
+					break
 				}
 				f.liveReload.ReloadFile(event.Name)
 
 			case strings.HasPrefix(rel, "pkg/markdown/html"):
 				err := f.compileReloadMd()
 				if err != nil {
-					return fmt.Errorf("compile markdown for changed file %s: %w", rel, err)
+					if !isPostErr(err) {
+						return fmt.Errorf("compile markdown for changed file %s: %w", rel, err)
+					}
+					slog.Error("compile markdown for changed file", "path", rel, "error", err)
+					break
 				}
 				f.liveReload.ReloadFile("")
 
@@ -143,12 +159,20 @@ func (f *FSWatcher) watchDirs(dirs ...string) error {
 }
 
 func (f *FSWatcher) compileReloadMd() error {
-	if err := sites.Rebuild(f.distDir); err != nil {
+	if err := sites.Rebuild(f.cfg, f.distDir, f.opts); err != nil {
 		return fmt.Errorf("rebuild for changed md: %w", err)
 	}
 	return nil
 }
 
+// isPostErr returns true if err is from problems in posts, like invalid front
+// matter, which were already reported. The server keeps running for these
+// errors.
+func isPostErr(err error) bool {
+	var re *diag.ReportError
+	return errors.As(err, &re)
+}
+
 func (f *FSWatcher) reloadMainCSS() {
 	stylePaths, err := css.CopyAllCSS(f.distDir)
 	if err != nil {
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jschaf/jsc/pkg/markdown"
+	"github.com/jschaf/jsc/pkg/markdown/catalog"
+	"github.com/jschaf/jsc/pkg/markdown/mdext"
+	"github.com/jschaf/jsc/pkg/process"
+)
+
+var (
+	formatFlag = flag.String("format", "table", "output format: table or json")
+	periodFlag = flag.String("period", "year", "group aggregates by year or month")
+	draftsFlag = flag.Bool("drafts", false, "include draft and scheduled posts")
+)
+
+// postStats are the stats of a single post.
+type postStats struct {
+	Slug           string    `json:"slug"`
+	Title          string    `json:"title"`
+	Date           time.Time `json:"date"`
+	Words          int       `json:"words"`
+	ReadingMinutes int       `json:"readingMinutes"`
+	CodeBlocks     int       `json:"codeBlocks"`
+	Figures        int       `json:"figures"`
+	Citations      int       `json:"citations"`
+	HasMath        bool      `json:"hasMath"`
+}
+
+// aggregate are the summed stats of the posts in a period, or of every post.
+type aggregate struct {
+	// Period is the year, like "2020", or the month, like "2020-01". Empty for
+	// the site-wide total.
+	Period         string `json:"period,omitempty"`
+	Posts          int    `json:"posts"`
+	Words          int    `json:"words"`
+	ReadingMinutes int    `json:"readingMinutes"`
+	CodeBlocks     int    `json:"codeBlocks"`
+	Figures        int    `json:"figures"`
+	Citations      int    `json:"citations"`
+	// PostsWithMath is the number of posts with TeX math.
+	PostsWithMath int `json:"postsWithMath"`
+}
+
+func (a *aggregate) add(p postStats) {
+	a.Posts++
+	a.Words += p.Words
+	a.ReadingMinutes += p.ReadingMinutes
+	a.CodeBlocks += p.CodeBlocks
+	a.Figures += p.Figures
+	a.Citations += p.Citations
+	if p.HasMath {
+		a.PostsWithMath++
+	}
+}
+
+type report struct {
+	Posts   []postStats `json:"posts"`
+	Periods []aggregate `json:"periods"`
+	Total   aggregate   `json:"total"`
+}
+
+func newPostStats(ast *markdown.AST) postStats {
+	s := ast.Stats
+	return postStats{
+		Slug:           ast.Meta.Slug,
+		Title:          ast.Meta.Title,
+		Date:           ast.Meta.Date,
+		Words:          s.Words,
+		ReadingMinutes: s.ReadingMinutes(),
+		CodeBlocks:     s.CodeBlocks,
+		Figures:        s.Figures,
+		Citations:      s.Citations,
+		HasMath:        s.HasMath,
+	}
+}
+
+// buildReport returns the stats of each post, oldest first, and the
+// aggregates of each period.
+func buildReport(asts []*markdown.AST, periodLayout string) report {
+	r := report{Posts: make([]postStats, 0, len(asts))}
+	for _, ast := range asts {
+		r.Posts = append(r.Posts, newPostStats(ast))
+	}
+	sort.Slice(r.Posts, func(i, j int) bool { return r.Posts[i].Date.Before(r.Posts[j].Date) })
+
+	for _, p := range r.Posts {
+		period := p.Date.Format(periodLayout)
+		if len(r.Periods) == 0 || r.Periods[len(r.Periods)-1].Period != period {
+			r.Periods = append(r.Periods, aggregate{Period: period})
+		}
+		r.Periods[len(r.Periods)-1].add(p)
+		r.Total.add(p)
+	}
+	return r
+}
+
+func writeTable(w io.Writer, r report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "Date\tWords\tMinutes\tCode\tFigures\tCitations\tMath\tSlug")
+	for _, p := range r.Posts {
+		math := ""
+		if p.HasMath {
+			math = "yes"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%s\t%s\n",
+			p.Date.Format("2006-01-02"), p.Words, p.ReadingMinutes, p.CodeBlocks, p.Figures, p.Citations, math, p.Slug)
+	}
+	// A line without tabs ends the column block so the aggregates table
+	// aligns separately.
+	_, _ = fmt.Fprintln(tw)
+	_, _ = fmt.Fprintln(tw, "Period\tPosts\tWords\tMinutes\tCode\tFigures\tCitations\tMath")
+	for _, a := range append(r.Periods, r.Total) {
+		period := a.Period
+		if period == "" {
+			period = "total"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\n",
+			period, a.Posts, a.Words, a.ReadingMinutes, a.CodeBlocks, a.Figures, a.Citations, a.PostsWithMath)
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("flush stats table: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(w io.Writer, r report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return fmt.Errorf("encode stats json: %w", err)
+	}
+	return nil
+}
+
+func main() {
+	process.RunMain(runMain)
+}
+
+func runMain(_ context.Context) error {
+	if err := flag.CommandLine.Parse(os.Args[1:]); err != nil {
+		return fmt.Errorf("parse flags: %w", err)
+	}
+	var periodLayout string
+	switch *periodFlag {
+	case "year":
+		periodLayout = "2006"
+	case "month":
+		periodLayout = "2006-01"
+	default:
+		return fmt.Errorf("invalid period %q; want year or month", *periodFlag)
+	}
+	write := writeTable
+	switch *formatFlag {
+	case "table":
+	case "json":
+		write = writeJSON
+	default:
+		return fmt.Errorf("invalid format %q; want table or json", *formatFlag)
+	}
+
+	cat, err := catalog.Load("", time.Now())
+	if err != nil {
+		return fmt.Errorf("load catalog: %w", err)
+	}
+	var asts []*markdown.AST
+	for _, ast := range cat.Posts {
+		if *draftsFlag || ast.Meta.Visibility == mdext.VisibilityPublished {
+			asts = append(asts, ast)
+		}
+	}
+	return write(os.Stdout, buildReport(asts, periodLayout))
+}
@@ -9,9 +9,11 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/jschaf/jsc/pkg/config"
 	"github.com/jschaf/jsc/pkg/errs"
 	"github.com/jschaf/jsc/pkg/log"
 	"github.com/jschaf/jsc/pkg/net/srv"
@@ -20,14 +22,13 @@ import (
 	"golang.org/x/net/http2/h2c"
 )
 
-const port = "3355"
-
 type Server struct {
 	// Lifecycle context.
 	// Calling serverCancel causes all background goroutines to stop. To stop the
 	// HTTP server, call Shutdown.
 	serverCtx    context.Context
 	serverCancel context.CancelFunc
+	port         int
 	// Servers
 	httpSrv *http.Server
 	// Locks
@@ -35,6 +36,7 @@ type Server struct {
 }
 
 type ServerOpts struct {
+	Config *config.Config
 	Cancel context.CancelFunc
 }
 
@@ -48,6 +50,7 @@ func InitServer(ctx context.Context, opts ServerOpts) (*Server, error) {
 	return &Server{
 		serverCtx:    ctx,
 		serverCancel: opts.Cancel,
+		port:         opts.Config.Track.Port,
 		// Servers
 		httpSrv: httpSrv,
 		// Locks
@@ -60,13 +63,14 @@ func (s *Server) ListenAndServe() (mErr error) {
 		return fmt.Errorf("server context error: %w", err)
 	}
 
-	ln, err := net.Listen("tcp", "0.0.0.0:"+port)
+	addr := net.JoinHostPort("0.0.0.0", strconv.Itoa(s.port))
+	ln, err := net.Listen("tcp", addr)
 	if err != nil {
 		return fmt.Errorf("listen to http port: %w", err)
 	}
 	defer errs.Capture(&mErr, srv.NewListenerCloser(ln), "close http listener")
 
-	url := "http://localhost:" + port
+	url := "http://localhost:" + strconv.Itoa(s.port)
 	slog.Info("ready: track server listening", slog.String("url", url))
 
 	// Serve
@@ -99,9 +103,14 @@ func runMain(ctx context.Context) (mErr error) {
 
 	fset := flag.CommandLine
 	logLevel := log.DefineFlags(fset)
+	cfgFlags := config.DefineFlags(fset, config.EnvProd)
 	if err := fset.Parse(os.Args[1:]); err != nil {
 		return fmt.Errorf("parse flags: %w", err)
 	}
+	cfg, err := cfgFlags.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
 
 	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
 		ReplaceAttr: log.GCPReplaceAttr,
@@ -112,6 +121,7 @@ func runMain(ctx context.Context) (mErr error) {
 	slog.Info("start track server", "process.args", os.Args[1:])
 
 	devSrv, err := InitServer(ctx, ServerOpts{
+		Config: cfg,
 		Cancel: cancel,
 	})
 	if err != nil {
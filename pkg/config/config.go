@@ -0,0 +1,262 @@
+// Package config loads the site configuration from site.toml, like the site
+// title and the ports of the servers, so that the compilers, templates,
+// publisher and servers share one source of truth.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/jschaf/jsc/pkg/git"
+)
+
+// FileName is the name of the config file in the repo root.
+const FileName = "site.toml"
+
+// Environments with overrides in the config file.
+const (
+	EnvDev  = "dev"
+	EnvProd = "prod"
+)
+
+// Config is the site configuration.
+type Config struct {
+	// Path is the full path of the config file.
+	Path string `toml:"-"`
+	// Env is the environment whose overrides were applied, like "dev".
+	Env string `toml:"-"`
+
+	Title  string `toml:"title"`
+	Author string `toml:"author"`
+	// URL is the absolute root URL of the site without a trailing slash, like
+	// "https://joe.schafer.dev".
+	URL string `toml:"url"`
+	// PageSize is the number of posts on each listing page, like the home
+	// page.
+	PageSize int `toml:"page_size"`
+	// Nav are the links in the site header.
+	Nav      []NavLink      `toml:"nav"`
+	Server   ServerConfig   `toml:"server"`
+	Track    TrackConfig    `toml:"track"`
+	Firebase FirebaseConfig `toml:"firebase"`
+	History  HistoryConfig  `toml:"history"`
+	Related  RelatedConfig  `toml:"related"`
+}
+
+// NavLink is a link in the site header.
+type NavLink struct {
+	Name  string `toml:"name"`
+	URL   string `toml:"url"`
+	Title string `toml:"title"`
+}
+
+// ServerConfig configures the dev server in cmd/server.
+type ServerConfig struct {
+	Port int `toml:"port"`
+}
+
+// TrackConfig configures the analytics server in cmd/track.
+type TrackConfig struct {
+	Port int `toml:"port"`
+}
+
+// HistoryConfig configures the revision history of each post, generated
+// from the commits that changed the post.
+type HistoryConfig struct {
+	// Show adds the revision history to the end of each post.
+	Show bool `toml:"show"`
+	// CommitURL is the URL prefix of a commit. The revision history links to
+	// the commit hash appended to the prefix. Empty to not link commits.
+	CommitURL string `toml:"commit_url"`
+}
+
+// RelatedConfig configures the related posts at the end of each post.
+type RelatedConfig struct {
+	// Count is the most related posts to show. Zero hides related posts.
+	Count int `toml:"count"`
+}
+
+// FirebaseConfig configures Firebase hosting for cmd/publish.
+type FirebaseConfig struct {
+	// Site is the Firebase hosting site name.
+	Site string `toml:"site"`
+	// TrailingSlash is the trailing slash behavior: "ADD", "REMOVE", or empty
+	// to serve both.
+	TrailingSlash string            `toml:"trailing_slash"`
+	Rewrites      []FirebaseRewrite `toml:"rewrites"`
+}
+
+// FirebaseRewrite serves requests matching a glob from a Cloud Run service.
+type FirebaseRewrite struct {
+	Glob      string `toml:"glob"`
+	Region    string `toml:"region"`
+	ServiceID string `toml:"service_id"`
+}
+
+// file is the layout of the config file: the config plus overrides for each
+// environment.
+type file struct {
+	Config
+	Env map[string]toml.Primitive `toml:"env"`
+}
+
+// Load reads the config file at path and applies the overrides for env.
+// Returns an error if the config is invalid.
+func Load(path string, env string) (*Config, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	cfg, err := Parse(string(src), env)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	cfg.Path = path
+	return cfg, nil
+}
+
+// Parse parses the config file contents and applies the overrides for env.
+// Returns an error if the config is invalid.
+func Parse(src string, env string) (*Config, error) {
+	f := &file{}
+	md, err := toml.Decode(src, f)
+	if err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+	if _, ok := f.Env[env]; !ok {
+		return nil, fmt.Errorf("unknown env %q; want one of %s", env, strings.Join(sortedKeys(f.Env), ", "))
+	}
+	cfg := f.Config
+	// Decode every env, not just the chosen one, so that a typo in another
+	// env is an error now instead of when deploying.
+	for name, prim := range f.Env {
+		target := &Config{}
+		if name == env {
+			target = &cfg
+		}
+		if err := md.PrimitiveDecode(prim, target); err != nil {
+			return nil, fmt.Errorf("parse env %s: %w", name, err)
+		}
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, k := range undecoded {
+			keys[i] = strconv.Quote(k.String())
+		}
+		return nil, fmt.Errorf("unknown config keys: %s", strings.Join(keys, ", "))
+	}
+	cfg.Env = env
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Validate returns an error describing every invalid value.
+func (c *Config) Validate() error {
+	var errs []error
+	addErr := func(key string, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+	if c.Title == "" {
+		addErr("title", "must not be empty")
+	}
+	if c.Author == "" {
+		addErr("author", "must not be empty")
+	}
+	if u, err := url.Parse(c.URL); err != nil || !u.IsAbs() || u.Host == "" {
+		addErr("url", "want absolute URL; got %q", c.URL)
+	} else if strings.HasSuffix(c.URL, "/") {
+		addErr("url", "must not end with a slash; got %q", c.URL)
+	}
+	if c.PageSize <= 0 {
+		addErr("page_size", "must be positive; got %d", c.PageSize)
+	}
+	for i, n := range c.Nav {
+		if n.Name == "" || n.URL == "" {
+			addErr("nav["+strconv.Itoa(i)+"]", "want name and url")
+		}
+	}
+	if c.Server.Port <= 0 || c.Server.Port > 65535 {
+		addErr("server.port", "want port in 1-65535; got %d", c.Server.Port)
+	}
+	if c.Track.Port <= 0 || c.Track.Port > 65535 {
+		addErr("track.port", "want port in 1-65535; got %d", c.Track.Port)
+	}
+	if c.Firebase.Site == "" {
+		addErr("firebase.site", "must not be empty")
+	}
+	if !slices.Contains([]string{"", "ADD", "REMOVE"}, c.Firebase.TrailingSlash) {
+		addErr("firebase.trailing_slash", `want "ADD", "REMOVE" or empty; got %q`, c.Firebase.TrailingSlash)
+	}
+	for i, r := range c.Firebase.Rewrites {
+		if r.Glob == "" || r.Region == "" || r.ServiceID == "" {
+			addErr("firebase.rewrites["+strconv.Itoa(i)+"]", "want glob, region and service_id")
+		}
+	}
+	if c.History.CommitURL != "" {
+		if u, err := url.Parse(c.History.CommitURL); err != nil || !u.IsAbs() {
+			addErr("history.commit_url", "want absolute URL; got %q", c.History.CommitURL)
+		}
+	}
+	if c.Related.Count < 0 {
+		addErr("related.count", "must not be negative; got %d", c.Related.Count)
+	}
+	return errors.Join(errs...)
+}
+
+// Fingerprint returns a hash of the config. Outputs built with a different
+// fingerprint are stale.
+func (c *Config) Fingerprint() string {
+	bs, err := json.Marshal(c)
+	if err != nil {
+		panic(fmt.Sprintf("marshal config: %v", err))
+	}
+	h := fnv.New64a()
+	_, _ = h.Write(bs)
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// Flags are the command line flags that choose the config.
+type Flags struct {
+	Path string
+	Env  string
+}
+
+// DefineFlags defines the config flags on fset. defaultEnv is the env used if
+// the --env flag isn't set.
+func DefineFlags(fset *flag.FlagSet, defaultEnv string) *Flags {
+	f := &Flags{}
+	fset.StringVar(&f.Path, "config-path", "", "path to the site config file; defaults to "+FileName+" in the repo root")
+	fset.StringVar(&f.Env, "env", defaultEnv, "config env to apply overrides for, like "+EnvDev+" or "+EnvProd)
+	return f
+}
+
+// Load loads the config chosen by the flags.
+func (f *Flags) Load() (*Config, error) {
+	path := f.Path
+	if path == "" {
+		path = filepath.Join(git.RootDir(), FileName)
+	}
+	return Load(path, f.Env)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
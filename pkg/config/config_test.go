@@ -0,0 +1,119 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jschaf/jsc/pkg/git"
+	"github.com/jschaf/jsc/pkg/testing/difftest"
+	"github.com/jschaf/jsc/pkg/testing/require"
+	"github.com/jschaf/jsc/pkg/texts"
+)
+
+const validConfig = `
+	title = "Blog"
+	author = "Alice"
+	url = "https://example.com"
+	page_size = 10
+
+	[[nav]]
+	name = "GitHub"
+	url = "https://github.com/alice"
+
+	[server]
+	port = 2222
+
+	[track]
+	port = 3355
+
+	[firebase]
+	site = "alice"
+
+	[env.dev]
+	url = "http://localhost:2222"
+
+	[env.prod]
+`
+
+func TestParse_EnvOverrides(t *testing.T) {
+	dev, err := Parse(texts.Dedent(validConfig), EnvDev)
+	require.NoError(t, err)
+	difftest.AssertSame(t, "http://localhost:2222", dev.URL)
+	difftest.AssertSame(t, "Blog", dev.Title)
+
+	prod, err := Parse(texts.Dedent(validConfig), EnvProd)
+	require.NoError(t, err)
+	difftest.AssertSame(t, "https://example.com", prod.URL)
+
+	if dev.Fingerprint() == prod.Fingerprint() {
+		t.Errorf("dev and prod have the same fingerprint %s", dev.Fingerprint())
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		env     string
+		wantErr string
+	}{
+		{
+			"unknown env",
+			validConfig,
+			"staging",
+			`unknown env "staging"; want one of dev, prod`,
+		},
+		{
+			"unknown key",
+			validConfig + "\n[env.staging]\ncolor = \"red\"\n",
+			EnvDev,
+			`unknown config keys: "env.staging.color"`,
+		},
+		{
+			"invalid url",
+			strings.Replace(validConfig, `url = "https://example.com"`, `url = "https://example.com/"`, 1),
+			EnvProd,
+			`url: must not end with a slash; got "https://example.com/"`,
+		},
+		{
+			"invalid override",
+			// Appends to the last table, env.prod.
+			validConfig + "\npage_size = 0\n",
+			EnvProd,
+			"page_size: must be positive; got 0",
+		},
+		{
+			"relative commit url",
+			validConfig + "\n[env.prod.history]\ncommit_url = \"/commit/\"\n",
+			EnvProd,
+			`history.commit_url: want absolute URL; got "/commit/"`,
+		},
+		{
+			"negative related count",
+			validConfig + "\n[related]\ncount = -1\n",
+			EnvProd,
+			`related.count: must not be negative; got -1`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(texts.Dedent(tt.src), tt.env)
+			if err == nil {
+				t.Fatalf("Parse() succeeded; want error %q", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() error mismatch:\nwant: %s\ngot:  %s", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoad_SiteConfig(t *testing.T) {
+	for _, env := range []string{EnvDev, EnvProd} {
+		t.Run(env, func(t *testing.T) {
+			_, err := Load(filepath.Join(git.RootDir(), FileName), env)
+			require.NoError(t, err)
+		})
+	}
+}
@@ -20,6 +20,14 @@ const (
 	Style  = "style"
 	TIL    = "til"
 	Dist   = "dist"
+	// LinkCache holds the fetched previews of links in posts.
+	LinkCache = "linkcache"
+	// LinkArchive holds the check history and snapshots of links in posts.
+	LinkArchive = "linkarchive"
+	// Manifest is the dir in the dist dir that holds the build manifests for
+	// incremental builds. The manifests aren't part of the site, so
+	// deployments skip the dir.
+	Manifest = ".manifest"
 )
 
 // RemoveAllChildren removes all children in the directory.
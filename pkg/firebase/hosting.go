@@ -15,6 +15,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/jschaf/jsc/pkg/dirs"
 	"github.com/jschaf/jsc/pkg/errs"
 	"github.com/karrick/godirwalk"
 	"golang.org/x/sync/errgroup"
@@ -99,6 +100,11 @@ func (sh *SiteHashes) PopulateFromDir(dir string) error {
 	g, ctx := errgroup.WithContext(ctx)
 
 	walkFunc := func(path string, dirent *godirwalk.Dirent) error {
+		// Skip the build manifests since they're not part of the site. Other
+		// dot-prefixed files, like .well-known, are served as is.
+		if filepath.Clean(path) == filepath.Join(dir, dirs.Manifest) {
+			return godirwalk.SkipThis
+		}
 		if isDir, err := dirent.IsDirOrSymlinkToDir(); err != nil {
 			return err
 		} else if isDir {
@@ -1,6 +1,9 @@
 package firebase
 
 import (
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -40,3 +43,28 @@ func BenchmarkSiteHashes_PopulateFromDir(b *testing.B) {
 		}
 	}
 }
+
+func TestSiteHashes_PopulateFromDir_SkipsManifest(t *testing.T) {
+	dir := t.TempDir()
+	for _, p := range []string{"index.html", ".well-known/security.txt", ".manifest/detail.json"} {
+		path := filepath.Join(dir, p)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(p), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sh := NewSiteHashes()
+	if err := sh.PopulateFromDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for url := range sh.HashesByURL() {
+		got = append(got, url)
+	}
+	slices.Sort(got)
+	if diff := cmp.Diff([]string{"/.well-known/security.txt", "/index.html"}, got); diff != "" {
+		t.Errorf("URLs mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,78 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Commit is a commit that changed a file.
+type Commit struct {
+	Hash string
+	// Time is the author time, when the change was written. Unlike the
+	// committer time, a rebase doesn't change the author time.
+	Time    time.Time
+	Subject string
+}
+
+// Separators of the git log format. Use ASCII control characters since they
+// can't appear in a commit subject.
+const (
+	recordSep = '\x1e'
+	fieldSep  = '\x1f'
+)
+
+// FileHistory returns the commits that changed each file matching the
+// pathspecs, newest first. The keys are full paths of files in the repo at
+// root. Merge commits are skipped. History stops at a rename since the
+// history of each file isn't followed.
+func FileHistory(root string, pathspecs ...string) (map[string][]Commit, error) {
+	args := []string{
+		"-C", root,
+		"-c", "core.quotePath=false",
+		"log", "--no-merges", "--name-only",
+		"--format=" + string(recordSep) + "%H" + string(fieldSep) + "%aI" + string(fieldSep) + "%s",
+		"--",
+	}
+	args = append(args, pathspecs...)
+	cmd := exec.Command("git", args...)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return parseFileHistory(root, string(out))
+}
+
+// parseFileHistory parses the output of git log with --name-only and the
+// format used by FileHistory.
+func parseFileHistory(root string, out string) (map[string][]Commit, error) {
+	history := make(map[string][]Commit)
+	for _, record := range strings.Split(out, string(recordSep)) {
+		if strings.TrimSpace(record) == "" {
+			continue
+		}
+		header, names, _ := strings.Cut(record, "\n")
+		fields := strings.Split(header, string(fieldSep))
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("parse git log: want 3 fields in commit header; got %q", header)
+		}
+		t, err := time.Parse(time.RFC3339, fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("parse git log commit time: %w", err)
+		}
+		c := Commit{Hash: fields[0], Time: t, Subject: fields[2]}
+		for _, name := range strings.Split(names, "\n") {
+			if name == "" {
+				continue
+			}
+			path := filepath.Join(root, filepath.FromSlash(name))
+			history[path] = append(history[path], c)
+		}
+	}
+	return history, nil
+}
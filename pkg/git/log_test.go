@@ -0,0 +1,41 @@
+package git
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jschaf/jsc/pkg/testing/difftest"
+	"github.com/jschaf/jsc/pkg/testing/require"
+)
+
+func TestParseFileHistory(t *testing.T) {
+	out := "\x1eaaa\x1f2025-01-02T03:04:05-08:00\x1fEdit both posts\n" +
+		"\n" +
+		"posts/a.md\n" +
+		"posts/b b.md\n" +
+		"\x1ebbb\x1f2024-12-01T00:00:00Z\x1fAdd post a\n" +
+		"\n" +
+		"posts/a.md\n"
+
+	got, err := parseFileHistory("/root", out)
+	require.NoError(t, err)
+
+	edit := Commit{
+		Hash:    "aaa",
+		Time:    time.Date(2025, 1, 2, 3, 4, 5, 0, time.FixedZone("", -8*60*60)),
+		Subject: "Edit both posts",
+	}
+	add := Commit{Hash: "bbb", Time: time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), Subject: "Add post a"}
+	want := map[string][]Commit{
+		"/root/posts/a.md":   {edit, add},
+		"/root/posts/b b.md": {edit},
+	}
+	difftest.AssertSame(t, want, got)
+}
+
+func TestParseFileHistory_BadHeader(t *testing.T) {
+	_, err := parseFileHistory("/root", "\x1eaaa\n\nposts/a.md\n")
+	if err == nil {
+		t.Fatal("want error for commit header without time and subject")
+	}
+}
@@ -2,27 +2,49 @@ package assets
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 
+	"github.com/jschaf/jsc/pkg/errs"
 	"github.com/jschaf/jsc/pkg/paths"
 )
 
 type Blob struct {
-	// Absolute path of the source file. If nil, GenPath must be non-nil.
+	// Absolute path of the source file. If empty, GenFunc must be non-nil.
 	Src string
 	// Path relative to the pub dir of the destination file path.
 	Dest string
-	// If non-nil, how to generate the output for Dest.
-	GenFunc func() error
+	// If non-nil, generates the contents of Dest instead of copying Src.
+	GenFunc func(w io.Writer) error
 }
 
 // CopyAll copies all assets into the distDir, overwriting existing files.
+// Generates the assets with a GenFunc.
 func CopyAll(distDir string, assets []Blob) error {
 	for _, blob := range assets {
 		dest := filepath.Join(distDir, blob.Dest)
+		if blob.GenFunc != nil {
+			if err := generate(dest, blob.GenFunc); err != nil {
+				return fmt.Errorf("generate asset %s: %w", blob.Dest, err)
+			}
+			continue
+		}
 		if _, err := paths.CopyLazy(dest, blob.Src); err != nil {
 			return fmt.Errorf("failed to copy asset to dest: %w", err)
 		}
 	}
 	return nil
 }
+
+func generate(dest string, gen func(w io.Writer) error) (mErr error) {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return fmt.Errorf("make dir: %w", err)
+	}
+	f, err := os.Create(dest)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	defer errs.Capture(&mErr, f.Close, "close generated asset")
+	return gen(f)
+}
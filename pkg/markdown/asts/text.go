@@ -2,6 +2,7 @@ package asts
 
 import (
 	"bytes"
+	"strings"
 
 	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/parser"
@@ -80,6 +81,28 @@ func appendSlugText(dest []byte, offs int, node ast.Node, src []byte) (int, bool
 	return offs, isTruncated
 }
 
+// PlainText returns the text content of node without any markup. Replaces
+// line breaks with a space.
+func PlainText(node ast.Node, src []byte) string {
+	sb := &strings.Builder{}
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch x := n.(type) {
+		case *ast.String:
+			sb.Write(x.Value)
+		case *ast.Text:
+			sb.Write(x.Segment.Value(src))
+			if x.SoftLineBreak() || x.HardLineBreak() {
+				sb.WriteByte(' ')
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(sb.String())
+}
+
 func lower(b byte) byte {
 	if 'A' <= b && b <= 'Z' {
 		return b | (1 << 5)
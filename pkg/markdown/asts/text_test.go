@@ -40,3 +40,24 @@ func TestWriteSlugText(t *testing.T) {
 		})
 	}
 }
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"foo *bar* `baz`", "foo bar baz"},
+		{"foo\nbar", "foo bar"},
+		{"[link](/foo) text", "link text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			md, ctx := mdtest.NewTester(t)
+			doc := mdtest.MustParseMarkdown(t, md, ctx, tt.src)
+			got := PlainText(doc.FirstChild(), []byte(tt.src))
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("PlainText() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
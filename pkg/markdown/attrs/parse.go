@@ -3,99 +3,235 @@ package attrs
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+
+	"github.com/yuin/goldmark/ast"
 )
 
-// Values are the extended attributes of a Markdown node.
+// Values are the extended attributes of a Markdown node, parsed from a
+// pandoc-style attribute list:
+//
+//	## Heading {#custom-id .wide data-note="intro"}
 //
 //	```go {name="foo.go" description="bar"}
 //	func foo() {}
 //	```
-type Values struct {
-	Name        string
-	Description string
+//
+// The ID is stored under "id" and the classes, space separated, under "class".
+type Values map[string]string
+
+// ID returns the ID from "#id", or empty if none.
+func (v Values) ID() string {
+	return v["id"]
+}
+
+// Classes returns the classes from each ".class", in order.
+func (v Values) Classes() []string {
+	return strings.Fields(v["class"])
+}
+
+// Name returns the "name" attribute, like the file name of a code block.
+func (v Values) Name() string {
+	return v["name"]
+}
+
+// Description returns the "description" attribute.
+func (v Values) Description() string {
+	return v["description"]
+}
+
+// Get returns the attribute with the key. Returns false if the attribute
+// isn't set.
+func (v Values) Get(key string) (string, bool) {
+	s, ok := v[key]
+	return s, ok
+}
+
+// Bool returns the attribute with the key as a bool, like "true" or "false".
+// Returns false if the attribute isn't set.
+func (v Values) Bool(key string) (bool, error) {
+	s, ok := v[key]
+	if !ok {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("attribute %q: want a bool; got %q", key, s)
+	}
+	return b, nil
+}
+
+// Int returns the attribute with the key as an int. Returns 0 if the
+// attribute isn't set.
+func (v Values) Int(key string) (int, error) {
+	s, ok := v[key]
+	if !ok {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("attribute %q: want an int; got %q", key, s)
+	}
+	return n, nil
+}
+
+// Only returns an error if any attribute isn't one of the keys, for nodes
+// that only support some attributes.
+func (v Values) Only(keys ...string) error {
+	var unknown []string
+	for k := range v {
+		if !slices.Contains(keys, k) {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	slices.Sort(unknown)
+	return fmt.Errorf("unsupported field name %q; want one of %s", unknown[0], strings.Join(keys, ", "))
+}
+
+// Apply sets the ID, classes and data attributes, like "data-note", on the
+// node so that the renderer of the node renders them. Preserves existing
+// classes. Other attributes are only for the extension that owns the node.
+func (v Values) Apply(n ast.Node) {
+	if id := v.ID(); id != "" {
+		n.SetAttribute([]byte("id"), []byte(id))
+	}
+	if cls := v.Classes(); len(cls) > 0 {
+		AddClass(n, cls...)
+	}
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		if strings.HasPrefix(k, "data-") {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		n.SetAttribute([]byte(k), []byte(v[k]))
+	}
 }
 
-// ParseValues parses the extended attribute string and returns a Values struct.
+// ParseValues parses the extended attribute string and returns the values.
+// The string must be a braced list of "#id", ".class" and "key='val'"
+// attributes, like:
+//
+//	{#id .class1 .class2 key="val" key2='val'}
 func ParseValues(expr string) (Values, error) {
 	var err error
 	s := strings.TrimSpace(expr)
 	offs := 0
 
 	if len(s) == 0 {
-		return Values{}, errors.New("empty extended attributes")
+		return nil, errors.New("empty extended attributes")
 	}
 	if s[0] != '{' {
-		return Values{}, fmt.Errorf("expected '{' for extended attributes in code block:\n%s", s)
+		return nil, fmt.Errorf("expected '{' for extended attributes in: %s", s)
 	}
 	offs++
 
 	vals := Values{}
-	for offs < len(s) {
+	for {
 		offs = skipWhitespace(s, offs)
 		if offs >= len(s) {
-			return Values{}, fmt.Errorf("extend attribute not closed")
+			return nil, fmt.Errorf("missing closing delimiter '}' in: %s", s)
 		}
-
 		if s[offs] == '}' {
 			break
 		}
 
-		// Parse the field name.
-		var fieldName string
-		fieldName, offs, err = parseFieldName(s, offs)
-		if err != nil {
-			return Values{}, fmt.Errorf("parse field name: %w", err)
-		}
+		switch s[offs] {
+		case '#':
+			var id string
+			id, offs, err = parseFieldName(s, offs+1)
+			if err != nil {
+				return nil, fmt.Errorf("parse id: %w", err)
+			}
+			if _, ok := vals["id"]; ok {
+				return nil, fmt.Errorf("duplicate id %q in: %s", id, s)
+			}
+			vals["id"] = id
 
-		offs = skipWhitespace(s, offs)
+		case '.':
+			var cls string
+			cls, offs, err = parseFieldName(s, offs+1)
+			if err != nil {
+				return nil, fmt.Errorf("parse class: %w", err)
+			}
+			if old := vals["class"]; old != "" {
+				cls = old + " " + cls
+			}
+			vals["class"] = cls
 
-		// Parse the equal sign.
-		if offs >= len(s) || s[offs] != '=' {
-			return Values{}, fmt.Errorf("expected '=' after field name %q", fieldName)
-		}
-		offs++
+		default:
+			// Parse the field name.
+			var fieldName string
+			fieldName, offs, err = parseFieldName(s, offs)
+			if err != nil {
+				return nil, fmt.Errorf("parse field name: %w", err)
+			}
+			if fieldName == "id" || fieldName == "class" {
+				return nil, fmt.Errorf("use #id or .class instead of field %q", fieldName)
+			}
 
-		// Skip whitespace.
-		offs = skipWhitespace(s, offs)
+			offs = skipWhitespace(s, offs)
 
-		// Parse the quoted value.
-		var val string
-		val, offs, err = parseQuotedValue(s, offs)
-		if err != nil {
-			return Values{}, fmt.Errorf("parse field %q value: %w", fieldName, err)
-		}
+			// Parse the equal sign.
+			if offs >= len(s) || s[offs] != '=' {
+				return nil, fmt.Errorf("expected '=' after field name %q", fieldName)
+			}
+			offs++
 
-		if offs >= len(s) || (!isWhitespace(s[offs]) && s[offs] != '}') {
-			return Values{}, fmt.Errorf("field %q not separated with whitespace", fieldName)
-		}
+			// Skip whitespace.
+			offs = skipWhitespace(s, offs)
 
-		// Assign the value.
-		switch fieldName {
-		case "name":
-			vals.Name = val
-		case "description":
-			vals.Description = val
-		default:
-			return Values{}, fmt.Errorf("unsupported field name %q", fieldName)
+			// Parse the quoted value.
+			var val string
+			val, offs, err = parseQuotedValue(s, offs)
+			if err != nil {
+				return nil, fmt.Errorf("parse field %q value: %w", fieldName, err)
+			}
+			if _, ok := vals[fieldName]; ok {
+				return nil, fmt.Errorf("duplicate field %q in: %s", fieldName, s)
+			}
+			vals[fieldName] = val
 		}
-	}
 
-	offs = skipWhitespace(s, offs)
-	if offs >= len(s) {
-		return Values{}, fmt.Errorf("missing closing delimiter '}' in: %s", s)
-	}
-	if s[offs] != '}' {
-		return Values{}, fmt.Errorf("expected '}' for extended attributes in: %s", s)
+		if offs >= len(s) {
+			return nil, fmt.Errorf("missing closing delimiter '}' in: %s", s)
+		}
+		if !isWhitespace(s[offs]) && s[offs] != '}' {
+			return nil, fmt.Errorf("attribute not separated with whitespace in: %s", s)
+		}
 	}
 	offs++
 
 	if offs != len(s) {
-		return Values{}, fmt.Errorf("expr not empty after parsing closing brace in: %s", s)
+		return nil, fmt.Errorf("expr not empty after parsing closing brace in: %s", s)
 	}
 	return vals, nil
 }
 
+// CutTrailing splits a string ending with an extended attribute list, like
+// "Heading {#id}", into the text before the list and the parsed list. Returns
+// false if the string doesn't end with a valid list.
+func CutTrailing(s string) (string, Values, bool) {
+	trimmed := strings.TrimRight(s, " \t\n")
+	if !strings.HasSuffix(trimmed, "}") {
+		return s, nil, false
+	}
+	// Quoted values may contain braces, so try each '{' from the end.
+	for i := strings.LastIndexByte(trimmed, '{'); i >= 0; i = strings.LastIndexByte(trimmed[:i], '{') {
+		if vals, err := ParseValues(trimmed[i:]); err == nil {
+			return strings.TrimRight(trimmed[:i], " \t"), vals, true
+		}
+	}
+	return s, nil, false
+}
+
 // skipWhitespace advances the offset past any whitespace.
 func skipWhitespace(s string, offs int) int {
 	for offs < len(s) && isWhitespace(s[offs]) {
@@ -111,6 +247,9 @@ func parseFieldName(s string, offs int) (string, int, error) {
 		offs++
 	}
 	if start == offs {
+		if offs >= len(s) {
+			return "", offs, fmt.Errorf("expected field name after %s", s[:start])
+		}
 		return "", offs, fmt.Errorf("expected field name after %s; got %q", s[:start], s[offs])
 	}
 	name := s[start:offs]
@@ -1,10 +1,12 @@
 package attrs
 
 import (
-	"github.com/jschaf/jsc/pkg/testing/difftest"
-	"github.com/jschaf/jsc/pkg/testing/require"
 	"strings"
 	"testing"
+
+	"github.com/jschaf/jsc/pkg/testing/difftest"
+	"github.com/jschaf/jsc/pkg/testing/require"
+	"github.com/yuin/goldmark/ast"
 )
 
 func TestParse(t *testing.T) {
@@ -16,32 +18,52 @@ func TestParse(t *testing.T) {
 		{
 			name: "both attributes with double quotes",
 			expr: `{name="foo.go" description="bar"}`,
-			want: Values{Name: "foo.go", Description: "bar"},
+			want: Values{"name": "foo.go", "description": "bar"},
 		},
 		{
 			name: "both attributes with single quotes",
 			expr: `{description='single quotes' name='test.go'}`,
-			want: Values{Name: "test.go", Description: "single quotes"},
+			want: Values{"name": "test.go", "description": "single quotes"},
 		},
 		{
 			name: "only name attribute",
 			expr: `{name="only name"}`,
-			want: Values{Name: "only name", Description: ""},
+			want: Values{"name": "only name"},
 		},
 		{
 			name: "only description attribute",
 			expr: `{description="only description"}`,
-			want: Values{Name: "", Description: "only description"},
+			want: Values{"description": "only description"},
 		},
 		{
 			name: "Mixed quotes for attributes",
 			expr: `{name="foo.go" description='bar'}`,
-			want: Values{Name: "foo.go", Description: "bar"},
+			want: Values{"name": "foo.go", "description": "bar"},
 		},
 		{
 			name: "leading and trailing spaces",
 			expr: `{ description="leading space" name="space.go" }`,
-			want: Values{Name: "space.go", Description: "leading space"},
+			want: Values{"name": "space.go", "description": "leading space"},
+		},
+		{
+			name: "empty",
+			expr: `{}`,
+			want: Values{},
+		},
+		{
+			name: "id",
+			expr: `{#intro}`,
+			want: Values{"id": "intro"},
+		},
+		{
+			name: "classes",
+			expr: `{.wide .dark}`,
+			want: Values{"class": "wide dark"},
+		},
+		{
+			name: "id, class and data attribute",
+			expr: `{#intro .wide data-note="a {b}"}`,
+			want: Values{"id": "intro", "class": "wide", "data-note": "a {b}"},
 		},
 	}
 
@@ -80,11 +102,6 @@ func TestParseErrors(t *testing.T) {
 			expr: `{name="foo.go" description=bar}`,
 			want: "missing start quote",
 		},
-		{
-			name: "invalid field name",
-			expr: `{name="foo.go" desc="bar"}`,
-			want: "unsupported field name",
-		},
 		{
 			name: "missing closing quote",
 			expr: `{name="foo.go" description="bar}`,
@@ -111,9 +128,29 @@ func TestParseErrors(t *testing.T) {
 			want: "missing start quote",
 		},
 		{
-			name: "extra field",
-			expr: `{name="foo.go" description="bar" extra="bad"}`,
-			want: "unsupported field",
+			name: "duplicate field",
+			expr: `{name="foo.go" name="bar.go"}`,
+			want: "duplicate field",
+		},
+		{
+			name: "multiple ids",
+			expr: `{#foo #bar}`,
+			want: "duplicate id",
+		},
+		{
+			name: "empty id",
+			expr: `{# .foo}`,
+			want: "expected field name",
+		},
+		{
+			name: "id as field",
+			expr: `{id="foo"}`,
+			want: "use #id",
+		},
+		{
+			name: "missing opening brace",
+			expr: `name="foo.go"}`,
+			want: "expected '{'",
 		},
 	}
 
@@ -131,3 +168,74 @@ func TestParseErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestValues_Only(t *testing.T) {
+	vals := Values{"name": "foo.go", "description": "bar"}
+	require.NoError(t, vals.Only("name", "description"))
+
+	err := vals.Only("name")
+	if err == nil || !strings.Contains(err.Error(), `unsupported field name "description"`) {
+		t.Errorf("want unsupported field error; got %v", err)
+	}
+}
+
+func TestValues_Typed(t *testing.T) {
+	vals := Values{"open": "true", "width": "640", "bad": "x"}
+
+	open, err := vals.Bool("open")
+	require.NoError(t, err)
+	if !open {
+		t.Errorf("Bool(open) = false; want true")
+	}
+	width, err := vals.Int("width")
+	require.NoError(t, err)
+	if width != 640 {
+		t.Errorf("Int(width) = %d; want 640", width)
+	}
+	if missing, err := vals.Int("missing"); err != nil || missing != 0 {
+		t.Errorf("Int(missing) = %d, %v; want 0, nil", missing, err)
+	}
+	if _, err := vals.Bool("bad"); err == nil {
+		t.Errorf("Bool(bad) = nil error; want error")
+	}
+}
+
+func TestValues_Apply(t *testing.T) {
+	n := ast.NewParagraph()
+	n.SetAttribute([]byte("class"), []byte("existing"))
+	vals := Values{"id": "intro", "class": "wide dark", "data-note": "x", "name": "foo.go"}
+	vals.Apply(n)
+
+	got := map[string]string{}
+	for _, a := range n.Attributes() {
+		got[string(a.Name)] = string(a.Value.([]byte))
+	}
+	want := map[string]string{"id": "intro", "class": "existing wide dark", "data-note": "x"}
+	difftest.AssertSame(t, want, got)
+}
+
+func TestCutTrailing(t *testing.T) {
+	tests := []struct {
+		s          string
+		wantBefore string
+		wantVals   Values
+		wantOK     bool
+	}{
+		{s: "Heading {#id}", wantBefore: "Heading", wantVals: Values{"id": "id"}, wantOK: true},
+		{s: "Heading {#id .a}  ", wantBefore: "Heading", wantVals: Values{"id": "id", "class": "a"}, wantOK: true},
+		{s: `note {data-x="}{"}`, wantBefore: "note", wantVals: Values{"data-x": "}{"}, wantOK: true},
+		{s: "it's {a} {#b}", wantBefore: "it's {a}", wantVals: Values{"id": "b"}, wantOK: true},
+		{s: "Heading", wantBefore: "Heading", wantOK: false},
+		{s: "no open }", wantBefore: "no open }", wantOK: false},
+		{s: "bad {#a #b}", wantBefore: "bad {#a #b}", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			before, vals, ok := CutTrailing(tt.s)
+			if before != tt.wantBefore || ok != tt.wantOK {
+				t.Errorf("CutTrailing(%q) = %q, %v; want %q, %v", tt.s, before, ok, tt.wantBefore, tt.wantOK)
+			}
+			difftest.AssertSame(t, tt.wantVals, vals)
+		})
+	}
+}
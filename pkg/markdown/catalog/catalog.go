@@ -0,0 +1,196 @@
+// Package catalog loads and parses every post once so that the compilers for
+// detail pages and index pages share the same parsed posts.
+package catalog
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jschaf/jsc/pkg/dirs"
+	"github.com/jschaf/jsc/pkg/git"
+	"github.com/jschaf/jsc/pkg/markdown"
+	"github.com/jschaf/jsc/pkg/markdown/diag"
+	"github.com/jschaf/jsc/pkg/markdown/linkio"
+	"github.com/jschaf/jsc/pkg/markdown/mdext"
+	"github.com/jschaf/jsc/pkg/paths"
+)
+
+// Catalog is the set of all parsed posts on the site.
+type Catalog struct {
+	// Posts sorted by date, newest first. Renderers must not modify the ASTs
+	// since they're shared across compilers.
+	Posts []*markdown.AST
+	glob  string
+	// partial is true if any post failed to parse.
+	partial bool
+}
+
+// newParser creates the Markdown parser for all posts. The parser enables
+// every parse-time feature; each compiler chooses what to show by configuring
+// its own renderer. Link previews and dead links come from the checked-in
+// link cache and link archive so parsing never fetches.
+func newParser(opts ...markdown.Option) *markdown.Markdown {
+	root := git.RootDir()
+	return markdown.New(append([]markdown.Option{
+		markdown.WithTOCStyle(mdext.TOCStyleShow),
+		markdown.WithExtender(mdext.NewNopContinueReadingExt()),
+		markdown.WithLinkCache(linkio.NewCache(filepath.Join(root, dirs.LinkCache))),
+		markdown.WithLinkArchive(linkio.NewArchive(filepath.Join(root, dirs.LinkArchive))),
+	}, opts...)...)
+}
+
+// Load reads and parses every Markdown file in the posts and TIL dirs whose
+// path contains glob. An empty glob loads every file. Posts scheduled to go
+// live after now are loaded as drafts, so they're built like any other draft
+// until the build clock passes publish_at.
+//
+// Load parses every post even if some posts fail. If any post fails, returns
+// the catalog of the posts that succeeded and a diag.List error with the
+// diagnostics of every failed post.
+func Load(glob string, now time.Time) (*Catalog, error) {
+	start := time.Now()
+	md := newParser(markdown.WithNow(now))
+	root := git.RootDir()
+	c := &Catalog{glob: glob}
+	diags := &diag.Collector{}
+	for _, dir := range []string{dirs.Posts, dirs.TIL} {
+		posts, err := loadDir(md, filepath.Join(root, dir), glob, diags)
+		if err != nil {
+			return nil, fmt.Errorf("load catalog dir %s: %w", dir, err)
+		}
+		c.Posts = append(c.Posts, posts...)
+	}
+	sort.Slice(c.Posts, func(i, j int) bool {
+		return c.Posts[i].Meta.Date.After(c.Posts[j].Meta.Date)
+	})
+	addHistory(root, c.Posts)
+	c.resolveWikiLinks()
+	slog.Debug("loaded catalog", "count", len(c.Posts), "duration", time.Since(start))
+	errs := diags.List()
+	c.partial = errs.HasErrors()
+	return c, errs.Err()
+}
+
+func loadDir(md *markdown.Markdown, dir string, glob string, diags *diag.Collector) ([]*markdown.AST, error) {
+	return paths.WalkCollect(dir, func(path string, dirent fs.DirEntry) ([]*markdown.AST, error) {
+		if !dirent.Type().IsRegular() || filepath.Ext(path) != ".md" {
+			return nil, nil
+		}
+		if glob != "" && !strings.Contains(path, glob) {
+			return nil, nil
+		}
+		ast, err := parseFile(md, path)
+		if err != nil {
+			diags.AddFile(path, nil, err)
+			return nil, nil
+		}
+		return []*markdown.AST{ast}, nil
+	})
+}
+
+// addHistory sets the commits that changed each post. Logs a warning if git
+// fails, like in a container without git, since the history only refines the
+// last modified time of each post.
+func addHistory(root string, posts []*markdown.AST) {
+	history, err := git.FileHistory(root, dirs.Posts, dirs.TIL)
+	if err != nil {
+		slog.Warn("read post history", "error", err)
+		return
+	}
+	for _, p := range posts {
+		p.History = history[p.Path]
+	}
+}
+
+// parseFile parses a single path into a markdown AST.
+func parseFile(md *markdown.Markdown, path string) (*markdown.AST, error) {
+	slog.Debug("parse post", "path", path)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open post %s: %w", path, err)
+	}
+	defer f.Close()
+	ast, err := md.Parse(path, f)
+	if err != nil {
+		return nil, fmt.Errorf("parse post markdown at path %s: %w", path, err)
+	}
+	return ast, nil
+}
+
+// IsComplete returns true if the catalog contains every post, meaning it
+// wasn't loaded with a glob and every post parsed.
+func (c *Catalog) IsComplete() bool {
+	return c.glob == "" && !c.partial
+}
+
+// WithoutDrafts returns a catalog of only the posts that aren't drafts.
+// Drafts are only built for previews.
+func (c *Catalog) WithoutDrafts() *Catalog {
+	c2 := *c
+	c2.Posts = make([]*markdown.AST, 0, len(c.Posts))
+	for _, p := range c.Posts {
+		if p.Meta.Visibility != mdext.VisibilityDraft {
+			c2.Posts = append(c2.Posts, p)
+		}
+	}
+	return &c2
+}
+
+// Scheduled returns the posts with a publish_at time in (since, now], the
+// posts that went live between two builds, sorted by publish_at.
+func (c *Catalog) Scheduled(since, now time.Time) []*markdown.AST {
+	var posts []*markdown.AST
+	for _, p := range c.Posts {
+		at := p.Meta.PublishAt
+		if p.Meta.Visibility == mdext.VisibilityPublished && at.After(since) && !at.After(now) {
+			posts = append(posts, p)
+		}
+	}
+	sort.Slice(posts, func(i, j int) bool { return posts[i].Meta.PublishAt.Before(posts[j].Meta.PublishAt) })
+	return posts
+}
+
+// DetailDest returns the path of the detail page of the post relative to the
+// dist dir.
+func DetailDest(p *markdown.AST) string {
+	if p.Meta.Visibility == mdext.VisibilityDraft {
+		return filepath.Join(strings.Trim(p.Meta.Path, "/"), "index.html")
+	}
+	return filepath.Join(p.Meta.Slug, "index.html")
+}
+
+// URLPath returns the URL path that serves the detail page of the post,
+// without a trailing slash, like "/new-slug".
+func URLPath(p *markdown.AST) string {
+	return "/" + path.Dir(filepath.ToSlash(DetailDest(p)))
+}
+
+// IsTIL returns true if the post is from the TIL dir.
+func IsTIL(p *markdown.AST) bool {
+	return strings.HasPrefix(p.Path, filepath.Join(git.RootDir(), dirs.TIL)+string(filepath.Separator))
+}
+
+// Warnings returns the diagnostics of every post that don't fail the build.
+func (c *Catalog) Warnings() diag.List {
+	var ws diag.List
+	for _, p := range c.Posts {
+		ws = append(ws, p.Warnings...)
+	}
+	return ws
+}
+
+// Paths returns the full path of the Markdown file for every post.
+func (c *Catalog) Paths() []string {
+	ps := make([]string, len(c.Posts))
+	for i, p := range c.Posts {
+		ps[i] = p.Path
+	}
+	return ps
+}
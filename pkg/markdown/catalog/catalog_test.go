@@ -0,0 +1,135 @@
+package catalog
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jschaf/jsc/pkg/markdown"
+	"github.com/jschaf/jsc/pkg/testing/difftest"
+	"github.com/jschaf/jsc/pkg/texts"
+)
+
+func TestCatalog_WithoutDrafts(t *testing.T) {
+	post := func(slug, visibility string) string {
+		return texts.Dedent(`
+			+++
+			slug = "` + slug + `"
+			date = 2020-01-02
+			visibility = "` + visibility + `"
+			+++
+			# Title
+		`)
+	}
+	cat := &Catalog{}
+	md := newParser()
+	for path, src := range map[string]string{
+		"/a.md": post("alpha", "published"),
+		"/b.md": post("bravo", "draft"),
+	} {
+		ast, err := md.Parse(path, strings.NewReader(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cat.Posts = append(cat.Posts, ast)
+	}
+
+	var gotAll []string
+	for _, p := range cat.Posts {
+		gotAll = append(gotAll, DetailDest(p))
+	}
+	var gotPublished []string
+	for _, p := range cat.WithoutDrafts().Posts {
+		gotPublished = append(gotPublished, DetailDest(p))
+	}
+
+	slices.Sort(gotAll)
+	difftest.AssertSame(t, []string{"alpha/index.html", "drafts/bravo/index.html"}, gotAll)
+	difftest.AssertSame(t, []string{"alpha/index.html"}, gotPublished)
+}
+
+func TestLoad_Scheduled(t *testing.T) {
+	src := texts.Dedent(`
+		+++
+		slug = "alpha"
+		date = 2020-01-02
+		visibility = "published"
+		publish_at = 2020-01-03T09:00:00Z
+		image = "cover.png"
+		+++
+		# Title
+
+		![diagram](diagram.png)
+	`)
+	publishAt := time.Date(2020, time.January, 3, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		now       time.Time
+		wantDest  string
+		wantAsset string
+	}{
+		{"before publish_at", publishAt.Add(-time.Second), "drafts/alpha/index.html", "/drafts/alpha/"},
+		{"at publish_at", publishAt, "alpha/index.html", "/alpha/"},
+		{"after publish_at", publishAt.Add(time.Hour), "alpha/index.html", "/alpha/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := newParser(markdown.WithNow(tt.now))
+			ast, err := md.Parse("/a.md", strings.NewReader(src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			difftest.AssertSame(t, tt.wantDest, DetailDest(ast))
+
+			var gotAssets []string
+			for _, a := range ast.Assets {
+				gotAssets = append(gotAssets, a.Dest)
+			}
+			slices.Sort(gotAssets)
+			difftest.AssertSame(t, []string{tt.wantAsset + "cover.png", tt.wantAsset + "diagram.png"}, gotAssets)
+			difftest.AssertSame(t, tt.wantAsset+"cover.png", ast.Meta.Image)
+
+			b := &bytes.Buffer{}
+			if err := md.Render(b, ast.Source, ast); err != nil {
+				t.Fatal(err)
+			}
+			if want := `src="` + tt.wantAsset + `diagram.png"`; !strings.Contains(b.String(), want) {
+				t.Errorf("rendered post doesn't include %q:\n\n%s", want, b.String())
+			}
+		})
+	}
+}
+
+func TestCatalog_Scheduled(t *testing.T) {
+	post := func(slug, publishAt string) *markdown.AST {
+		src := texts.Dedent(`
+			+++
+			slug = "` + slug + `"
+			date = 2020-01-02
+			visibility = "published"
+			publish_at = ` + publishAt + `
+			+++
+			# Title
+		`)
+		ast, err := newParser().Parse("/"+slug+".md", strings.NewReader(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ast
+	}
+	cat := &Catalog{Posts: []*markdown.AST{
+		post("before", "2020-01-01T00:00:00Z"),
+		post("second", "2020-01-05T00:00:00Z"),
+		post("first", "2020-01-03T00:00:00Z"),
+		post("after", "2020-01-09T00:00:00Z"),
+	}}
+	since := time.Date(2020, time.January, 2, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2020, time.January, 6, 0, 0, 0, 0, time.UTC)
+	var got []string
+	for _, p := range cat.Scheduled(since, now) {
+		got = append(got, p.Meta.Slug)
+	}
+	difftest.AssertSame(t, []string{"first", "second"}, got)
+}
@@ -0,0 +1,111 @@
+package catalog
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jschaf/jsc/pkg/markdown"
+	"github.com/jschaf/jsc/pkg/markdown/diag"
+	"github.com/jschaf/jsc/pkg/markdown/mdext"
+	"github.com/yuin/goldmark/ast"
+)
+
+// linkGraph returns the posts that each post links to, either with a wiki
+// link or a site-relative link.
+func (c *Catalog) linkGraph() map[*markdown.AST]map[*markdown.AST]bool {
+	byPath := make(map[string]*markdown.AST, 2*len(c.Posts))
+	for _, p := range c.Posts {
+		byPath[URLPath(p)] = p
+		byPath[strings.TrimSuffix(p.Meta.Path, "/")] = p
+	}
+	linksTo := make(map[*markdown.AST]map[*markdown.AST]bool, len(c.Posts))
+	for _, p := range c.Posts {
+		for _, l := range p.Links {
+			if dest, ok := byPath[l]; ok && dest != p {
+				if linksTo[p] == nil {
+					linksTo[p] = make(map[*markdown.AST]bool)
+				}
+				linksTo[p][dest] = true
+			}
+		}
+	}
+	return linksTo
+}
+
+// Backlinks returns the published posts that link to each post, newest
+// first.
+func (c *Catalog) Backlinks() map[*markdown.AST][]*markdown.AST {
+	backlinks := make(map[*markdown.AST][]*markdown.AST)
+	for src, dests := range c.linkGraph() {
+		if src.Meta.Visibility != mdext.VisibilityPublished {
+			continue
+		}
+		for dest := range dests {
+			backlinks[dest] = append(backlinks[dest], src)
+		}
+	}
+	for _, srcs := range backlinks {
+		sort.Slice(srcs, func(i, j int) bool {
+			a, b := srcs[i].Meta, srcs[j].Meta
+			if !a.Date.Equal(b.Date) {
+				return a.Date.After(b.Date)
+			}
+			return a.Slug < b.Slug
+		})
+	}
+	return backlinks
+}
+
+// wikiLinks returns the wiki links in the post.
+func wikiLinks(p *markdown.AST) []*mdext.WikiLink {
+	var links []*mdext.WikiLink
+	_ = ast.Walk(p.Node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if link, ok := n.(*mdext.WikiLink); ok && entering {
+			links = append(links, link)
+		}
+		return ast.WalkContinue, nil
+	})
+	return links
+}
+
+// resolveWikiLinks points each wiki link at the post with the slug. Links to
+// unknown posts stay unresolved and are reported by Validate.
+func (c *Catalog) resolveWikiLinks() {
+	bySlug := make(map[string]*markdown.AST, len(c.Posts))
+	for _, p := range c.Posts {
+		bySlug[p.Meta.Slug] = p
+	}
+	for _, p := range c.Posts {
+		for _, link := range wikiLinks(p) {
+			if dest, ok := bySlug[link.Slug]; ok {
+				link.Resolve(URLPath(dest), dest.Meta.Title)
+			}
+		}
+	}
+}
+
+// validateWikiLinks checks that each wiki link targets a post, that the
+// heading exists in the target and that a published post doesn't link to a
+// draft, which isn't built for the published site.
+func validateWikiLinks(diags *diag.Collector, posts []*markdown.AST) {
+	bySlug := make(map[string]*markdown.AST, len(posts))
+	for _, p := range posts {
+		bySlug[p.Meta.Slug] = p
+	}
+	for _, p := range posts {
+		for _, link := range wikiLinks(p) {
+			dest, ok := bySlug[link.Slug]
+			if !ok {
+				addError(diags, p, link.Offset, "unknown wiki link target %q", link.Slug)
+				continue
+			}
+			if _, ok := dest.HeadingIDs[link.Fragment]; link.Fragment != "" && !ok {
+				addError(diags, p, link.Offset, "no heading with ID %q in %s", link.Fragment, relPath(dest.Path))
+				continue
+			}
+			if p.Meta.Visibility == mdext.VisibilityPublished && dest.Meta.Visibility != mdext.VisibilityPublished {
+				addError(diags, p, link.Offset, "wiki link from a published post to draft %q", link.Slug)
+			}
+		}
+	}
+}
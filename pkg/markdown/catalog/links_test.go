@@ -0,0 +1,91 @@
+package catalog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jschaf/jsc/pkg/markdown"
+	"github.com/jschaf/jsc/pkg/testing/difftest"
+)
+
+func TestCatalog_Backlinks(t *testing.T) {
+	post := func(slug, date, visibility, body string) string {
+		return "+++\nslug = \"" + slug + "\"\ndate = " + date + "\nvisibility = \"" + visibility + "\"\n+++\n# " + slug + " title\n\n" + body + "\n"
+	}
+	cat := &Catalog{}
+	md := newParser()
+	for slug, src := range map[string]string{
+		"alpha":   post("alpha", "2020-01-01", "published", "Nothing."),
+		"bravo":   post("bravo", "2020-01-02", "published", "See [[alpha]]."),
+		"charlie": post("charlie", "2020-01-03", "published", "See [alpha](/alpha/) and [[bravo]]."),
+		"draft":   post("draft", "2020-01-04", "draft", "See [[alpha]]."),
+	} {
+		ast, err := md.Parse("/"+slug+".md", strings.NewReader(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cat.Posts = append(cat.Posts, ast)
+	}
+	got := make(map[string][]string)
+	for dest, srcs := range cat.Backlinks() {
+		for _, src := range srcs {
+			got[dest.Meta.Slug] = append(got[dest.Meta.Slug], src.Meta.Slug)
+		}
+	}
+	difftest.AssertSame(t, map[string][]string{
+		"alpha": {"charlie", "bravo"},
+		"bravo": {"charlie"},
+	}, got)
+}
+
+func TestCatalog_ResolveWikiLinks(t *testing.T) {
+	cat := &Catalog{}
+	md := newParser()
+	for slug, src := range map[string]string{
+		"alpha": "+++\nslug = \"alpha\"\n+++\n# Alpha *title*\n",
+		"bravo": "+++\nslug = \"bravo\"\n+++\n# Bravo\n\nSee [[alpha]], [[alpha|text]] and [[zulu]].\n",
+	} {
+		ast, err := md.Parse("/"+slug+".md", strings.NewReader(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cat.Posts = append(cat.Posts, ast)
+	}
+	cat.resolveWikiLinks()
+	for _, p := range cat.Posts {
+		if p.Meta.Slug != "bravo" {
+			continue
+		}
+		b := &bytes.Buffer{}
+		if err := md.Render(b, p.Source, p); err != nil {
+			t.Fatal(err)
+		}
+		want := `See <a href="/alpha">Alpha title</a>, <a href="/alpha">text</a> and <a href="/zulu">zulu</a>.`
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("rendered post doesn't include %q:\n\n%s", want, b.String())
+		}
+	}
+}
+
+func TestCatalog_ResolveWikiLinks_Scheduled(t *testing.T) {
+	cat := &Catalog{}
+	md := newParser(markdown.WithNow(time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)))
+	for slug, src := range map[string]string{
+		"alpha": "+++\nslug = \"alpha\"\nvisibility = \"published\"\npublish_at = 2020-01-03T09:00:00Z\n+++\n# Alpha\n",
+		"bravo": "+++\nslug = \"bravo\"\nvisibility = \"draft\"\n+++\n# Bravo\n\nSee [[alpha]].\n",
+	} {
+		ast, err := md.Parse("/"+slug+".md", strings.NewReader(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cat.Posts = append(cat.Posts, ast)
+	}
+	cat.resolveWikiLinks()
+	for _, p := range cat.Posts {
+		for _, link := range wikiLinks(p) {
+			difftest.AssertSame(t, "/drafts/alpha", link.Dest)
+		}
+	}
+}
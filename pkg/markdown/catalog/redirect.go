@@ -0,0 +1,23 @@
+package catalog
+
+import (
+	"strings"
+
+	"github.com/jschaf/jsc/pkg/markdown/mdext"
+)
+
+// Redirects returns the URL path of each alias of the published posts mapped
+// to the URL path of the post, like "/old-slug" to "/new-slug". Paths don't
+// have a trailing slash, matching the clean URLs served by Firebase.
+func (c *Catalog) Redirects() map[string]string {
+	redirects := make(map[string]string)
+	for _, p := range c.Posts {
+		if p.Meta.Visibility != mdext.VisibilityPublished {
+			continue
+		}
+		for _, a := range p.Meta.Aliases {
+			redirects[strings.TrimSuffix(a, "/")] = URLPath(p)
+		}
+	}
+	return redirects
+}
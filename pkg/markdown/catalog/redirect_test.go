@@ -0,0 +1,40 @@
+package catalog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jschaf/jsc/pkg/testing/difftest"
+	"github.com/jschaf/jsc/pkg/texts"
+)
+
+func TestCatalog_Redirects(t *testing.T) {
+	post := func(slug, visibility string) string {
+		return texts.Dedent(`
+			+++
+			slug = "` + slug + `"
+			date = 2020-01-02
+			visibility = "` + visibility + `"
+			aliases = ["/old-` + slug + `/", "/2020/` + slug + `"]
+			+++
+			# Title
+		`)
+	}
+	cat := &Catalog{}
+	md := newParser()
+	for path, src := range map[string]string{
+		"/a.md": post("alpha", "published"),
+		"/b.md": post("bravo", "draft"),
+	} {
+		ast, err := md.Parse(path, strings.NewReader(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cat.Posts = append(cat.Posts, ast)
+	}
+	want := map[string]string{
+		"/old-alpha":  "/alpha",
+		"/2020/alpha": "/alpha",
+	}
+	difftest.AssertSame(t, want, cat.Redirects())
+}
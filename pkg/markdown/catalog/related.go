@@ -0,0 +1,130 @@
+package catalog
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jschaf/bibtex"
+	"github.com/jschaf/jsc/pkg/markdown"
+	"github.com/jschaf/jsc/pkg/markdown/mdext"
+)
+
+// Weights of each signal that two posts are related. A link between posts is
+// the strongest signal since the author chose to connect the posts.
+const (
+	relatedTagWeight  = 3
+	relatedCiteWeight = 2
+	relatedLinkWeight = 4
+)
+
+// Relation is why a post is related to another post.
+type Relation struct {
+	// Post is the related post.
+	Post  *markdown.AST
+	Score int
+	// SharedTags are the tags of both posts, sorted.
+	SharedTags []string
+	// SharedCites are the BibTeX keys cited by both posts, sorted.
+	SharedCites []bibtex.CiteKey
+	// LinksTo is true if the post links to the related post.
+	LinksTo bool
+	// LinkedFrom is true if the related post links to the post.
+	LinkedFrom bool
+}
+
+// Explain describes how the score adds up, like
+// "10 = tags go, sql (+6) + links to (+4)".
+func (r Relation) Explain() string {
+	var parts []string
+	if n := len(r.SharedTags); n > 0 {
+		parts = append(parts, fmt.Sprintf("tags %s (+%d)", strings.Join(r.SharedTags, ", "), n*relatedTagWeight))
+	}
+	if n := len(r.SharedCites); n > 0 {
+		keys := make([]string, n)
+		for i, k := range r.SharedCites {
+			keys[i] = string(k)
+		}
+		parts = append(parts, fmt.Sprintf("cites %s (+%d)", strings.Join(keys, ", "), n*relatedCiteWeight))
+	}
+	if r.LinksTo {
+		parts = append(parts, fmt.Sprintf("links to (+%d)", relatedLinkWeight))
+	}
+	if r.LinkedFrom {
+		parts = append(parts, fmt.Sprintf("linked from (+%d)", relatedLinkWeight))
+	}
+	return fmt.Sprintf("%d = %s", r.Score, strings.Join(parts, " + "))
+}
+
+// Related returns the at most n published posts most related to each post in
+// the catalog, highest score first. Posts without a shared tag, a shared
+// citation or a link aren't related.
+func (c *Catalog) Related(n int) map[*markdown.AST][]Relation {
+	related := make(map[*markdown.AST][]Relation, len(c.Posts))
+	if n <= 0 {
+		return related
+	}
+	var published []*markdown.AST
+	for _, p := range c.Posts {
+		if p.Meta.Visibility == mdext.VisibilityPublished {
+			published = append(published, p)
+		}
+	}
+	linksTo := c.linkGraph()
+
+	for _, p := range c.Posts {
+		var rels []Relation
+		for _, q := range published {
+			if q == p {
+				continue
+			}
+			r := Relation{
+				Post:        q,
+				SharedTags:  intersect(p.Meta.Tags, q.Meta.Tags),
+				SharedCites: intersect(p.CiteKeys, q.CiteKeys),
+				LinksTo:     linksTo[p][q],
+				LinkedFrom:  linksTo[q][p],
+			}
+			r.Score = len(r.SharedTags)*relatedTagWeight + len(r.SharedCites)*relatedCiteWeight
+			if r.LinksTo {
+				r.Score += relatedLinkWeight
+			}
+			if r.LinkedFrom {
+				r.Score += relatedLinkWeight
+			}
+			if r.Score > 0 {
+				rels = append(rels, r)
+			}
+		}
+		// Break ties with the newest post, then the slug so the order is
+		// stable.
+		sort.Slice(rels, func(i, j int) bool {
+			a, b := rels[i], rels[j]
+			switch {
+			case a.Score != b.Score:
+				return a.Score > b.Score
+			case !a.Post.Meta.Date.Equal(b.Post.Meta.Date):
+				return a.Post.Meta.Date.After(b.Post.Meta.Date)
+			default:
+				return a.Post.Meta.Slug < b.Post.Meta.Slug
+			}
+		})
+		if len(rels) > 0 {
+			related[p] = rels[:min(n, len(rels))]
+		}
+	}
+	return related
+}
+
+// intersect returns the elements in both a and b, sorted.
+func intersect[T ~string](a, b []T) []T {
+	var both []T
+	for _, x := range a {
+		if slices.Contains(b, x) && !slices.Contains(both, x) {
+			both = append(both, x)
+		}
+	}
+	slices.Sort(both)
+	return both
+}
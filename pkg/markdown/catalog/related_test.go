@@ -0,0 +1,53 @@
+package catalog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jschaf/jsc/pkg/testing/difftest"
+)
+
+func TestCatalog_Related(t *testing.T) {
+	post := func(slug, visibility, tags, body string) string {
+		return "+++\nslug = \"" + slug + "\"\ndate = 2020-01-02\nvisibility = \"" + visibility + "\"\ntags = [" + tags + "]\n+++\n# Title\n\n" + body + "\n"
+	}
+	cat := &Catalog{}
+	md := newParser()
+	for slug, src := range map[string]string{
+		"alpha":   post("alpha", "published", `"go", "sql"`, "See [bravo](/bravo/)."),
+		"bravo":   post("bravo", "published", `"go"`, "See [alpha](/alpha#intro)."),
+		"charlie": post("charlie", "published", `"go", "sql"`, "Nothing."),
+		"delta":   post("delta", "published", `"rust"`, "Nothing."),
+		"draft":   post("draft", "draft", `"go", "sql"`, "Nothing."),
+	} {
+		ast, err := md.Parse("/"+slug+".md", strings.NewReader(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cat.Posts = append(cat.Posts, ast)
+	}
+	got := make(map[string][]string)
+	for p, rels := range cat.Related(2) {
+		for _, r := range rels {
+			got[p.Meta.Slug] = append(got[p.Meta.Slug], r.Post.Meta.Slug+": "+r.Explain())
+		}
+	}
+	difftest.AssertSame(t, map[string][]string{
+		"alpha": {
+			"bravo: 11 = tags go (+3) + links to (+4) + linked from (+4)",
+			"charlie: 6 = tags go, sql (+6)",
+		},
+		"bravo": {
+			"alpha: 11 = tags go (+3) + links to (+4) + linked from (+4)",
+			"charlie: 3 = tags go (+3)",
+		},
+		"charlie": {
+			"alpha: 6 = tags go, sql (+6)",
+			"bravo: 3 = tags go (+3)",
+		},
+		"draft": {
+			"alpha: 6 = tags go, sql (+6)",
+			"charlie: 6 = tags go, sql (+6)",
+		},
+	}, got)
+}
@@ -0,0 +1,45 @@
+package catalog
+
+import (
+	"sort"
+
+	"github.com/jschaf/jsc/pkg/markdown"
+)
+
+// Series is a group of posts with the same series in the front matter.
+type Series struct {
+	Name string
+	// Posts are sorted by series_order, then by date for posts without an
+	// order.
+	Posts []*markdown.AST
+}
+
+// Series returns every series of the posts in the catalog, sorted by name.
+func (c *Catalog) Series() []Series {
+	byName := make(map[string][]*markdown.AST)
+	for _, p := range c.Posts {
+		if p.Meta.Series != "" {
+			byName[p.Meta.Series] = append(byName[p.Meta.Series], p)
+		}
+	}
+	series := make([]Series, 0, len(byName))
+	for name, posts := range byName {
+		sort.SliceStable(posts, func(i, j int) bool { return seriesLess(posts[i], posts[j]) })
+		series = append(series, Series{Name: name, Posts: posts})
+	}
+	sort.Slice(series, func(i, j int) bool { return series[i].Name < series[j].Name })
+	return series
+}
+
+// seriesLess orders posts with a series_order first, then by date.
+func seriesLess(a, b *markdown.AST) bool {
+	ao, bo := a.Meta.SeriesOrder, b.Meta.SeriesOrder
+	switch {
+	case ao != bo && (ao == 0 || bo == 0):
+		return bo == 0
+	case ao != bo:
+		return ao < bo
+	default:
+		return a.Meta.Date.Before(b.Meta.Date)
+	}
+}
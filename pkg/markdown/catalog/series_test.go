@@ -0,0 +1,47 @@
+package catalog
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jschaf/jsc/pkg/testing/difftest"
+)
+
+func TestCatalog_Series(t *testing.T) {
+	post := func(slug, date, series string, order int) string {
+		src := "+++\nslug = \"" + slug + "\"\ndate = " + date + "\nvisibility = \"published\"\nseries = \"" + series + "\"\n"
+		if order > 0 {
+			src += "series_order = " + strconv.Itoa(order) + "\n"
+		}
+		return src + "+++\n# Title\n"
+	}
+	cat := &Catalog{}
+	md := newParser()
+	for path, src := range map[string]string{
+		"/a.md": post("a", "2020-01-05", "perf", 2),
+		"/b.md": post("b", "2020-01-01", "perf", 0),
+		"/c.md": post("c", "2020-01-09", "perf", 1),
+		"/d.md": post("d", "2019-12-30", "perf", 0),
+		"/e.md": post("e", "2020-01-03", "go", 0),
+	} {
+		ast, err := md.Parse(path, strings.NewReader(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cat.Posts = append(cat.Posts, ast)
+	}
+	got := make(map[string][]string)
+	var names []string
+	for _, s := range cat.Series() {
+		names = append(names, s.Name)
+		for _, p := range s.Posts {
+			got[s.Name] = append(got[s.Name], p.Meta.Slug)
+		}
+	}
+	difftest.AssertSame(t, []string{"go", "perf"}, names)
+	difftest.AssertSame(t, map[string][]string{
+		"go":   {"e"},
+		"perf": {"c", "a", "d", "b"},
+	}, got)
+}
@@ -0,0 +1,212 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jschaf/jsc/pkg/dirs"
+	"github.com/jschaf/jsc/pkg/git"
+	"github.com/jschaf/jsc/pkg/markdown"
+	"github.com/jschaf/jsc/pkg/markdown/diag"
+	"github.com/jschaf/jsc/pkg/markdown/mdext"
+)
+
+// Validate checks the frontmatter of every post and consistency across posts,
+// like duplicate slugs, so that problems are found before anything is
+// written to the dist dir. Returns a diag.List error.
+func (c *Catalog) Validate() error {
+	diags := &diag.Collector{}
+	// Sort by path so the first post to claim a slug or output is stable.
+	posts := make([]*markdown.AST, len(c.Posts))
+	copy(posts, c.Posts)
+	sort.Slice(posts, func(i, j int) bool { return posts[i].Path < posts[j].Path })
+
+	for _, p := range posts {
+		validateRequired(diags, p)
+	}
+
+	slugs := make(map[string]*markdown.AST, len(posts))
+	dupes := make(map[*markdown.AST]bool)
+	for _, p := range posts {
+		if p.Meta.Slug == "" {
+			continue
+		}
+		if first, ok := slugs[p.Meta.Slug]; ok {
+			addError(diags, p, mdext.FrontMatterKeyOffset(p.Source, "slug"),
+				"duplicate slug %q already used by %s", p.Meta.Slug, relPath(first.Path))
+			dupes[p] = true
+			continue
+		}
+		slugs[p.Meta.Slug] = p
+	}
+
+	reserved, err := reservedOutputs()
+	if err != nil {
+		return fmt.Errorf("find reserved outputs: %w", err)
+	}
+	outputs := make(map[string]*markdown.AST)
+	for _, p := range posts {
+		// Duplicate slugs always collide, so only report the slug.
+		if dupes[p] || p.Meta.Slug == "" {
+			continue
+		}
+		if reason, ok := reserved[strings.SplitN(p.Meta.Slug, "/", 2)[0]]; ok {
+			addError(diags, p, mdext.FrontMatterKeyOffset(p.Source, "slug"),
+				"slug %q collides with %s", p.Meta.Slug, reason)
+			continue
+		}
+		for _, out := range postOutputs(p) {
+			if first, ok := outputs[out]; ok && first != p {
+				addError(diags, p, diag.NoOffset,
+					"output %s collides with output of %s", out, relPath(first.Path))
+				continue
+			}
+			outputs[out] = p
+		}
+	}
+	validateAliases(diags, posts, dupes, reserved)
+	validateSeries(diags, posts)
+	// A partial catalog doesn't have every target of a wiki link.
+	if c.IsComplete() {
+		validateWikiLinks(diags, posts)
+	}
+	return diags.Err()
+}
+
+// validateSeries checks that no two posts in a series have the same
+// series_order.
+func validateSeries(diags *diag.Collector, posts []*markdown.AST) {
+	type position struct {
+		series string
+		order  int
+	}
+	seen := make(map[position]*markdown.AST)
+	for _, p := range posts {
+		if p.Meta.SeriesOrder == 0 {
+			continue
+		}
+		pos := position{p.Meta.Series, p.Meta.SeriesOrder}
+		if first, ok := seen[pos]; ok {
+			addError(diags, p, mdext.FrontMatterKeyOffset(p.Source, "series_order"),
+				"duplicate series_order %d in series %q already used by %s", pos.order, pos.series, relPath(first.Path))
+			continue
+		}
+		seen[pos] = p
+	}
+}
+
+// validateAliases checks that no alias collides with the URL path of a post,
+// a reserved output or another alias. Firebase serves existing content before
+// redirects, so a colliding alias would never redirect.
+func validateAliases(diags *diag.Collector, posts []*markdown.AST, dupes map[*markdown.AST]bool, reserved map[string]string) {
+	live := make(map[string]*markdown.AST, len(posts))
+	for _, p := range posts {
+		if !dupes[p] && p.Meta.Slug != "" {
+			live[URLPath(p)] = p
+		}
+	}
+	aliases := make(map[string]*markdown.AST)
+	for _, p := range posts {
+		offset := mdext.FrontMatterKeyOffset(p.Source, "aliases")
+		for _, a := range p.Meta.Aliases {
+			urlPath := strings.TrimSuffix(a, "/")
+			if other, ok := live[urlPath]; ok {
+				if other == p {
+					addError(diags, p, offset, "alias %q is the path of the post", a)
+				} else {
+					addError(diags, p, offset, "alias %q collides with the slug of %s", a, relPath(other.Path))
+				}
+				continue
+			}
+			if reason, ok := reserved[strings.TrimPrefix(urlPath, "/")]; ok {
+				addError(diags, p, offset, "alias %q collides with %s", a, reason)
+				continue
+			}
+			if first, ok := aliases[urlPath]; ok {
+				addError(diags, p, offset, "duplicate alias %q already used by %s", a, relPath(first.Path))
+				continue
+			}
+			aliases[urlPath] = p
+		}
+	}
+}
+
+// validateRequired checks that the post has every required frontmatter
+// field.
+func validateRequired(diags *diag.Collector, p *markdown.AST) {
+	if p.Meta.Slug == "" && p.Meta.Date.IsZero() && p.Meta.Visibility == "" {
+		addError(diags, p, 0, "missing front matter")
+		return
+	}
+	if p.Meta.Slug == "" {
+		addError(diags, p, 0, "missing slug in front matter")
+	}
+	if p.Meta.Date.IsZero() {
+		addError(diags, p, 0, "missing date in front matter")
+	}
+	if p.Meta.Visibility == "" {
+		addError(diags, p, 0, "missing visibility in front matter; want %q or %q",
+			mdext.VisibilityPublished, mdext.VisibilityDraft)
+	}
+}
+
+func addError(diags *diag.Collector, p *markdown.AST, offset int, format string, args ...any) {
+	diags.AddFile(p.Path, p.Source, diag.Errorf("catalog", offset, format, args...))
+}
+
+// postOutputs returns the paths, relative to the dist dir, of every file
+// written for the post.
+func postOutputs(p *markdown.AST) []string {
+	outs := []string{DetailDest(p)}
+	for _, a := range p.Assets {
+		outs = append(outs, filepath.Clean(strings.TrimPrefix(a.Dest, "/")))
+	}
+	return outs
+}
+
+// reservedOutputs returns the top-level names in the dist dir written by
+// something other than a post mapped to a description of the writer.
+func reservedOutputs() (map[string]string, error) {
+	reserved := map[string]string{
+		"index.html":                             "the index page",
+		"sitemap.xml":                            "the sitemap",
+		"atom.xml":                               "the Atom feed",
+		"rss.xml":                                "the RSS feed",
+		"feed.json":                              "the JSON feed",
+		dirs.TIL:                                 "the TIL dir",
+		"page":                                   "the home page pagination dir",
+		"archive":                                "the archive dir",
+		"tags":                                   "the tags dir",
+		"series":                                 "the series dir",
+		dirs.Style:                               "the style dir",
+		dirs.Papers:                              "the papers dir",
+		strings.Trim(mdext.DraftPathPrefix, "/"): "the drafts dir",
+	}
+	entries, err := os.ReadDir(filepath.Join(git.RootDir(), dirs.Static))
+	if errors.Is(err, os.ErrNotExist) {
+		return reserved, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read static dir: %w", err)
+	}
+	for _, e := range entries {
+		name := e.Name()
+		// TypeScript is bundled into a JavaScript file with the same name.
+		if ext := filepath.Ext(name); ext == ".ts" {
+			name = strings.TrimSuffix(name, ext) + ".js"
+		}
+		reserved[name] = "static file " + e.Name()
+	}
+	return reserved, nil
+}
+
+func relPath(path string) string {
+	rel, err := filepath.Rel(git.RootDir(), path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,129 @@
+package catalog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jschaf/jsc/pkg/markdown/diag"
+	"github.com/jschaf/jsc/pkg/testing/difftest"
+	"github.com/jschaf/jsc/pkg/texts"
+)
+
+func TestCatalog_Validate(t *testing.T) {
+	post := func(slug string) string {
+		return texts.Dedent(`
+			+++
+			slug = "` + slug + `"
+			date = 2020-01-02
+			visibility = "published"
+			+++
+			# Title
+		`)
+	}
+	aliased := func(slug string, aliases ...string) string {
+		return texts.Dedent(`
+			+++
+			slug = "` + slug + `"
+			date = 2020-01-02
+			visibility = "published"
+			aliases = ["` + strings.Join(aliases, `", "`) + `"]
+			+++
+			# Title
+		`)
+	}
+	tests := []struct {
+		name  string
+		posts map[string]string
+		want  []string
+	}{
+		{
+			"valid",
+			map[string]string{"/a.md": post("alpha"), "/b.md": post("bravo")},
+			nil,
+		},
+		{
+			"duplicate slug",
+			map[string]string{"/a.md": post("alpha"), "/b.md": post("alpha")},
+			[]string{`/b.md:2:1: catalog: duplicate slug "alpha" already used by /a.md`},
+		},
+		{
+			"reserved slug",
+			map[string]string{"/a.md": post("style")},
+			[]string{`/a.md:2:1: catalog: slug "style" collides with the style dir`},
+		},
+		{
+			"alias",
+			map[string]string{"/a.md": aliased("alpha", "/old-alpha/"), "/b.md": post("bravo")},
+			nil,
+		},
+		{
+			"alias collides with slug",
+			map[string]string{"/a.md": aliased("alpha", "/bravo/"), "/b.md": post("bravo")},
+			[]string{`/a.md:5:1: catalog: alias "/bravo/" collides with the slug of /b.md`},
+		},
+		{
+			"alias is own slug",
+			map[string]string{"/a.md": aliased("alpha", "/alpha")},
+			[]string{`/a.md:5:1: catalog: alias "/alpha/" is the path of the post`},
+		},
+		{
+			"alias collides with reserved",
+			map[string]string{"/a.md": aliased("alpha", "/tags/")},
+			[]string{`/a.md:5:1: catalog: alias "/tags/" collides with the tags dir`},
+		},
+		{
+			"duplicate alias",
+			map[string]string{"/a.md": aliased("alpha", "/old/"), "/b.md": aliased("bravo", "/old")},
+			[]string{`/b.md:5:1: catalog: duplicate alias "/old/" already used by /a.md`},
+		},
+		{
+			"duplicate series_order",
+			map[string]string{
+				"/a.md": "+++\nslug = \"alpha\"\ndate = 2020-01-02\nvisibility = \"published\"\nseries = \"perf\"\nseries_order = 1\n+++\n# Title\n",
+				"/b.md": "+++\nslug = \"bravo\"\ndate = 2020-01-02\nvisibility = \"published\"\nseries = \"perf\"\nseries_order = 1\n+++\n# Title\n",
+			},
+			[]string{`/b.md:6:1: catalog: duplicate series_order 1 in series "perf" already used by /a.md`},
+		},
+		{
+			"wiki links",
+			map[string]string{
+				"/a.md": post("alpha") + "\n## Intro\n\nSee [[bravo]], [[bravo#missing]] and [[zulu]].\n",
+				"/b.md": post("bravo") + "\nSee [[alpha#intro]] and [[draft]].\n",
+				"/d.md": "+++\nslug = \"draft\"\ndate = 2020-01-02\nvisibility = \"draft\"\n+++\n# Title\n",
+			},
+			[]string{
+				`/a.md:9:16: catalog: no heading with ID "missing" in /b.md`,
+				`/a.md:9:38: catalog: unknown wiki link target "zulu"`,
+				`/b.md:7:25: catalog: wiki link from a published post to draft "draft"`,
+			},
+		},
+		{
+			"missing front matter",
+			map[string]string{"/a.md": "# Title\n"},
+			[]string{`/a.md:1:1: catalog: missing front matter`},
+		},
+		{
+			"missing date",
+			map[string]string{"/a.md": "+++\nslug = \"alpha\"\nvisibility = \"draft\"\n+++\n# Title\n"},
+			[]string{`/a.md:1:1: catalog: missing date in front matter`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cat := &Catalog{}
+			md := newParser()
+			for path, src := range tt.posts {
+				ast, err := md.Parse(path, strings.NewReader(src))
+				if err != nil {
+					t.Fatal(err)
+				}
+				cat.Posts = append(cat.Posts, ast)
+			}
+			var got []string
+			for _, d := range diag.FromError(cat.Validate()) {
+				got = append(got, d.Error())
+			}
+			difftest.AssertSame(t, tt.want, got)
+		})
+	}
+}
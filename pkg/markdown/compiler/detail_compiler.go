@@ -3,58 +3,67 @@ package compiler
 import (
 	"bytes"
 	"fmt"
-	"github.com/jschaf/jsc/pkg/markdown/mdctx"
 	"html/template"
 	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 
-	"github.com/jschaf/jsc/pkg/dirs"
+	"github.com/jschaf/jsc/pkg/config"
 	"github.com/jschaf/jsc/pkg/errs"
-	"github.com/jschaf/jsc/pkg/git"
 	"github.com/jschaf/jsc/pkg/markdown"
 	"github.com/jschaf/jsc/pkg/markdown/assets"
+	"github.com/jschaf/jsc/pkg/markdown/catalog"
+	"github.com/jschaf/jsc/pkg/markdown/diag"
 	"github.com/jschaf/jsc/pkg/markdown/html"
+	"github.com/jschaf/jsc/pkg/markdown/mdctx"
 	"github.com/jschaf/jsc/pkg/markdown/mdext"
-	"github.com/jschaf/jsc/pkg/paths"
-	"github.com/karrick/godirwalk"
+	"github.com/jschaf/jsc/render/cards"
+	gast "github.com/yuin/goldmark/ast"
+	"golang.org/x/sync/errgroup"
 )
 
 // DetailCompiler compiles the detail page for each post.
 type DetailCompiler struct {
-	md      *markdown.Markdown
-	distDir string
+	md       *markdown.Markdown
+	cfg      *config.Config
+	distDir  string
+	manifest *manifest
+	hasher   *fileHasher
+	// series is the series of each post in a series, set by Compile.
+	series map[*markdown.AST]*html.SeriesParams
+	// related is the related posts of each post, set by Compile.
+	related map[*markdown.AST][]html.PostLinkParams
+	// backlinks is the posts that link to each post, set by Compile.
+	backlinks map[*markdown.AST][]html.PostLinkParams
+	// docs is the post of each document node, set by Compile, to look up the
+	// stats of the post while rendering.
+	docs map[gast.Node]*markdown.AST
 }
 
 // NewDetailCompiler creates a compiler for a detail page.
-func NewDetailCompiler(distDir string) *DetailCompiler {
-	md := markdown.New(
+func NewDetailCompiler(cfg *config.Config, distDir string) *DetailCompiler {
+	c := &DetailCompiler{cfg: cfg, distDir: distDir, hasher: newFileHasher()}
+	c.md = markdown.New(
 		markdown.WithHeadingAnchorStyle(mdext.HeadingAnchorStyleShow),
 		markdown.WithTOCStyle(mdext.TOCStyleShow),
 		markdown.WithExtender(mdext.NewNopContinueReadingExt()),
+		markdown.WithPostStats(c.postStats),
 	)
-	return &DetailCompiler{md: md, distDir: distDir}
+	return c
 }
 
-// parseFile parses a single path into a markdown AST.
-func (c *DetailCompiler) parseFile(path string) (*markdown.AST, error) {
-	slog.Debug("compiling detail", "path", path)
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, fmt.Errorf("open TIL post %s: %w", path, err)
-	}
-	src, err := io.ReadAll(f)
-	if err != nil {
-		return nil, fmt.Errorf("read post at path %s: %w", path, err)
+// postStats returns the reading time and last modified date shown in the
+// header of the post with the document node.
+func (c *DetailCompiler) postStats(doc gast.Node) (mdext.PostStatsData, bool) {
+	ast, ok := c.docs[doc]
+	if !ok {
+		return mdext.PostStatsData{}, false
 	}
-	ast, err := c.md.Parse(path, bytes.NewReader(src))
-	if err != nil {
-		return nil, fmt.Errorf("parseFile post markdown at path %s: %w", path, err)
-	}
-	return ast, nil
+	return mdext.PostStatsData{ReadingMinutes: ast.Stats.ReadingMinutes(), Updated: updatedDate(ast)}, true
 }
 
 func (c *DetailCompiler) createDestFile(ast *markdown.AST) (*os.File, error) {
@@ -62,11 +71,10 @@ func (c *DetailCompiler) createDestFile(ast *markdown.AST) (*os.File, error) {
 	if slug == "" {
 		return nil, fmt.Errorf("empty slug for path: %s", ast.Path)
 	}
-	slugDir := filepath.Join(c.distDir, slug)
-	if err := os.MkdirAll(slugDir, 0o755); err != nil {
+	dest := filepath.Join(c.distDir, catalog.DetailDest(ast))
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
 		return nil, fmt.Errorf("make dir for slug %s: %w", slug, err)
 	}
-	dest := filepath.Join(slugDir, "index.html")
 	destFile, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
 	if err != nil {
 		return nil, fmt.Errorf("create dest file %q for post: %w", dest, err)
@@ -80,58 +88,147 @@ func (c *DetailCompiler) compileAST(ast *markdown.AST, w io.Writer) error {
 	if err := c.md.Render(b, ast.Source, ast); err != nil {
 		return fmt.Errorf("failed to render markdown: %w", err)
 	}
-	ast.Features.Add(mdctx.FeatureComments)
+	// Copy the features since the AST is shared with the index compiler.
+	feats := mdctx.NewFeatureSet()
+	feats.AddAll(ast.Features)
+	if ast.Meta.Visibility == mdext.VisibilityDraft {
+		// Don't start a comment thread for a post that might change its URL.
+		feats.Add(mdctx.FeatureDraft)
+	} else {
+		feats.Add(mdctx.FeatureComments)
+	}
 	data := html.DetailParams{
-		Title:    ast.Meta.Title,
-		Content:  template.HTML(b.String()),
-		Features: ast.Features,
+		Site:      c.cfg,
+		Title:     ast.Meta.Title,
+		Meta:      detailPageMeta(c.cfg, ast),
+		Content:   template.HTML(b.String()),
+		Features:  feats,
+		Tags:      detailTags(ast),
+		Revisions: revisionParams(c.cfg, ast),
+		Series:    c.series[ast],
+		Related:   c.related[ast],
+		Backlinks: c.backlinks[ast],
 	}
 	if err := html.RenderDetail(w, data); err != nil {
 		return fmt.Errorf("failed to execute post template: %w", err)
 	}
 
-	if err := assets.CopyAll(c.distDir, ast.Assets); err != nil {
+	if err := assets.CopyAll(c.distDir, c.postAssets(ast)); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (c *DetailCompiler) Compile(glob string) error {
-	err := c.compileDir(filepath.Join(git.RootDir(), dirs.Posts), glob)
-	if err != nil {
-		return fmt.Errorf("compile posts dir: %w", err)
+// postAssets returns the assets of the post and the generated social preview
+// image.
+func (c *DetailCompiler) postAssets(ast *markdown.AST) []assets.Blob {
+	blobs := ast.Assets
+	if card, ok := cardBlob(c.cfg, ast); ok {
+		blobs = append(slices.Clip(blobs), card)
 	}
-	err = c.compileDir(filepath.Join(git.RootDir(), dirs.TIL), glob)
-	if err != nil {
-		return fmt.Errorf("compile til dir: %w", err)
+	return blobs
+}
+
+// detailStamp returns the manifest stamp of the detail page of a post. Stamps
+// with the visibility since a scheduled post goes live without any change to
+// its inputs, with the latest commit since committing a post changes its
+// last modified time without changing its contents, and with the parts of
+// the series, the related posts, the backlinks and the wiki link targets
+// since all are other posts.
+func (c *DetailCompiler) detailStamp(ast *markdown.AST) string {
+	return ast.Meta.Visibility + "@" + ast.Revision() +
+		seriesStamp(c.series[ast]) +
+		postLinksStamp("related", c.related[ast]) +
+		postLinksStamp("backlinks", c.backlinks[ast]) +
+		wikiLinksStamp(ast)
+}
+
+// wikiLinksStamp returns the slug, URL path and title of the target of each
+// wiki link in the post, so renaming or moving a target rebuilds the post.
+func wikiLinksStamp(ast *markdown.AST) string {
+	var parts []string
+	_ = gast.Walk(ast.Node, func(n gast.Node, entering bool) (gast.WalkStatus, error) {
+		if link, ok := n.(*mdext.WikiLink); ok && entering {
+			parts = append(parts, link.Slug+"="+link.Dest+"="+link.Title)
+		}
+		return gast.WalkContinue, nil
+	})
+	if len(parts) == 0 {
+		return ""
 	}
-	return nil
+	return ";wikilinks:" + strings.Join(parts, ",")
 }
 
-func (c *DetailCompiler) compileDir(dir string, glob string) (mErr error) {
-	err := paths.WalkConcurrent(dir, runtime.NumCPU(), func(path string, dirent *godirwalk.Dirent) error {
-		if !dirent.IsRegular() || filepath.Ext(path) != ".md" {
-			return nil
+// Compile compiles the detail page of every post in the catalog. Skips posts
+// whose inputs haven't changed since the last build. Compiles every post even
+// if some fail, returning a diag.List error with the diagnostics of each
+// failed post.
+func (c *DetailCompiler) Compile(cat *catalog.Catalog) error {
+	m, err := loadManifest(c.distDir, "detail", c.cfg.Fingerprint())
+	if err != nil {
+		return fmt.Errorf("load detail manifest: %w", err)
+	}
+	c.manifest = m
+	c.series = seriesParams(cat.Series())
+	c.related = relatedParams(cat.Related(c.cfg.Related.Count))
+	c.backlinks = backlinkParams(cat.Backlinks())
+	c.docs = make(map[gast.Node]*markdown.AST, len(cat.Posts))
+	for _, ast := range cat.Posts {
+		c.docs[ast.Node] = ast
+	}
+
+	diags := &diag.Collector{}
+	g := &errgroup.Group{}
+	g.SetLimit(runtime.NumCPU())
+	for _, ast := range cat.Posts {
+		if c.manifest.isFresh(ast.Path, c.detailStamp(ast), c.hasher) {
+			slog.Debug("skip unchanged detail", "path", ast.Path)
+			continue
 		}
-		if glob != "" && !strings.Contains(path, glob) {
+		g.Go(func() error {
+			// A failed post isn't recorded in the manifest, so the next build
+			// retries it.
+			diags.AddFile(ast.Path, ast.Source, c.compilePost(ast))
 			return nil
-		}
+		})
+	}
+	_ = g.Wait()
 
-		ast, err := c.parseFile(path)
-		if err != nil {
-			return fmt.Errorf("parseFile TIL post into AST at path %s: %w", path, err)
-		}
+	// Only prune a complete catalog, otherwise we'd delete the outputs of
+	// every post not in the catalog.
+	if err := c.manifest.save(cat.IsComplete()); err != nil {
+		return fmt.Errorf("save detail manifest: %w", err)
+	}
+	return diags.Err()
+}
 
-		dest, err := c.createDestFile(ast)
-		if err != nil {
-			return err
-		}
-		defer errs.Capture(&mErr, dest.Close, "close dest file")
+// compilePost compiles the detail page for the post and records its inputs
+// and outputs in the manifest.
+func (c *DetailCompiler) compilePost(ast *markdown.AST) (mErr error) {
+	dest, err := c.createDestFile(ast)
+	if err != nil {
+		return err
+	}
+	defer errs.Capture(&mErr, dest.Close, "close dest file")
+
+	if err := c.compileAST(ast, dest); err != nil {
+		return fmt.Errorf("compileAST AST for path %s: %w", ast.Path, err)
+	}
 
-		if err := c.compileAST(ast, dest); err != nil {
-			return fmt.Errorf("compileAST AST for path %s: %w", path, err)
+	inputs := make([]string, 0, 4+len(ast.Deps)+len(ast.Assets))
+	inputs = append(inputs, ast.Path)
+	inputs = append(inputs, ast.Deps...)
+	inputs = append(inputs, html.DetailTemplatePaths()...)
+	inputs = append(inputs, cards.FontPaths()...)
+	outputs := []string{catalog.DetailDest(ast)}
+	for _, a := range c.postAssets(ast) {
+		if a.Src != "" {
+			inputs = append(inputs, a.Src)
 		}
-		return nil
-	})
-	return err
+		outputs = append(outputs, strings.TrimPrefix(a.Dest, "/"))
+	}
+	if err := c.manifest.record(ast.Path, c.detailStamp(ast), inputs, outputs, c.hasher); err != nil {
+		return fmt.Errorf("record detail manifest for path %s: %w", ast.Path, err)
+	}
+	return nil
 }
@@ -1,18 +1,56 @@
 package compiler
 
 import (
+	"path/filepath"
 	"testing"
+	"time"
 
-	"github.com/jschaf/jsc/pkg/dirs"
+	"github.com/jschaf/jsc/pkg/config"
+	"github.com/jschaf/jsc/pkg/git"
+	"github.com/jschaf/jsc/pkg/markdown"
+	"github.com/jschaf/jsc/pkg/markdown/catalog"
+	"github.com/jschaf/jsc/pkg/markdown/mdext"
+	"github.com/jschaf/jsc/pkg/testing/difftest"
+	"github.com/yuin/goldmark/ast"
 )
 
 func BenchmarkNewDetailCompiler_Compile(b *testing.B) {
 	b.StopTimer()
-	c := NewDetailCompiler(dirs.Dist)
+	cfg, err := config.Load(filepath.Join(git.RootDir(), config.FileName), config.EnvProd)
+	if err != nil {
+		b.Fatal(err)
+	}
+	cat, err := catalog.Load("procella", time.Now())
+	if err != nil {
+		b.Fatal(err)
+	}
 	b.StartTimer()
 	for i := 0; i < b.N; i++ {
-		if err := c.Compile("procella"); err != nil {
+		// Use a new dist dir so the manifest doesn't skip the post.
+		b.StopTimer()
+		c := NewDetailCompiler(cfg, b.TempDir())
+		b.StartTimer()
+		if err := c.Compile(cat); err != nil {
 			b.Fatal(err)
 		}
 	}
 }
+
+func TestWikiLinksStamp(t *testing.T) {
+	link := mdext.NewWikiLink()
+	link.Slug = "alpha"
+	doc := ast.NewDocument()
+	para := ast.NewParagraph()
+	para.AppendChild(para, link)
+	doc.AppendChild(doc, para)
+	post := &markdown.AST{Node: doc}
+
+	link.Resolve("/alpha", "Alpha")
+	before := wikiLinksStamp(post)
+	link.Resolve("/alpha", "Renamed")
+	if after := wikiLinksStamp(post); after == before {
+		t.Errorf("wikiLinksStamp unchanged after renaming the target: %q", after)
+	}
+	link.Resolve("/drafts/alpha", "Renamed")
+	difftest.AssertSame(t, ";wikilinks:alpha=/drafts/alpha=Renamed", wikiLinksStamp(post))
+}
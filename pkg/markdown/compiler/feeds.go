@@ -0,0 +1,132 @@
+package compiler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jschaf/jsc/pkg/dirs"
+	"github.com/jschaf/jsc/pkg/markdown"
+	"github.com/jschaf/jsc/pkg/markdown/catalog"
+	"github.com/jschaf/jsc/pkg/markdown/mdext"
+	"github.com/jschaf/jsc/render/feeds"
+)
+
+// feedFiles maps the file name of each feed format to the function that
+// renders it.
+var feedFiles = []struct {
+	name  string
+	build func(f *feeds.Feed, selfURL string) (string, error)
+}{
+	{"atom.xml", (*feeds.Feed).BuildAtom},
+	{"rss.xml", (*feeds.Feed).BuildRSS},
+	{"feed.json", (*feeds.Feed).BuildJSON},
+}
+
+// postURL returns the absolute URL of the detail page of the post.
+func (ic *IndexCompiler) postURL(ast *markdown.AST) string {
+	return ic.cfg.URL + "/" + ast.Meta.Slug
+}
+
+// writeFeeds writes the feeds of every published post to the dist dir and
+// the feeds of only TIL posts to the TIL dir. Returns the paths written,
+// relative to the dist dir.
+func (ic *IndexCompiler) writeFeeds(asts []*markdown.AST) ([]string, error) {
+	all := &feeds.Feed{
+		Title:       ic.cfg.Title,
+		Description: "Posts by " + ic.cfg.Author,
+		HomeURL:     ic.cfg.URL + "/",
+		Author:      ic.cfg.Author,
+	}
+	til := &feeds.Feed{
+		Title:       ic.cfg.Title + ": Today I Learned",
+		Description: "Short notes by " + ic.cfg.Author,
+		HomeURL:     ic.cfg.URL + "/" + dirs.TIL + "/",
+		Author:      ic.cfg.Author,
+	}
+	for _, ast := range asts {
+		if ast.Meta.Visibility != mdext.VisibilityPublished {
+			continue
+		}
+		entry, err := ic.feedEntry(ast)
+		if err != nil {
+			return nil, fmt.Errorf("build feed entry for %s: %w", ast.Path, err)
+		}
+		all.Add(entry)
+		if catalog.IsTIL(ast) {
+			til.Add(entry)
+		}
+	}
+
+	var outputs []string
+	for _, feed := range []struct {
+		dir  string
+		feed *feeds.Feed
+	}{{"", all}, {dirs.TIL, til}} {
+		for _, file := range feedFiles {
+			out := filepath.Join(feed.dir, file.name)
+			selfURL := ic.cfg.URL + "/" + filepath.ToSlash(out)
+			contents, err := file.build(feed.feed, selfURL)
+			if err != nil {
+				return nil, fmt.Errorf("build feed %s: %w", out, err)
+			}
+			dest := filepath.Join(ic.distDir, out)
+			if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+				return nil, fmt.Errorf("make dir for feed %s: %w", out, err)
+			}
+			if err := os.WriteFile(dest, []byte(contents), 0o644); err != nil {
+				return nil, fmt.Errorf("write feed %s: %w", out, err)
+			}
+			outputs = append(outputs, out)
+		}
+	}
+	return outputs, nil
+}
+
+// feedEntry creates the feed entry for a post with the full HTML of the post.
+func (ic *IndexCompiler) feedEntry(ast *markdown.AST) (feeds.Entry, error) {
+	b := &bytes.Buffer{}
+	if err := ic.renderFeedContent(b, ast); err != nil {
+		return feeds.Entry{}, err
+	}
+	url := ic.postURL(ast)
+	// Resolve against the directory of the post, since relative asset URLs
+	// are relative to the post dir.
+	content, err := feeds.AbsoluteURLs(b.String(), url+"/")
+	if err != nil {
+		return feeds.Entry{}, fmt.Errorf("make feed urls absolute: %w", err)
+	}
+	return feeds.Entry{
+		Title:       ast.Meta.Title,
+		URL:         url,
+		Published:   ast.Meta.Date,
+		Updated:     ast.LastModified(),
+		ContentHTML: content,
+		Tags:        ast.Meta.Tags,
+	}, nil
+}
+
+// renderFeedContent renders the full article without the header since feed
+// readers show the title and date from the entry.
+func (ic *IndexCompiler) renderFeedContent(w io.Writer, a *markdown.AST) error {
+	r := ic.feedMD.Renderer()
+	for n := a.Node.FirstChild(); n != nil; n = n.NextSibling() {
+		if n.Kind() != mdext.KindArticle {
+			if err := r.Render(w, a.Source, n); err != nil {
+				return fmt.Errorf("render feed node: %w", err)
+			}
+			continue
+		}
+		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+			if c.Kind() == mdext.KindHeader {
+				continue
+			}
+			if err := r.Render(w, a.Source, c); err != nil {
+				return fmt.Errorf("render feed article node: %w", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package compiler
+
+import (
+	"time"
+
+	"github.com/jschaf/jsc/pkg/config"
+	"github.com/jschaf/jsc/pkg/markdown"
+	"github.com/jschaf/jsc/pkg/markdown/html"
+)
+
+// modifiedTime returns the time the post last changed, or zero if the post
+// hasn't changed since it was published.
+func modifiedTime(ast *markdown.AST) time.Time {
+	if t := ast.LastModified(); t.After(ast.Meta.Date) {
+		return t
+	}
+	return time.Time{}
+}
+
+// updatedDate returns the date the post last changed, or zero if the post
+// only changed on the day it was published. Compares dates in UTC, like the
+// publish date shown on the post.
+func updatedDate(ast *markdown.AST) time.Time {
+	t := modifiedTime(ast)
+	if t.IsZero() || t.UTC().Format(time.DateOnly) == ast.Meta.Date.UTC().Format(time.DateOnly) {
+		return time.Time{}
+	}
+	return t
+}
+
+// revisionParams returns a revision for each commit that changed the post,
+// newest first. Returns nil if the revision history is disabled.
+func revisionParams(cfg *config.Config, ast *markdown.AST) []html.RevisionParams {
+	if !cfg.History.Show {
+		return nil
+	}
+	revs := make([]html.RevisionParams, 0, len(ast.History))
+	for _, c := range ast.History {
+		r := html.RevisionParams{Date: c.Time, Summary: c.Subject}
+		if cfg.History.CommitURL != "" {
+			r.URL = cfg.History.CommitURL + c.Hash
+		}
+		revs = append(revs, r)
+	}
+	return revs
+}
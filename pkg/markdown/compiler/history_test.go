@@ -0,0 +1,45 @@
+package compiler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jschaf/jsc/pkg/config"
+	"github.com/jschaf/jsc/pkg/git"
+	"github.com/jschaf/jsc/pkg/markdown"
+	"github.com/jschaf/jsc/pkg/markdown/html"
+	"github.com/jschaf/jsc/pkg/markdown/mdext"
+	"github.com/jschaf/jsc/pkg/testing/difftest"
+)
+
+func TestUpdatedDate(t *testing.T) {
+	published := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		history []git.Commit
+		want    time.Time
+	}{
+		{"no history", nil, time.Time{}},
+		{"before publish", []git.Commit{{Time: published.Add(-time.Hour)}}, time.Time{}},
+		{"publish day", []git.Commit{{Time: published.Add(time.Hour)}}, time.Time{}},
+		{"later", []git.Commit{{Time: published.AddDate(0, 1, 0)}}, published.AddDate(0, 1, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast := &markdown.AST{Meta: mdext.PostMeta{Date: published}, History: tt.history}
+			difftest.AssertSame(t, tt.want, updatedDate(ast))
+		})
+	}
+}
+
+func TestRevisionParams(t *testing.T) {
+	date := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	ast := &markdown.AST{History: []git.Commit{{Hash: "abc", Time: date, Subject: "Fix typo"}}}
+
+	cfg := &config.Config{}
+	difftest.AssertSame(t, []html.RevisionParams(nil), revisionParams(cfg, ast))
+
+	cfg.History = config.HistoryConfig{Show: true, CommitURL: "https://example.com/commit/"}
+	want := []html.RevisionParams{{Date: date, Summary: "Fix typo", URL: "https://example.com/commit/abc"}}
+	difftest.AssertSame(t, want, revisionParams(cfg, ast))
+}
@@ -6,80 +6,69 @@ import (
 	"github.com/jschaf/jsc/pkg/errs"
 	"github.com/jschaf/jsc/render/sitemaps"
 	"html/template"
-	"io/fs"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strings"
+	"time"
 
-	"github.com/jschaf/jsc/pkg/dirs"
+	"github.com/jschaf/jsc/pkg/config"
 	"github.com/jschaf/jsc/pkg/markdown"
+	"github.com/jschaf/jsc/pkg/markdown/catalog"
+	"github.com/jschaf/jsc/pkg/markdown/diag"
 	"github.com/jschaf/jsc/pkg/markdown/html"
 	"github.com/jschaf/jsc/pkg/markdown/mdctx"
 	"github.com/jschaf/jsc/pkg/markdown/mdext"
-	"github.com/jschaf/jsc/pkg/paths"
 )
 
-const rootURL = "https://joe.schafer.dev"
+// indexManifestKey is the manifest key for all outputs of the index compiler.
+// Every post is an input, so the outputs are rebuilt together.
+const indexManifestKey = "index"
 
 // IndexCompiler compiles the / path, the main homepage.
 type IndexCompiler struct {
-	md      *markdown.Markdown
+	md *markdown.Markdown
+	// feedMD renders the full post for feeds.
+	feedMD  *markdown.Markdown
+	cfg     *config.Config
 	distDir string
+	hasher  *fileHasher
 }
 
-func NewIndexCompiler(distDir string) *IndexCompiler {
+func NewIndexCompiler(cfg *config.Config, distDir string) *IndexCompiler {
 	md := markdown.New(markdown.WithExtender(mdext.NewContinueReadingExt()))
-	return &IndexCompiler{md: md, distDir: distDir}
-}
-
-func (ic *IndexCompiler) parseDirs(dirs ...string) ([]*markdown.AST, error) {
-	asts := make([]*markdown.AST, 0, len(dirs)*8)
-	for _, dir := range dirs {
-		as, err := ic.collectASTs(dir)
-		if err != nil {
-			return nil, fmt.Errorf("collectASTs for dir %s: %w", dir, err)
-		}
-		asts = append(asts, as...)
+	feedMD := markdown.New(markdown.WithExtender(mdext.NewNopContinueReadingExt()))
+	return &IndexCompiler{
+		md:      md,
+		feedMD:  feedMD,
+		cfg:     cfg,
+		distDir: distDir,
+		hasher:  newFileHasher(),
 	}
-	sort.Slice(asts, func(i, j int) bool {
-		return asts[i].Meta.Date.After(asts[j].Meta.Date)
-	})
-	return asts, nil
-}
-
-func (ic *IndexCompiler) collectASTs(dir string) ([]*markdown.AST, error) {
-	asts, err := paths.WalkCollect(dir, func(path string, dirent fs.DirEntry) ([]*markdown.AST, error) {
-		if !dirent.Type().IsRegular() || filepath.Ext(path) != ".md" {
-			return nil, nil
-		}
-		slog.Debug("compiling for index", "path", path)
-		bs, err := os.ReadFile(path)
-		if err != nil {
-			return nil, fmt.Errorf("read post at path %s: %w", path, err)
-		}
-		ast, err := ic.md.Parse(path, bytes.NewReader(bs))
-		if err != nil {
-			return nil, fmt.Errorf("parseFile markdown for root index: %w", err)
-		}
-		return []*markdown.AST{ast}, nil
-	})
-	return asts, err
 }
 
+// renderASTs renders the summary of each published post. Renders every post
+// even if some fail, returning a diag.List error with the diagnostics of each
+// failed post.
 func (ic *IndexCompiler) renderASTs(asts []*markdown.AST) ([]html.IndexPostParams, error) {
 	posts := make([]html.IndexPostParams, 0, len(asts))
+	diags := &diag.Collector{}
 	for _, ast := range asts {
 		if ast.Meta.Visibility != mdext.VisibilityPublished {
 			continue
 		}
 		b := new(bytes.Buffer)
-		if err := ic.md.Render(b, ast.Source, ast); err != nil {
-			return nil, fmt.Errorf("render markdown for index: %w", err)
+		if err := ic.renderSummary(b, ast); err != nil {
+			diags.AddFile(ast.Path, ast.Source, fmt.Errorf("render markdown for index: %w", err))
+			continue
 		}
 		titleHTML, err := ic.renderTitle(ast)
 		if err != nil {
-			return nil, fmt.Errorf("render index title: %w", err)
+			diags.AddFile(ast.Path, ast.Source, fmt.Errorf("render index title: %w", err))
+			continue
 		}
 		posts = append(posts, html.IndexPostParams{
 			Title:     ast.Meta.Title,
@@ -87,10 +76,39 @@ func (ic *IndexCompiler) renderASTs(asts []*markdown.AST) ([]html.IndexPostParam
 			Slug:      ast.Meta.Slug,
 			Date:      ast.Meta.Date,
 			Body:      template.HTML(b.String()),
+			Tags:      ast.Meta.Tags,
+			TIL:       catalog.IsTIL(ast),
+			Stats:     ast.Stats,
 		})
 	}
 	sort.Slice(posts, func(i, j int) bool { return posts[i].Date.After(posts[j].Date) })
-	return posts, nil
+	return posts, diags.Err()
+}
+
+// renderSummary renders the article up to and including the continue reading
+// node. Renders node by node instead of truncating the AST because the AST is
+// shared with the detail compiler.
+func (ic *IndexCompiler) renderSummary(w io.Writer, a *markdown.AST) error {
+	r := ic.md.Renderer()
+	for n := a.Node.FirstChild(); n != nil; n = n.NextSibling() {
+		if n.Kind() != mdext.KindArticle {
+			if err := r.Render(w, a.Source, n); err != nil {
+				return fmt.Errorf("render summary node: %w", err)
+			}
+			continue
+		}
+		_, _ = io.WriteString(w, "<article>\n")
+		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+			if err := r.Render(w, a.Source, c); err != nil {
+				return fmt.Errorf("render summary article node: %w", err)
+			}
+			if c.Kind() == mdext.KindContinueReading {
+				break
+			}
+		}
+		_, _ = io.WriteString(w, "\n</article>\n")
+	}
+	return nil
 }
 
 func (ic *IndexCompiler) renderTitle(ast *markdown.AST) (template.HTML, error) {
@@ -109,11 +127,47 @@ func (ic *IndexCompiler) renderTitle(ast *markdown.AST) (template.HTML, error) {
 	return template.HTML(b.String()), nil
 }
 
-func (ic *IndexCompiler) Compile() error {
-	asts, err := ic.parseDirs(dirs.Posts, dirs.TIL)
+// isFresh returns true if no source was added since the last build and the
+// manifest entry for the index is fresh.
+func (ic *IndexCompiler) isFresh(m *manifest, srcs []string, stamp string) bool {
+	known := m.inputs(indexManifestKey)
+	for _, src := range srcs {
+		if !slices.Contains(known, src) {
+			return false
+		}
+	}
+	return m.isFresh(indexManifestKey, stamp, ic.hasher)
+}
+
+// publishedStamp returns the slugs and latest commits of the published posts
+// as a manifest stamp so that the index is rebuilt when a scheduled post goes
+// live or when a post is committed, which changes its last modified time.
+func publishedStamp(asts []*markdown.AST) string {
+	slugs := make([]string, 0, len(asts))
+	for _, ast := range asts {
+		if ast.Meta.Visibility == mdext.VisibilityPublished {
+			slugs = append(slugs, ast.Meta.Slug+"@"+ast.Revision())
+		}
+	}
+	sort.Strings(slugs)
+	return strings.Join(slugs, ",")
+}
+
+// Compile compiles the home page, the TIL page, the archive pages, the tag
+// pages, the series pages, the feeds, the search index and the sitemap from
+// every post in the catalog.
+func (ic *IndexCompiler) Compile(cat *catalog.Catalog) (mErr error) {
+	m, err := loadManifest(ic.distDir, "index", ic.cfg.Fingerprint())
 	if err != nil {
-		return err
+		return fmt.Errorf("load index manifest: %w", err)
 	}
+	srcs := cat.Paths()
+	stamp := publishedStamp(cat.Posts)
+	if ic.isFresh(m, srcs, stamp) {
+		slog.Debug("skip unchanged index")
+		return nil
+	}
+	asts := cat.Posts
 
 	featureSet := mdctx.NewFeatureSet()
 	for _, ast := range asts {
@@ -126,34 +180,60 @@ func (ic *IndexCompiler) Compile() error {
 		return fmt.Errorf("compileAST asts for index: %w", err)
 	}
 
-	if err := os.MkdirAll(ic.distDir, 0o755); err != nil {
-		return fmt.Errorf("make dir for index: %w", err)
+	var listingOutputs []string
+	for _, l := range ic.homeListings(posts) {
+		outs, err := ic.writeListing(featureSet, l)
+		if err != nil {
+			return fmt.Errorf("write listing %s: %w", listingPagePath(l.dir, 1), err)
+		}
+		listingOutputs = append(listingOutputs, outs...)
 	}
-	dest := filepath.Join(ic.distDir, "index.html")
 
-	destFile, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	tags := groupTags(posts)
+	tagOutputs, err := ic.writeTagPages(featureSet, tags)
 	if err != nil {
-		return fmt.Errorf("open index.html file for write: %w", err)
-	}
-	data := html.IndexParams{
-		Title:    "Joe Schafer's Blog",
-		Posts:    posts,
-		Features: featureSet,
+		return fmt.Errorf("write tag pages: %w", err)
 	}
-	if err := html.RenderIndex(destFile, data); err != nil {
-		return fmt.Errorf("execute index template: %w", err)
+
+	series := groupSeries(cat.Series(), posts)
+	seriesOutputs, err := ic.writeSeriesPages(featureSet, series)
+	if err != nil {
+		return fmt.Errorf("write series pages: %w", err)
 	}
 
-	err = writeSitemap(ic.distDir, asts)
+	err = ic.writeSitemap(asts, tags, series)
 	if err != nil {
 		return fmt.Errorf("write sitemap: %w", err)
 	}
 
+	feedOutputs, err := ic.writeFeeds(asts)
+	if err != nil {
+		return fmt.Errorf("write feeds: %w", err)
+	}
+
+	if err := ic.writeSearchIndex(asts); err != nil {
+		return fmt.Errorf("write search index: %w", err)
+	}
+
+	inputs := append(srcs, html.IndexTemplatePaths()...)
+	for _, ast := range asts {
+		inputs = append(inputs, ast.Deps...)
+	}
+	outputs := append([]string{"sitemap.xml", searchIndexFile}, listingOutputs...)
+	outputs = append(outputs, tagOutputs...)
+	outputs = append(outputs, seriesOutputs...)
+	outputs = append(outputs, feedOutputs...)
+	if err := m.record(indexManifestKey, stamp, inputs, outputs, ic.hasher); err != nil {
+		return fmt.Errorf("record index manifest: %w", err)
+	}
+	if err := m.save(cat.IsComplete()); err != nil {
+		return fmt.Errorf("save index manifest: %w", err)
+	}
 	return nil
 }
 
-func writeSitemap(distDir string, ast []*markdown.AST) (mErr error) {
-	destFile, err := os.OpenFile(filepath.Join(distDir, "sitemap.xml"), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+func (ic *IndexCompiler) writeSitemap(ast []*markdown.AST, tags []tagPage, series []seriesPage) (mErr error) {
+	destFile, err := os.OpenFile(filepath.Join(ic.distDir, "sitemap.xml"), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
 	if err != nil {
 		return fmt.Errorf("open sitemap.xml file for write: %w", err)
 	}
@@ -165,12 +245,39 @@ func writeSitemap(distDir string, ast []*markdown.AST) (mErr error) {
 			continue
 		}
 		url := sitemaps.URL{
-			Loc:        rootURL + "/" + a.Meta.Slug,
-			LastMod:    a.Meta.Date,
+			Loc:        ic.postURL(a),
+			LastMod:    a.LastModified(),
 			ChangeFreq: "monthly",
 		}
 		sitemap.Add(url)
 	}
+	var tagsLastMod time.Time
+	for _, t := range tags {
+		// Posts are newest first, so a tag page last changed with its first post.
+		lastMod := t.posts[0].Date
+		if lastMod.After(tagsLastMod) {
+			tagsLastMod = lastMod
+		}
+		sitemap.Add(sitemaps.URL{
+			Loc:        ic.cfg.URL + strings.TrimSuffix(tagPath(t.tag), "/"),
+			LastMod:    lastMod,
+			ChangeFreq: "monthly",
+		})
+	}
+	if len(tags) > 0 {
+		sitemap.Add(sitemaps.URL{
+			Loc:        ic.cfg.URL + "/" + tagsDir,
+			LastMod:    tagsLastMod,
+			ChangeFreq: "monthly",
+		})
+	}
+	for _, s := range series {
+		sitemap.Add(sitemaps.URL{
+			Loc:        ic.cfg.URL + strings.TrimSuffix(seriesPath(s.name), "/"),
+			LastMod:    s.lastModified(),
+			ChangeFreq: "monthly",
+		})
+	}
 	sm, err := sitemap.Build()
 	if err != nil {
 		return fmt.Errorf("build sitemap: %w", err)
@@ -0,0 +1,177 @@
+package compiler
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/jschaf/jsc/pkg/dirs"
+	"github.com/jschaf/jsc/pkg/errs"
+	"github.com/jschaf/jsc/pkg/markdown/html"
+	"github.com/jschaf/jsc/pkg/markdown/mdctx"
+)
+
+const (
+	// pageDir is the dir under a listing that holds every page after the
+	// first, like /page/2/.
+	pageDir = "page"
+	// archiveDir is the dist dir that holds the archive page of each year.
+	archiveDir = "archive"
+)
+
+// listing is a list of posts paginated under a dir, like the home page or
+// the /til/ page.
+type listing struct {
+	// dir is the dist dir of the first page, relative to the dist dir. Empty
+	// for the home page.
+	dir     string
+	title   string
+	heading string
+	// posts are sorted newest first.
+	posts []html.IndexPostParams
+	// archives are shown on the first page.
+	archives []html.ArchiveParams
+}
+
+// listingPagePath returns the URL path of the 1-based page of the listing in
+// dir.
+func listingPagePath(dir string, page int) string {
+	p := "/"
+	if dir != "" {
+		p += filepath.ToSlash(dir) + "/"
+	}
+	if page > 1 {
+		p += pageDir + "/" + strconv.Itoa(page) + "/"
+	}
+	return p
+}
+
+// listingPageDest returns the path of the 1-based page of the listing in dir,
+// relative to the dist dir.
+func listingPageDest(dir string, page int) string {
+	if page > 1 {
+		dir = filepath.Join(dir, pageDir, strconv.Itoa(page))
+	}
+	return filepath.Join(dir, "index.html")
+}
+
+// writeListing writes each page of the listing with at most the configured
+// page size of posts. Always writes the first page, even without posts.
+// Returns the paths written, relative to the dist dir.
+func (ic *IndexCompiler) writeListing(feats *mdctx.FeatureSet, l listing) ([]string, error) {
+	pages := paginate(l.posts, ic.cfg.PageSize)
+	outputs := make([]string, 0, len(pages))
+	for i, posts := range pages {
+		page := i + 1
+		data := html.IndexParams{
+			Title:    l.title,
+			Heading:  l.heading,
+			Posts:    posts,
+			Features: feats,
+		}
+		if len(pages) > 1 {
+			data.Pagination = &html.PaginationParams{Page: page, NumPages: len(pages)}
+			if page > 1 {
+				data.Pagination.PrevPath = listingPagePath(l.dir, page-1)
+			}
+			if page < len(pages) {
+				data.Pagination.NextPath = listingPagePath(l.dir, page+1)
+			}
+		}
+		if page == 1 {
+			data.Archives = l.archives
+		}
+		out := listingPageDest(l.dir, page)
+		if err := ic.writeIndexPage(out, data); err != nil {
+			return nil, fmt.Errorf("write page %d: %w", page, err)
+		}
+		outputs = append(outputs, out)
+	}
+	return outputs, nil
+}
+
+// paginate splits posts into pages of at most size posts. Returns a single
+// empty page if there are no posts. A size of zero means one page.
+func paginate(posts []html.IndexPostParams, size int) [][]html.IndexPostParams {
+	if len(posts) == 0 {
+		return [][]html.IndexPostParams{nil}
+	}
+	if size <= 0 {
+		size = len(posts)
+	}
+	pages := make([][]html.IndexPostParams, 0, (len(posts)+size-1)/size)
+	for start := 0; start < len(posts); start += size {
+		pages = append(pages, posts[start:min(start+size, len(posts))])
+	}
+	return pages
+}
+
+// homeListings returns the listings of the home page, the TIL page, and the
+// archive page of each year. The home page lists every post, including TIL
+// posts, while the TIL page lists only TIL posts.
+func (ic *IndexCompiler) homeListings(posts []html.IndexPostParams) []listing {
+	var tils []html.IndexPostParams
+	byYear := make(map[int][]html.IndexPostParams)
+	for _, p := range posts {
+		if p.TIL {
+			tils = append(tils, p)
+		}
+		year := p.Date.Year()
+		byYear[year] = append(byYear[year], p)
+	}
+
+	years := make([]int, 0, len(byYear))
+	for year := range byYear {
+		years = append(years, year)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(years)))
+	archives := make([]html.ArchiveParams, len(years))
+	for i, year := range years {
+		archives[i] = html.ArchiveParams{Year: year, Path: archivePath(year)}
+	}
+
+	listings := []listing{
+		{title: ic.cfg.Title, posts: posts, archives: archives},
+		{dir: dirs.TIL, title: "Today I Learned", heading: "Today I Learned", posts: tils},
+	}
+	for _, year := range years {
+		heading := "Posts from " + strconv.Itoa(year)
+		listings = append(listings, listing{
+			dir:     filepath.Join(archiveDir, strconv.Itoa(year)),
+			title:   heading,
+			heading: heading,
+			posts:   byYear[year],
+		})
+	}
+	return listings
+}
+
+// archivePath returns the URL path of the archive page for the year.
+func archivePath(year int) string {
+	return "/" + archiveDir + "/" + strconv.Itoa(year) + "/"
+}
+
+// writeIndexPage renders the index template into the file at out, relative
+// to the dist dir.
+func (ic *IndexCompiler) writeIndexPage(out string, data html.IndexParams) (mErr error) {
+	data.Site = ic.cfg
+	data.Meta = html.PageMeta{
+		URL:         pageURL(ic.cfg, out),
+		Description: "Posts by " + ic.cfg.Author,
+	}
+	dest := filepath.Join(ic.distDir, out)
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return fmt.Errorf("make dir for index page: %w", err)
+	}
+	f, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("open index page for write: %w", err)
+	}
+	defer errs.Capture(&mErr, f.Close, "close index page")
+	if err := html.RenderIndex(f, data); err != nil {
+		return fmt.Errorf("render index page: %w", err)
+	}
+	return nil
+}
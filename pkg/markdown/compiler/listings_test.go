@@ -0,0 +1,96 @@
+package compiler
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jschaf/jsc/pkg/config"
+	"github.com/jschaf/jsc/pkg/markdown/html"
+	"github.com/jschaf/jsc/pkg/markdown/mdctx"
+	"github.com/jschaf/jsc/pkg/testing/difftest"
+	"github.com/jschaf/jsc/pkg/testing/require"
+)
+
+func TestWriteListing(t *testing.T) {
+	distDir := t.TempDir()
+	var posts []html.IndexPostParams
+	for i := 5; i > 0; i-- {
+		posts = append(posts, html.IndexPostParams{
+			Slug: "post-" + strconv.Itoa(i),
+			Date: time.Date(2020, time.January, i, 0, 0, 0, 0, time.UTC),
+		})
+	}
+	l := listing{dir: "til", title: "TIL", posts: posts}
+	cfg := &config.Config{Title: "Blog", Author: "Author", URL: "https://example.com", PageSize: 2}
+	ic := NewIndexCompiler(cfg, distDir)
+
+	outs, err := ic.writeListing(mdctx.NewFeatureSet(), l)
+	require.NoError(t, err)
+
+	difftest.AssertSame(t, []string{
+		"til/index.html",
+		"til/page/2/index.html",
+		"til/page/3/index.html",
+	}, outs)
+	page2, err := os.ReadFile(filepath.Join(distDir, "til/page/2/index.html"))
+	require.NoError(t, err)
+	for _, want := range []string{
+		`<a href="/til/" rel="prev">`,
+		`<a href="/til/page/3/" rel="next">`,
+		`<a href="/post-3"`,
+		`<a href="/post-2"`,
+		`Page 2 of 3`,
+	} {
+		if !strings.Contains(string(page2), want) {
+			t.Errorf("page 2 doesn't include %q:\n\n%s", want, page2)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	posts := make([]html.IndexPostParams, 5)
+	tests := []struct {
+		name  string
+		posts []html.IndexPostParams
+		size  int
+		want  []int
+	}{
+		{"empty", nil, 2, []int{0}},
+		{"exact", posts[:4], 2, []int{2, 2}},
+		{"remainder", posts, 2, []int{2, 2, 1}},
+		{"no limit", posts, 0, []int{5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, page := range paginate(tt.posts, tt.size) {
+				got = append(got, len(page))
+			}
+			difftest.AssertSame(t, tt.want, got)
+		})
+	}
+}
+
+func TestHomeListings(t *testing.T) {
+	posts := []html.IndexPostParams{
+		{Slug: "til-post", TIL: true, Date: time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)},
+		{Slug: "article", Date: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	ic := NewIndexCompiler(&config.Config{Title: "Blog"}, t.TempDir())
+	got := make(map[string][]string)
+	for _, l := range ic.homeListings(posts) {
+		for _, p := range l.posts {
+			got[l.dir] = append(got[l.dir], p.Slug)
+		}
+	}
+	difftest.AssertSame(t, map[string][]string{
+		"":                   {"til-post", "article"},
+		"til":                {"til-post"},
+		archiveDir + "/2021": {"til-post"},
+		archiveDir + "/2020": {"article"},
+	}, got)
+}
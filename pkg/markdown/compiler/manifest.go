@@ -0,0 +1,241 @@
+package compiler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/jschaf/jsc/pkg/dirs"
+	"github.com/jschaf/jsc/pkg/files"
+)
+
+// compilerVersion identifies the running binary. A manifest written by a
+// different binary is stale since the compiler code itself may have changed.
+var compilerVersion = sync.OnceValue(func() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	h, err := files.HashContentsFnv64(exe)
+	if err != nil {
+		return ""
+	}
+	return strconv.FormatUint(h, 16)
+})
+
+// manifest records the inputs and outputs of each build entry, like a single
+// post, so that a rebuild only recompiles entries whose inputs changed and
+// deletes outputs that are no longer produced.
+type manifest struct {
+	// Version is the compiler version that wrote the manifest.
+	Version string `json:"version"`
+	// Entries is keyed by the source that produces the entry, like the path to
+	// a Markdown file.
+	Entries map[string]manifestEntry `json:"entries"`
+
+	path    string
+	distDir string
+	// seen tracks which entries were visited in this build. Unseen entries
+	// are pruned on save.
+	seen map[string]struct{}
+	mu   sync.Mutex
+}
+
+type manifestEntry struct {
+	// Inputs maps the full path of each input file to the hash of its
+	// contents. An empty hash means the file didn't exist.
+	Inputs map[string]string `json:"inputs"`
+	// Outputs are the files written by the entry, relative to the dist dir.
+	Outputs []string `json:"outputs"`
+	// Stamp summarizes inputs that aren't files, like whether a scheduled
+	// post is live yet. The entry is stale if the stamp changes.
+	Stamp string `json:"stamp,omitempty"`
+}
+
+// loadManifest reads the manifest with the name from the dist dir. Returns an
+// empty manifest if none exists or if it was written by another compiler or
+// with another config, identified by configVersion.
+func loadManifest(distDir, name, configVersion string) (*manifest, error) {
+	version := compilerVersion()
+	if version != "" && configVersion != "" {
+		version += "-" + configVersion
+	}
+	m := &manifest{
+		Version: version,
+		Entries: make(map[string]manifestEntry),
+		path:    filepath.Join(distDir, dirs.Manifest, name+".json"),
+		distDir: distDir,
+		seen:    make(map[string]struct{}),
+	}
+	bs, err := os.ReadFile(m.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read manifest %s: %w", name, err)
+	}
+	old := &manifest{}
+	if err := json.Unmarshal(bs, old); err != nil {
+		slog.Warn("ignore invalid manifest", "path", m.path, "error", err)
+		return m, nil
+	}
+	if old.Version != m.Version || m.Version == "" {
+		slog.Debug("ignore manifest from different compiler or config", "path", m.path)
+		// Keep the entries so we can delete stale outputs, but don't trust the
+		// input hashes.
+		for key, e := range old.Entries {
+			m.Entries[key] = manifestEntry{Outputs: e.Outputs}
+		}
+		return m, nil
+	}
+	m.Entries = old.Entries
+	return m, nil
+}
+
+// isFresh returns true if the entry for key exists with the same stamp, every
+// input has the same hash as the last build, and every output still exists.
+// Marks the key as seen.
+func (m *manifest) isFresh(key, stamp string, h *fileHasher) bool {
+	m.mu.Lock()
+	m.seen[key] = struct{}{}
+	e, ok := m.Entries[key]
+	m.mu.Unlock()
+	if !ok || len(e.Inputs) == 0 || e.Stamp != stamp {
+		return false
+	}
+	for path, want := range e.Inputs {
+		if got, err := h.hash(path); err != nil || got != want {
+			return false
+		}
+	}
+	for _, out := range e.Outputs {
+		if _, err := os.Stat(filepath.Join(m.distDir, out)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// inputs returns the input paths of the entry for key from the last build.
+func (m *manifest) inputs(key string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.Entries[key]
+	paths := make([]string, 0, len(e.Inputs))
+	for path := range e.Inputs {
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// record hashes the inputs and stores a new entry for key, deleting any
+// outputs from the previous entry that aren't in outputs.
+func (m *manifest) record(key, stamp string, inputs []string, outputs []string, h *fileHasher) error {
+	e := manifestEntry{
+		Inputs:  make(map[string]string, len(inputs)),
+		Outputs: outputs,
+		Stamp:   stamp,
+	}
+	for _, path := range inputs {
+		sum, err := h.hash(path)
+		if err != nil {
+			return fmt.Errorf("hash manifest input: %w", err)
+		}
+		e.Inputs[path] = sum
+	}
+
+	m.mu.Lock()
+	old := m.Entries[key]
+	m.Entries[key] = e
+	m.seen[key] = struct{}{}
+	m.mu.Unlock()
+
+	var stale []string
+	for _, out := range old.Outputs {
+		if !slices.Contains(outputs, out) {
+			stale = append(stale, out)
+		}
+	}
+	return m.removeOutputs(stale)
+}
+
+// save writes the manifest to the dist dir. If prune is true, removes entries
+// not seen during this build and deletes their outputs. Only prune if the
+// build visited every source.
+func (m *manifest) save(prune bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, e := range m.Entries {
+		if _, ok := m.seen[key]; ok || !prune {
+			continue
+		}
+		slog.Debug("remove stale outputs", "source", key, "outputs", e.Outputs)
+		if err := m.removeOutputs(e.Outputs); err != nil {
+			return err
+		}
+		delete(m.Entries, key)
+	}
+
+	bs, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal manifest: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0o755); err != nil {
+		return fmt.Errorf("make manifest dir: %w", err)
+	}
+	if err := os.WriteFile(m.path, bs, 0o644); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	return nil
+}
+
+// removeOutputs deletes outputs relative to the dist dir and any parent dirs
+// left empty.
+func (m *manifest) removeOutputs(outputs []string) error {
+	for _, out := range outputs {
+		path := filepath.Join(m.distDir, out)
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove stale output: %w", err)
+		}
+		for dir := filepath.Dir(path); dir != m.distDir && len(dir) > len(m.distDir); dir = filepath.Dir(dir) {
+			if err := os.Remove(dir); err != nil {
+				break // not empty or already gone
+			}
+		}
+	}
+	return nil
+}
+
+// fileHasher hashes file contents, caching the result so that shared inputs,
+// like templates, are only read once per build.
+type fileHasher struct {
+	sums sync.Map // map[string]string
+}
+
+func newFileHasher() *fileHasher {
+	return &fileHasher{}
+}
+
+// hash returns the hex-encoded FNV-64a hash of the file contents at path, or
+// the empty string if the file doesn't exist.
+func (h *fileHasher) hash(path string) (string, error) {
+	if sum, ok := h.sums.Load(path); ok {
+		return sum.(string), nil
+	}
+	n, err := files.HashContentsFnv64(path)
+	sum := ""
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return "", err
+	default:
+		sum = strconv.FormatUint(n, 16)
+	}
+	h.sums.Store(path, sum)
+	return sum, nil
+}
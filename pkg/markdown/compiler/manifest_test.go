@@ -0,0 +1,74 @@
+package compiler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jschaf/jsc/pkg/testing/require"
+)
+
+func TestManifest_IsFresh(t *testing.T) {
+	distDir := t.TempDir()
+	src := filepath.Join(t.TempDir(), "post.md")
+	writeFile(t, src, "alpha")
+	writeFile(t, filepath.Join(distDir, "post", "index.html"), "<p>alpha</p>")
+
+	m, err := loadManifest(distDir, "test", "")
+	require.NoError(t, err)
+	if m.isFresh(src, "", newFileHasher()) {
+		t.Fatal("isFresh for empty manifest; want false")
+	}
+	require.NoError(t, m.record(src, "", []string{src}, []string{"post/index.html"}, newFileHasher()))
+	require.NoError(t, m.save(true))
+
+	m, err = loadManifest(distDir, "test", "")
+	require.NoError(t, err)
+	if !m.isFresh(src, "", newFileHasher()) {
+		t.Error("isFresh for unchanged input; want true")
+	}
+
+	if m.isFresh(src, "draft", newFileHasher()) {
+		t.Error("isFresh for changed stamp; want false")
+	}
+
+	writeFile(t, src, "bravo")
+	if m.isFresh(src, "", newFileHasher()) {
+		t.Error("isFresh for changed input; want false")
+	}
+}
+
+func TestManifest_RemovesStaleOutputs(t *testing.T) {
+	distDir := t.TempDir()
+	src := filepath.Join(t.TempDir(), "post.md")
+	writeFile(t, src, "alpha")
+	oldOut := filepath.Join(distDir, "old-slug", "index.html")
+	newOut := filepath.Join(distDir, "new-slug", "index.html")
+	writeFile(t, oldOut, "old")
+
+	m, err := loadManifest(distDir, "test", "")
+	require.NoError(t, err)
+	require.NoError(t, m.record(src, "", []string{src}, []string{"old-slug/index.html"}, newFileHasher()))
+
+	// Simulate renaming the slug.
+	writeFile(t, newOut, "new")
+	require.NoError(t, m.record(src, "", []string{src}, []string{"new-slug/index.html"}, newFileHasher()))
+	if _, err := os.Stat(filepath.Dir(oldOut)); !os.IsNotExist(err) {
+		t.Errorf("want old output dir removed; got stat err %v", err)
+	}
+	require.NoError(t, m.save(true))
+
+	// Simulate deleting the source.
+	m, err = loadManifest(distDir, "test", "")
+	require.NoError(t, err)
+	require.NoError(t, m.save(true))
+	if _, err := os.Stat(newOut); !os.IsNotExist(err) {
+		t.Errorf("want output of deleted source removed; got stat err %v", err)
+	}
+}
+
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+}
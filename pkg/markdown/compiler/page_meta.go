@@ -0,0 +1,166 @@
+package compiler
+
+import (
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jschaf/bibtex"
+	"github.com/jschaf/jsc/pkg/config"
+	"github.com/jschaf/jsc/pkg/markdown"
+	"github.com/jschaf/jsc/pkg/markdown/assets"
+	"github.com/jschaf/jsc/pkg/markdown/asts"
+	"github.com/jschaf/jsc/pkg/markdown/catalog"
+	"github.com/jschaf/jsc/pkg/markdown/html"
+	"github.com/jschaf/jsc/pkg/markdown/mdext"
+	"github.com/jschaf/jsc/render/cards"
+	"github.com/yuin/goldmark/ast"
+)
+
+// maxDescriptionLen is the max number of runes in a description derived from
+// the first paragraph of a post. Search engines truncate longer descriptions.
+const maxDescriptionLen = 160
+
+// cardName is the file name of the generated social preview image in the
+// dir of each post.
+const cardName = "card.png"
+
+// cardBlob returns the asset that generates the social preview image of the
+// post. Returns false if the post already has an asset with the same name.
+func cardBlob(cfg *config.Config, p *markdown.AST) (assets.Blob, bool) {
+	dest := path.Join(p.Meta.Path, cardName)
+	for _, a := range p.Assets {
+		if path.Clean(a.Dest) == dest {
+			return assets.Blob{}, false
+		}
+	}
+	card := cards.Card{Title: p.Meta.Title, Date: p.Meta.Date, SiteName: cfg.Title}
+	return assets.Blob{
+		Dest:    dest,
+		GenFunc: func(w io.Writer) error { return cards.Render(w, card) },
+	}, true
+}
+
+// pageURL returns the absolute canonical URL of the page written to out,
+// relative to the dist dir, like "foo/index.html". Follows the trailing slash
+// behavior of Firebase so the URL doesn't redirect.
+func pageURL(cfg *config.Config, out string) string {
+	dir := path.Dir(filepath.ToSlash(out))
+	if dir == "." {
+		return cfg.URL + "/"
+	}
+	if cfg.Firebase.TrailingSlash == "ADD" {
+		dir += "/"
+	}
+	return cfg.URL + "/" + dir
+}
+
+// absURL returns the absolute URL of the URL path p on the site. Returns p if
+// it's already absolute or empty.
+func absURL(cfg *config.Config, p string) string {
+	if !strings.HasPrefix(p, "/") {
+		return p
+	}
+	return cfg.URL + p
+}
+
+// detailPageMeta returns the link preview metadata of the detail page of a
+// post.
+func detailPageMeta(cfg *config.Config, ast *markdown.AST) html.PageMeta {
+	img := postImage(ast)
+	if img == "" {
+		img = path.Join(ast.Meta.Path, cardName)
+	}
+	return html.PageMeta{
+		URL:         pageURL(cfg, catalog.DetailDest(ast)),
+		Description: postDescription(ast),
+		ImageURL:    absURL(cfg, img),
+		Article: &html.ArticleMeta{
+			Published: ast.Meta.Date,
+			Modified:  modifiedTime(ast),
+			Tags:      ast.Meta.Tags,
+			Citations: citationTitles(ast),
+		},
+	}
+}
+
+// postDescription returns the description from the front matter or the
+// truncated text of the first paragraph.
+func postDescription(p *markdown.AST) string {
+	if p.Meta.Description != "" {
+		return p.Meta.Description
+	}
+	desc := ""
+	_ = ast.Walk(p.Node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n.Kind() {
+		case mdext.KindHeader, mdext.KindTOC, mdext.KindFigure, mdext.KindColonBlock,
+			mdext.KindFootnoteBody, mdext.KindCitationReferences:
+			return ast.WalkSkipChildren, nil
+		case ast.KindParagraph:
+			desc = asts.PlainText(n, p.Source)
+			if desc != "" {
+				return ast.WalkStop, nil
+			}
+			return ast.WalkSkipChildren, nil
+		default:
+			return ast.WalkContinue, nil
+		}
+	})
+	return truncateWords(desc, maxDescriptionLen)
+}
+
+// truncateWords truncates s to at most n runes, ending with a complete word
+// followed by an ellipsis.
+func truncateWords(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)[:n-1]
+	cut := string(runes)
+	if i := strings.LastIndexByte(cut, ' '); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
+
+// postImage returns the URL path or URL of the image from the front matter
+// or of the first figure. Returns empty if the post has no image, in which
+// case the generated card is the image.
+func postImage(p *markdown.AST) string {
+	if p.Meta.Image != "" {
+		return p.Meta.Image
+	}
+	img := ""
+	_ = ast.Walk(p.Node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if fig, ok := n.(*mdext.Figure); ok && entering {
+			img = string(fig.Destination)
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return img
+}
+
+// citationTitles returns the title of each work cited by the post in order of
+// first appearance.
+func citationTitles(p *markdown.AST) []string {
+	var titles []string
+	seen := make(map[bibtex.CiteKey]bool)
+	_ = ast.Walk(p.Node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		c, ok := n.(*mdext.Citation)
+		if !ok || !entering || seen[c.Key] {
+			return ast.WalkContinue, nil
+		}
+		seen[c.Key] = true
+		if t := c.Title(); t != "" {
+			titles = append(titles, t)
+		}
+		return ast.WalkContinue, nil
+	})
+	return titles
+}
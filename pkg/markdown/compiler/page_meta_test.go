@@ -0,0 +1,86 @@
+package compiler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jschaf/jsc/pkg/config"
+	"github.com/jschaf/jsc/pkg/markdown"
+	"github.com/jschaf/jsc/pkg/testing/difftest"
+	"github.com/jschaf/jsc/pkg/testing/require"
+	"github.com/jschaf/jsc/pkg/texts"
+)
+
+func TestDetailPageMeta(t *testing.T) {
+	cfg := &config.Config{Author: "Author", URL: "https://example.com"}
+	tests := []struct {
+		name     string
+		src      string
+		wantDesc string
+		wantImg  string
+	}{
+		{
+			"first paragraph and figure",
+			texts.Dedent(`
+				+++
+				slug = "foo"
+				date = 2020-01-02
+				+++
+				# Title
+
+				![alt](/img/first.png "title")
+
+				First *paragraph*
+				continues.
+
+				![alt](/img/second.png "title")
+			`),
+			"First paragraph continues.",
+			"https://example.com/img/first.png",
+		},
+		{
+			"front matter",
+			texts.Dedent(`
+				+++
+				slug = "foo"
+				date = 2020-01-02
+				description = "Summary."
+				image = "https://cdn.example.com/cover.png"
+				+++
+				# Title
+
+				First paragraph.
+			`),
+			"Summary.",
+			"https://cdn.example.com/cover.png",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, err := markdown.New().Parse("post.md", strings.NewReader(tt.src))
+			require.NoError(t, err)
+			got := detailPageMeta(cfg, ast)
+			difftest.AssertSame(t, "https://example.com/foo", got.URL)
+			difftest.AssertSame(t, tt.wantDesc, got.Description)
+			difftest.AssertSame(t, tt.wantImg, got.ImageURL)
+		})
+	}
+}
+
+func TestTruncateWords(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"one two three", 10, "one two…"},
+		{"one, two three", 8, "one…"},
+		{"onetwothree", 5, "onet…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			difftest.AssertSame(t, tt.want, truncateWords(tt.s, tt.n))
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package compiler
+
+import (
+	"strings"
+
+	"github.com/jschaf/jsc/pkg/markdown"
+	"github.com/jschaf/jsc/pkg/markdown/catalog"
+	"github.com/jschaf/jsc/pkg/markdown/html"
+)
+
+// postLink returns a link to the post.
+func postLink(ast *markdown.AST) html.PostLinkParams {
+	return html.PostLinkParams{Title: ast.Meta.Title, Path: catalog.URLPath(ast)}
+}
+
+// relatedParams returns a link to each related post of each post.
+func relatedParams(related map[*markdown.AST][]catalog.Relation) map[*markdown.AST][]html.PostLinkParams {
+	params := make(map[*markdown.AST][]html.PostLinkParams, len(related))
+	for ast, rels := range related {
+		ps := make([]html.PostLinkParams, len(rels))
+		for i, r := range rels {
+			ps[i] = postLink(r.Post)
+		}
+		params[ast] = ps
+	}
+	return params
+}
+
+// backlinkParams returns a link to each post that links to each post.
+func backlinkParams(backlinks map[*markdown.AST][]*markdown.AST) map[*markdown.AST][]html.PostLinkParams {
+	params := make(map[*markdown.AST][]html.PostLinkParams, len(backlinks))
+	for ast, srcs := range backlinks {
+		ps := make([]html.PostLinkParams, len(srcs))
+		for i, src := range srcs {
+			ps[i] = postLink(src)
+		}
+		params[ast] = ps
+	}
+	return params
+}
+
+// postLinksStamp returns the links as a manifest stamp so that a post is
+// rebuilt when the linked posts change.
+func postLinksStamp(name string, links []html.PostLinkParams) string {
+	if len(links) == 0 {
+		return ""
+	}
+	parts := make([]string, len(links))
+	for i, l := range links {
+		parts[i] = l.Path + "=" + l.Title
+	}
+	return ";" + name + ":" + strings.Join(parts, ",")
+}
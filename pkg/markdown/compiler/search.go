@@ -0,0 +1,92 @@
+package compiler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	qjskatex "github.com/graemephi/goldmark-qjs-katex"
+	"github.com/jschaf/jsc/pkg/markdown"
+	"github.com/jschaf/jsc/pkg/markdown/asts"
+	"github.com/jschaf/jsc/pkg/markdown/mdext"
+	"github.com/jschaf/jsc/render/search"
+	"github.com/yuin/goldmark/ast"
+)
+
+// searchIndexFile is the file name of the search index in the dist dir.
+const searchIndexFile = "search.json"
+
+// writeSearchIndex writes the search index of every published post to the
+// dist dir.
+func (ic *IndexCompiler) writeSearchIndex(asts []*markdown.AST) error {
+	idx := search.NewIndex()
+	for _, ast := range asts {
+		if ast.Meta.Visibility != mdext.VisibilityPublished {
+			continue
+		}
+		idx.Add(searchDoc(ast))
+	}
+	bs, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("encode search index: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(ic.distDir, searchIndexFile), bs, 0o644); err != nil {
+		return fmt.Errorf("write search index file: %w", err)
+	}
+	return nil
+}
+
+// searchDoc returns the searchable text of a post split into sections at each
+// heading. Skips code, math and the footnotes since they make poor matches
+// and snippets.
+func searchDoc(p *markdown.AST) search.Doc {
+	sections := []search.Section{{}}
+	sb := &strings.Builder{}
+	flush := func() {
+		sections[len(sections)-1].Text = strings.Join(strings.Fields(sb.String()), " ")
+		sb.Reset()
+	}
+	_ = ast.Walk(p.Node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			// Separate the text of adjacent blocks, like list items.
+			if n.Type() == ast.TypeBlock {
+				sb.WriteByte(' ')
+			}
+			return ast.WalkContinue, nil
+		}
+		switch n.Kind() {
+		case mdext.KindHeader, mdext.KindTOC, mdext.KindFootnoteBody, mdext.KindCitationReferences,
+			ast.KindCodeBlock, ast.KindFencedCodeBlock, ast.KindCodeSpan, ast.KindHTMLBlock,
+			ast.KindRawHTML, qjskatex.KindTex:
+			return ast.WalkSkipChildren, nil
+		}
+		switch x := n.(type) {
+		case *ast.Heading:
+			flush()
+			id, _ := x.AttributeString("id")
+			idBytes, _ := id.([]byte)
+			sections = append(sections, search.Section{
+				ID:      string(idBytes),
+				Heading: asts.PlainText(x, p.Source),
+			})
+			return ast.WalkSkipChildren, nil
+		case *ast.String:
+			sb.Write(x.Value)
+		case *ast.Text:
+			sb.Write(x.Segment.Value(p.Source))
+			if x.SoftLineBreak() || x.HardLineBreak() {
+				sb.WriteByte(' ')
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	flush()
+	return search.Doc{
+		URL:      "/" + p.Meta.Slug,
+		Title:    p.Meta.Title,
+		Date:     p.Meta.Date.Format("2006-01-02"),
+		Sections: sections,
+	}
+}
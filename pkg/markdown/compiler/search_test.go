@@ -0,0 +1,47 @@
+package compiler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jschaf/jsc/pkg/markdown"
+	"github.com/jschaf/jsc/pkg/testing/difftest"
+	"github.com/jschaf/jsc/pkg/testing/require"
+	"github.com/jschaf/jsc/pkg/texts"
+	"github.com/jschaf/jsc/render/search"
+)
+
+func TestSearchDoc(t *testing.T) {
+	src := texts.Dedent(`
+		+++
+		slug = "foo"
+		date = 2020-01-02
+		+++
+		# Title
+
+		Intro with ` + "`code`" + ` and $x^2$
+		math.
+
+		## Second *section*
+
+		- one
+		- two
+
+		` + "```" + `
+		func main() {}
+		` + "```" + `
+	`)
+	ast, err := markdown.New().Parse("post.md", strings.NewReader(src))
+	require.NoError(t, err)
+
+	want := search.Doc{
+		URL:   "/foo",
+		Title: "Title",
+		Date:  "2020-01-02",
+		Sections: []search.Section{
+			{Text: "Intro with and math."},
+			{ID: "second-section", Heading: "Second section", Text: "one two"},
+		},
+	}
+	difftest.AssertSame(t, want, searchDoc(ast))
+}
@@ -0,0 +1,130 @@
+package compiler
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jschaf/jsc/pkg/markdown"
+	"github.com/jschaf/jsc/pkg/markdown/catalog"
+	"github.com/jschaf/jsc/pkg/markdown/html"
+	"github.com/jschaf/jsc/pkg/markdown/mdctx"
+	"github.com/jschaf/jsc/pkg/markdown/mdext"
+)
+
+// seriesDir is the dist dir that holds the listing page of each series.
+const seriesDir = "series"
+
+// seriesPath returns the URL path of the listing page for the series.
+func seriesPath(name string) string {
+	return "/" + seriesDir + "/" + name + "/"
+}
+
+// seriesParams returns the series of each post in a series. A published post
+// only links to the published parts of the series, so a draft part isn't
+// linked until it's published.
+func seriesParams(series []catalog.Series) map[*markdown.AST]*html.SeriesParams {
+	params := make(map[*markdown.AST]*html.SeriesParams)
+	for _, s := range series {
+		for _, ast := range s.Posts {
+			sp := &html.SeriesParams{Name: s.Name, Path: seriesPath(s.Name)}
+			for _, p := range s.Posts {
+				if p != ast && p.Meta.Visibility != mdext.VisibilityPublished {
+					continue
+				}
+				sp.Posts = append(sp.Posts, html.SeriesPostParams{
+					Title:   p.Meta.Title,
+					Path:    catalog.URLPath(p),
+					Current: p == ast,
+				})
+				if p == ast {
+					sp.Part = len(sp.Posts)
+				}
+			}
+			if i := sp.Part - 1; i > 0 {
+				sp.Prev = &sp.Posts[i-1]
+			}
+			if i := sp.Part - 1; i < len(sp.Posts)-1 {
+				sp.Next = &sp.Posts[i+1]
+			}
+			params[ast] = sp
+		}
+	}
+	return params
+}
+
+// seriesStamp returns the parts of the series as a manifest stamp so that a
+// post is rebuilt when another part of its series is added, removed or
+// renamed. Returns the empty string if the post isn't part of a series.
+func seriesStamp(sp *html.SeriesParams) string {
+	if sp == nil {
+		return ""
+	}
+	parts := make([]string, len(sp.Posts))
+	for i, p := range sp.Posts {
+		parts[i] = p.Path + "=" + p.Title
+	}
+	return ";series:" + sp.Name + ":" + strings.Join(parts, ",")
+}
+
+// seriesPage is the listing of the published posts in a series.
+type seriesPage struct {
+	name string
+	// posts are sorted in series order.
+	posts []html.IndexPostParams
+}
+
+// groupSeries returns the listing page of each series with a published post,
+// sorted by series name.
+func groupSeries(series []catalog.Series, posts []html.IndexPostParams) []seriesPage {
+	bySlug := make(map[string]html.IndexPostParams, len(posts))
+	for _, p := range posts {
+		bySlug[p.Slug] = p
+	}
+	var pages []seriesPage
+	for _, s := range series {
+		page := seriesPage{name: s.Name}
+		for _, ast := range s.Posts {
+			if p, ok := bySlug[ast.Meta.Slug]; ok && ast.Meta.Visibility == mdext.VisibilityPublished {
+				page.posts = append(page.posts, p)
+			}
+		}
+		if len(page.posts) > 0 {
+			pages = append(pages, page)
+		}
+	}
+	return pages
+}
+
+// lastModified returns the publish date of the newest post in the series.
+func (sp seriesPage) lastModified() time.Time {
+	var t time.Time
+	for _, p := range sp.posts {
+		if p.Date.After(t) {
+			t = p.Date
+		}
+	}
+	return t
+}
+
+// writeSeriesPages writes a listing page for each series. Returns the paths
+// written, relative to the dist dir.
+func (ic *IndexCompiler) writeSeriesPages(feats *mdctx.FeatureSet, pages []seriesPage) ([]string, error) {
+	outputs := make([]string, 0, len(pages))
+	for _, page := range pages {
+		out := filepath.Join(seriesDir, page.name, "index.html")
+		heading := "Series “" + page.name + "”"
+		data := html.IndexParams{
+			Title:    heading,
+			Heading:  heading,
+			Posts:    page.posts,
+			Features: feats,
+		}
+		if err := ic.writeIndexPage(out, data); err != nil {
+			return nil, fmt.Errorf("write series page %s: %w", page.name, err)
+		}
+		outputs = append(outputs, out)
+	}
+	return outputs, nil
+}
@@ -0,0 +1,60 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/jschaf/jsc/pkg/markdown"
+	"github.com/jschaf/jsc/pkg/markdown/catalog"
+	"github.com/jschaf/jsc/pkg/markdown/html"
+	"github.com/jschaf/jsc/pkg/markdown/mdext"
+	"github.com/jschaf/jsc/pkg/testing/difftest"
+)
+
+func TestSeriesParams(t *testing.T) {
+	post := func(slug, visibility string) *markdown.AST {
+		return &markdown.AST{Meta: mdext.PostMeta{Slug: slug, Title: slug + " title", Visibility: visibility}}
+	}
+	first := post("first", mdext.VisibilityPublished)
+	draft := post("draft", mdext.VisibilityDraft)
+	last := post("last", mdext.VisibilityPublished)
+	params := seriesParams([]catalog.Series{{Name: "perf", Posts: []*markdown.AST{first, draft, last}}})
+
+	firstLink := html.SeriesPostParams{Title: "first title", Path: "/first"}
+	lastLink := html.SeriesPostParams{Title: "last title", Path: "/last"}
+	difftest.AssertSame(t, &html.SeriesParams{
+		Name:  "perf",
+		Path:  "/series/perf/",
+		Part:  1,
+		Posts: []html.SeriesPostParams{{Title: "first title", Path: "/first", Current: true}, lastLink},
+		Next:  &lastLink,
+	}, params[first])
+	difftest.AssertSame(t, &html.SeriesParams{
+		Name:  "perf",
+		Path:  "/series/perf/",
+		Part:  2,
+		Posts: []html.SeriesPostParams{firstLink, {Title: "last title", Path: "/last", Current: true}},
+		Prev:  &firstLink,
+	}, params[last])
+	// A draft shows its own position among the published parts.
+	difftest.AssertSame(t, 2, params[draft].Part)
+	difftest.AssertSame(t, 3, len(params[draft].Posts))
+}
+
+func TestGroupSeries(t *testing.T) {
+	ast := func(slug string) *markdown.AST {
+		return &markdown.AST{Meta: mdext.PostMeta{Slug: slug, Visibility: mdext.VisibilityPublished}}
+	}
+	series := []catalog.Series{
+		{Name: "drafts", Posts: []*markdown.AST{ast("unlisted")}},
+		{Name: "perf", Posts: []*markdown.AST{ast("old"), ast("new")}},
+	}
+	// Posts are newest first, unlike the series order.
+	posts := []html.IndexPostParams{{Slug: "new"}, {Slug: "old"}}
+	got := make(map[string][]string)
+	for _, page := range groupSeries(series, posts) {
+		for _, p := range page.posts {
+			got[page.name] = append(got[page.name], p.Slug)
+		}
+	}
+	difftest.AssertSame(t, map[string][]string{"perf": {"old", "new"}}, got)
+}
@@ -0,0 +1,102 @@
+package compiler
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/jschaf/jsc/pkg/markdown"
+	"github.com/jschaf/jsc/pkg/markdown/html"
+	"github.com/jschaf/jsc/pkg/markdown/mdctx"
+	"github.com/jschaf/jsc/pkg/markdown/mdext"
+)
+
+// tagsDir is the dist dir that holds the tag listing pages.
+const tagsDir = "tags"
+
+// tagPath returns the URL path of the listing page for the tag.
+func tagPath(tag string) string {
+	return "/" + tagsDir + "/" + tag + "/"
+}
+
+// tagParams returns a link to the listing page of each tag.
+func tagParams(tags []string) []html.TagParams {
+	if len(tags) == 0 {
+		return nil
+	}
+	params := make([]html.TagParams, len(tags))
+	for i, tag := range tags {
+		params[i] = html.TagParams{Name: tag, Path: tagPath(tag)}
+	}
+	return params
+}
+
+// detailTags returns the tag links for the detail page of the post. Drafts
+// link no tags since tag pages only list published posts.
+func detailTags(ast *markdown.AST) []html.TagParams {
+	if ast.Meta.Visibility == mdext.VisibilityDraft {
+		return nil
+	}
+	return tagParams(ast.Meta.Tags)
+}
+
+// tagPage is the listing of the published posts with a tag.
+type tagPage struct {
+	tag string
+	// posts are sorted newest first.
+	posts []html.IndexPostParams
+}
+
+// groupTags groups posts by tag, sorted by tag name. Keeps the order of
+// posts, which is newest first.
+func groupTags(posts []html.IndexPostParams) []tagPage {
+	byTag := make(map[string][]html.IndexPostParams)
+	for _, p := range posts {
+		for _, tag := range p.Tags {
+			byTag[tag] = append(byTag[tag], p)
+		}
+	}
+	pages := make([]tagPage, 0, len(byTag))
+	for tag, ps := range byTag {
+		pages = append(pages, tagPage{tag: tag, posts: ps})
+	}
+	sort.Slice(pages, func(i, j int) bool { return pages[i].tag < pages[j].tag })
+	return pages
+}
+
+// writeTagPages writes the /tags/ page listing every tag and a listing page
+// for each tag. Returns the paths written, relative to the dist dir.
+func (ic *IndexCompiler) writeTagPages(feats *mdctx.FeatureSet, pages []tagPage) ([]string, error) {
+	if len(pages) == 0 {
+		return nil, nil
+	}
+	outputs := make([]string, 0, len(pages)+1)
+	tags := make([]html.TagParams, len(pages))
+	for i, page := range pages {
+		tags[i] = html.TagParams{Name: page.tag, Path: tagPath(page.tag), Count: len(page.posts)}
+		out := filepath.Join(tagsDir, page.tag, "index.html")
+		heading := "Posts tagged “" + page.tag + "”"
+		data := html.IndexParams{
+			Title:    heading,
+			Heading:  heading,
+			Posts:    page.posts,
+			Features: feats,
+		}
+		if err := ic.writeIndexPage(out, data); err != nil {
+			return nil, fmt.Errorf("write tag page %s: %w", page.tag, err)
+		}
+		outputs = append(outputs, out)
+	}
+
+	out := filepath.Join(tagsDir, "index.html")
+	data := html.IndexParams{
+		Title:    "Tags",
+		Heading:  "Tags",
+		Tags:     tags,
+		Features: feats,
+	}
+	if err := ic.writeIndexPage(out, data); err != nil {
+		return nil, fmt.Errorf("write tags page: %w", err)
+	}
+	return append(outputs, out), nil
+}
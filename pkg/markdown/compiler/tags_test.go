@@ -0,0 +1,39 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/jschaf/jsc/pkg/markdown"
+	"github.com/jschaf/jsc/pkg/markdown/html"
+	"github.com/jschaf/jsc/pkg/markdown/mdext"
+	"github.com/jschaf/jsc/pkg/testing/difftest"
+)
+
+func TestGroupTags(t *testing.T) {
+	posts := []html.IndexPostParams{
+		{Slug: "newest", Tags: []string{"go", "sql"}},
+		{Slug: "middle"},
+		{Slug: "oldest", Tags: []string{"go"}},
+	}
+	got := make(map[string][]string)
+	var order []string
+	for _, page := range groupTags(posts) {
+		order = append(order, page.tag)
+		for _, p := range page.posts {
+			got[page.tag] = append(got[page.tag], p.Slug)
+		}
+	}
+	difftest.AssertSame(t, []string{"go", "sql"}, order)
+	difftest.AssertSame(t, map[string][]string{
+		"go":  {"newest", "oldest"},
+		"sql": {"newest"},
+	}, got)
+}
+
+func TestDetailTags(t *testing.T) {
+	post := func(visibility string) *markdown.AST {
+		return &markdown.AST{Meta: mdext.PostMeta{Visibility: visibility, Tags: []string{"go"}}}
+	}
+	difftest.AssertSame(t, []html.TagParams{{Name: "go", Path: "/tags/go/"}}, detailTags(post(mdext.VisibilityPublished)))
+	difftest.AssertSame(t, []html.TagParams(nil), detailTags(post(mdext.VisibilityDraft)))
+}
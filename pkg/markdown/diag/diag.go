@@ -0,0 +1,258 @@
+// Package diag reports problems found while building posts, like a footnote
+// without a body, with the source location of the problem so that a build
+// reports every problem at once.
+package diag
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// Severity is how bad a diagnostic is. Errors fail the build; warnings don't.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// NoOffset is the offset for a diagnostic without a known source position.
+const NoOffset = -1
+
+// Diagnostic is a problem in a source file.
+type Diagnostic struct {
+	// Path is the full path of the file with the problem.
+	Path string
+	// Line and Col are the 1-based position of the problem. Zero if unknown.
+	Line int
+	Col  int
+	// Offset is the byte offset of the problem in the source, or NoOffset.
+	// Resolve converts the offset into the line and column.
+	Offset   int
+	Severity Severity
+	// Ext is the name of the extension that found the problem, like
+	// "footnote".
+	Ext string
+	Err error
+	// LineText is the source line containing the problem, shown in reports.
+	LineText string
+}
+
+// Errorf creates an error diagnostic at offset from the extension named ext.
+func Errorf(ext string, offset int, format string, args ...any) Diagnostic {
+	return Diagnostic{Offset: offset, Severity: SeverityError, Ext: ext, Err: fmt.Errorf(format, args...)}
+}
+
+func (d Diagnostic) Error() string {
+	b := &strings.Builder{}
+	d.writeLocation(b)
+	d.writeMessage(b)
+	return b.String()
+}
+
+func (d Diagnostic) Unwrap() error {
+	return d.Err
+}
+
+func (d Diagnostic) writeLocation(b *strings.Builder) {
+	if d.Path == "" {
+		return
+	}
+	b.WriteString(d.Path)
+	if d.Line > 0 {
+		_, _ = fmt.Fprintf(b, ":%d:%d", d.Line, d.Col)
+	}
+	b.WriteString(": ")
+}
+
+func (d Diagnostic) writeMessage(b *strings.Builder) {
+	if d.Ext != "" {
+		b.WriteString(d.Ext)
+		b.WriteString(": ")
+	}
+	if d.Err != nil {
+		b.WriteString(d.Err.Error())
+	}
+}
+
+// Resolve sets the path of the diagnostic and computes the line and column
+// from the offset into src. Doesn't change diagnostics that already have a
+// different path since the offset refers to another file.
+func (d *Diagnostic) Resolve(path string, src []byte) {
+	if d.Path != "" && d.Path != path {
+		return
+	}
+	d.Path = path
+	if d.Offset < 0 || d.Offset > len(src) || d.Line > 0 {
+		return
+	}
+	lineStart := bytes.LastIndexByte(src[:d.Offset], '\n') + 1
+	lineEnd := bytes.IndexByte(src[d.Offset:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(src)
+	} else {
+		lineEnd += d.Offset
+	}
+	d.Line = bytes.Count(src[:lineStart], []byte{'\n'}) + 1
+	d.Col = utf8.RuneCount(src[lineStart:d.Offset]) + 1
+	d.LineText = string(bytes.TrimRight(src[lineStart:lineEnd], "\r"))
+}
+
+// NodeOffset returns the byte offset in the source of the first segment in n
+// or its descendants. If n has no segments, like some inline nodes, returns
+// the offset of the nearest ancestor with a segment, or NoOffset.
+func NodeOffset(n ast.Node) int {
+	for ; n != nil; n = n.Parent() {
+		offset := NoOffset
+		_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+			if !entering {
+				return ast.WalkContinue, nil
+			}
+			if c.Type() == ast.TypeBlock && c.Lines().Len() > 0 {
+				offset = c.Lines().At(0).Start
+				return ast.WalkStop, nil
+			}
+			if t, ok := c.(*ast.Text); ok {
+				offset = t.Segment.Start
+				return ast.WalkStop, nil
+			}
+			return ast.WalkContinue, nil
+		})
+		if offset != NoOffset {
+			return offset
+		}
+	}
+	return NoOffset
+}
+
+// List is a list of diagnostics usable as an error.
+type List []Diagnostic
+
+func (l List) Error() string {
+	msgs := make([]string, len(l))
+	for i, d := range l {
+		msgs[i] = d.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// HasErrors returns true if any diagnostic has error severity.
+func (l List) HasErrors() bool {
+	return l.Count(SeverityError) > 0
+}
+
+// Count returns the number of diagnostics with the severity.
+func (l List) Count(s Severity) int {
+	n := 0
+	for _, d := range l {
+		if d.Severity == s {
+			n++
+		}
+	}
+	return n
+}
+
+// Err returns the list as an error if it has any errors, otherwise nil.
+func (l List) Err() error {
+	if !l.HasErrors() {
+		return nil
+	}
+	return l
+}
+
+// Sort sorts the diagnostics by path and position.
+func (l List) Sort() {
+	sort.SliceStable(l, func(i, j int) bool {
+		if l[i].Path != l[j].Path {
+			return l[i].Path < l[j].Path
+		}
+		if l[i].Line != l[j].Line {
+			return l[i].Line < l[j].Line
+		}
+		return l[i].Col < l[j].Col
+	})
+}
+
+// FromError extracts the diagnostics from err. Returns a single diagnostic
+// without a position if err contains no diagnostics.
+func FromError(err error) List {
+	if err == nil {
+		return nil
+	}
+	var l List
+	if errors.As(err, &l) {
+		return l
+	}
+	var d Diagnostic
+	if errors.As(err, &d) {
+		return List{d}
+	}
+	return List{{Offset: NoOffset, Severity: SeverityError, Err: err}}
+}
+
+// Collector collects diagnostics from concurrent goroutines.
+type Collector struct {
+	mu    sync.Mutex
+	diags List
+}
+
+// Add adds the diagnostics in err. Does nothing if err is nil.
+func (c *Collector) Add(err error) {
+	c.AddAll(FromError(err)...)
+}
+
+// AddFile adds the diagnostics in err for the file at path with the contents
+// src, resolving the position of diagnostics without one. src may be nil if
+// the contents are unknown. Does nothing if err is nil.
+func (c *Collector) AddFile(path string, src []byte, err error) {
+	ds := slices.Clone(FromError(err))
+	for i := range ds {
+		ds[i].Resolve(path, src)
+	}
+	c.AddAll(ds...)
+}
+
+// AddAll adds each diagnostic.
+func (c *Collector) AddAll(ds ...Diagnostic) {
+	if len(ds) == 0 {
+		return
+	}
+	c.mu.Lock()
+	c.diags = append(c.diags, ds...)
+	c.mu.Unlock()
+}
+
+// List returns a sorted copy of all collected diagnostics.
+func (c *Collector) List() List {
+	c.mu.Lock()
+	l := make(List, len(c.diags))
+	copy(l, c.diags)
+	c.mu.Unlock()
+	l.Sort()
+	return l
+}
+
+// Err returns the collected diagnostics as an error if any has error
+// severity.
+func (c *Collector) Err() error {
+	return c.List().Err()
+}
@@ -0,0 +1,94 @@
+package diag
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jschaf/jsc/pkg/testing/difftest"
+	"github.com/jschaf/jsc/pkg/testing/require"
+	"github.com/jschaf/jsc/pkg/texts"
+)
+
+func TestDiagnostic_Resolve(t *testing.T) {
+	src := []byte("alpha\nbravo charlie\n\tdelta")
+	tests := []struct {
+		name   string
+		offset int
+		want   Diagnostic
+	}{
+		{"first line", 2, Diagnostic{Path: "a.md", Line: 1, Col: 3, Offset: 2, LineText: "alpha"}},
+		{"start of line", 6, Diagnostic{Path: "a.md", Line: 2, Col: 1, Offset: 6, LineText: "bravo charlie"}},
+		{"last line", 21, Diagnostic{Path: "a.md", Line: 3, Col: 2, Offset: 21, LineText: "\tdelta"}},
+		{"no offset", NoOffset, Diagnostic{Path: "a.md", Offset: NoOffset}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Diagnostic{Offset: tt.offset}
+			d.Resolve("a.md", src)
+			difftest.AssertSame(t, tt.want, d)
+		})
+	}
+}
+
+func TestDiagnostic_Resolve_OtherPath(t *testing.T) {
+	d := Diagnostic{Path: "b.bib", Offset: 2}
+	d.Resolve("a.md", []byte("alpha"))
+	difftest.AssertSame(t, Diagnostic{Path: "b.bib", Offset: 2}, d)
+}
+
+func TestFromError(t *testing.T) {
+	d := Errorf("ext", 1, "alpha")
+	l := List{d, Errorf("ext", 2, "bravo")}
+	other := errors.New("charlie")
+	tests := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{"nil", nil, nil},
+		{"diagnostic", fmt.Errorf("wrap: %w", d), []string{"ext: alpha"}},
+		{"list", fmt.Errorf("wrap: %w", l), []string{"ext: alpha", "ext: bravo"}},
+		{"other", other, []string{"charlie"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, d := range FromError(tt.err) {
+				got = append(got, d.Error())
+			}
+			difftest.AssertSame(t, tt.want, got)
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	src := []byte("# title\n\nsee [^side:foo]\n")
+	d1 := Errorf("footnote", 13, "no footnote body")
+	d1.Resolve("/post.md", src)
+	d2 := Diagnostic{Path: "/post.md", Offset: NoOffset, Severity: SeverityWarning, Err: errors.New("no date")}
+	b := &strings.Builder{}
+
+	err := Report(b, List{d1, d2})
+
+	if err == nil || err.Error() != "found 1 error" {
+		t.Errorf("Report error: want 'found 1 error'; got %v", err)
+	}
+	want := texts.Dedent(`
+		/post.md:3:5: error: footnote: no footnote body
+		   3 | see [^side:foo]
+		     |     ^
+		/post.md: warning: no date
+
+		1 error, 1 warning
+	`)
+	difftest.AssertSame(t, want+"\n", b.String())
+}
+
+func TestReport_WarningsOnly(t *testing.T) {
+	b := &strings.Builder{}
+	d := Diagnostic{Severity: SeverityWarning, Err: errors.New("alpha")}
+	require.NoError(t, Report(b, List{d}))
+	difftest.AssertSame(t, "warning: alpha\n\n0 errors, 1 warning\n", b.String())
+}
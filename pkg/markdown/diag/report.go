@@ -0,0 +1,114 @@
+package diag
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ReportError is the error returned by Report. The message only summarizes
+// the diagnostics since Report already wrote them.
+type ReportError struct {
+	Diags List
+}
+
+func (e *ReportError) Error() string {
+	return "found " + plural(e.Diags.Count(SeverityError), "error")
+}
+
+func (e *ReportError) Unwrap() error {
+	return e.Diags
+}
+
+// Report writes a report of the diagnostics to w, if there are any, and
+// returns a *ReportError if any diagnostic is an error.
+func Report(w io.Writer, l List) error {
+	if len(l) == 0 {
+		return nil
+	}
+	if err := WriteReport(w, l); err != nil {
+		return err
+	}
+	if l.HasErrors() {
+		return &ReportError{Diags: l}
+	}
+	return nil
+}
+
+// WriteReport writes a compiler-style report of the diagnostics to w, like:
+//
+//	posts/foo.md:12:3: error: footnote: no bibtex found for key: bar
+//	   12 | see [^@bar] for details
+//	      |     ^
+//
+//	1 error, 0 warnings
+//
+// Paths are shown relative to the working directory when possible.
+func WriteReport(w io.Writer, l List) error {
+	b := &strings.Builder{}
+	for _, d := range l {
+		d.Path = relPath(d.Path)
+		d.writeLocation(b)
+		b.WriteString(d.Severity.String())
+		b.WriteString(": ")
+		d.writeMessage(b)
+		b.WriteByte('\n')
+		if d.LineText != "" {
+			writeSnippet(b, d)
+		}
+	}
+	if len(l) > 0 {
+		b.WriteByte('\n')
+	}
+	_, _ = fmt.Fprintf(b, "%s, %s\n", plural(l.Count(SeverityError), "error"), plural(l.Count(SeverityWarning), "warning"))
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("write diagnostics report: %w", err)
+	}
+	return nil
+}
+
+// writeSnippet writes the source line of the diagnostic with a caret under
+// the column.
+func writeSnippet(b *strings.Builder, d Diagnostic) {
+	num := strconv.Itoa(d.Line)
+	gutter := strings.Repeat(" ", len(num))
+	_, _ = fmt.Fprintf(b, "   %s | %s\n", num, d.LineText)
+	// Keep tabs so the caret lines up with the source line.
+	caret := &strings.Builder{}
+	for i, r := range []rune(d.LineText) {
+		if i >= d.Col-1 {
+			break
+		}
+		if r == '\t' {
+			caret.WriteRune('\t')
+		} else {
+			caret.WriteRune(' ')
+		}
+	}
+	_, _ = fmt.Fprintf(b, "   %s | %s^\n", gutter, caret.String())
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return strconv.Itoa(n) + " " + noun + "s"
+}
+
+func relPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,104 @@
+package html
+
+import (
+	"time"
+
+	"github.com/jschaf/jsc/pkg/config"
+)
+
+// PageMeta describes a page for link previews, like OpenGraph and Twitter
+// cards, and for search engines.
+type PageMeta struct {
+	// URL is the absolute canonical URL of the page.
+	URL         string
+	Description string
+	// ImageURL is the absolute URL of the preview image, if any.
+	ImageURL string
+	// Article describes a post. Nil for pages that aren't a post, like the
+	// index.
+	Article *ArticleMeta
+}
+
+// ArticleMeta describes a post.
+type ArticleMeta struct {
+	Published time.Time
+	// Modified is the time the post last changed. Zero if unknown.
+	Modified time.Time
+	Tags     []string
+	// Citations are the titles of the works cited by the post in order of
+	// appearance.
+	Citations []string
+}
+
+// OGType returns the OpenGraph type of the page.
+func (m PageMeta) OGType() string {
+	if m.Article != nil {
+		return "article"
+	}
+	return "website"
+}
+
+// TwitterCard returns the Twitter card type, a large image if the page has an
+// image.
+func (m PageMeta) TwitterCard() string {
+	if m.ImageURL != "" {
+		return "summary_large_image"
+	}
+	return "summary"
+}
+
+// blogPosting is the schema.org BlogPosting type serialized as JSON-LD.
+// https://schema.org/BlogPosting
+type blogPosting struct {
+	Context          string               `json:"@context"`
+	Type             string               `json:"@type"`
+	Headline         string               `json:"headline"`
+	URL              string               `json:"url"`
+	MainEntityOfPage string               `json:"mainEntityOfPage"`
+	Description      string               `json:"description,omitempty"`
+	Image            string               `json:"image,omitempty"`
+	DatePublished    string               `json:"datePublished"`
+	DateModified     string               `json:"dateModified,omitempty"`
+	Author           jsonLDPerson         `json:"author"`
+	Keywords         []string             `json:"keywords,omitempty"`
+	Citations        []jsonLDCreativeWork `json:"citation,omitempty"`
+}
+
+type jsonLDPerson struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type jsonLDCreativeWork struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+// JSONLD returns the schema.org BlogPosting for a post, or nil if the page
+// isn't a post. html/template serializes the value as JSON in a script tag.
+func (m PageMeta) JSONLD(site *config.Config, headline string) any {
+	a := m.Article
+	if a == nil {
+		return nil
+	}
+	p := blogPosting{
+		Context:          "https://schema.org",
+		Type:             "BlogPosting",
+		Headline:         headline,
+		URL:              m.URL,
+		MainEntityOfPage: m.URL,
+		Description:      m.Description,
+		Image:            m.ImageURL,
+		DatePublished:    a.Published.UTC().Format(time.RFC3339),
+		Author:           jsonLDPerson{Type: "Person", Name: site.Author, URL: site.URL + "/"},
+		Keywords:         a.Tags,
+	}
+	if !a.Modified.IsZero() {
+		p.DateModified = a.Modified.UTC().Format(time.RFC3339)
+	}
+	for _, c := range a.Citations {
+		p.Citations = append(p.Citations, jsonLDCreativeWork{Type: "CreativeWork", Name: c})
+	}
+	return p
+}
@@ -8,33 +8,58 @@ import (
 	"sync"
 	"time"
 
+	"github.com/jschaf/jsc/pkg/config"
 	"github.com/jschaf/jsc/pkg/dirs"
+	"github.com/jschaf/jsc/pkg/markdown"
 	"github.com/jschaf/jsc/pkg/markdown/mdctx"
 
 	"github.com/jschaf/jsc/pkg/git"
 )
 
 var (
-	layoutDir = filepath.Join(git.RootDir(), dirs.Pkg, "markdown", "html")
-	baseTmpl  = filepath.Join(layoutDir, "base.gohtml")
+	layoutDir      = filepath.Join(git.RootDir(), dirs.Pkg, "markdown", "html")
+	baseTmplPath   = filepath.Join(layoutDir, "base.gohtml")
+	indexTmplPath  = filepath.Join(layoutDir, "index.gohtml")
+	detailTmplPath = filepath.Join(layoutDir, "detail.gohtml")
 
 	indexTmpl = sync.OnceValue(func() *template.Template {
-		tmpl := filepath.Join(layoutDir, "index.gohtml")
 		return template.Must(
-			template.New("index").Funcs(TemplateFuncs()).ParseFiles(tmpl, baseTmpl))
+			template.New("index").Funcs(TemplateFuncs()).ParseFiles(indexTmplPath, baseTmplPath))
 	})
 
 	detailTmpl = sync.OnceValue(func() *template.Template {
-		tmpl := filepath.Join(layoutDir, "detail.gohtml")
 		return template.Must(
-			template.New("detail").Funcs(TemplateFuncs()).ParseFiles(tmpl, baseTmpl))
+			template.New("detail").Funcs(TemplateFuncs()).ParseFiles(detailTmplPath, baseTmplPath))
 	})
 )
 
+// IndexTemplatePaths returns the full paths of the template files used by
+// RenderIndex.
+func IndexTemplatePaths() []string {
+	return []string{indexTmplPath, baseTmplPath}
+}
+
+// DetailTemplatePaths returns the full paths of the template files used by
+// RenderDetail.
+func DetailTemplatePaths() []string {
+	return []string{detailTmplPath, baseTmplPath}
+}
+
 type IndexParams struct {
-	Title    string
+	Site  *config.Config
+	Title string
+	Meta  PageMeta
+	// Heading is shown above the listing if set, like on a tag page.
+	Heading  string
 	Features *mdctx.FeatureSet
 	Posts    []IndexPostParams
+	// Tags lists tags instead of posts, like on the /tags/ page.
+	Tags []TagParams
+	// Pagination links to the neighboring pages if the posts span more than
+	// one page.
+	Pagination *PaginationParams
+	// Archives links to the archive page of each year, newest first.
+	Archives []ArchiveParams
 }
 
 type IndexPostParams struct {
@@ -43,6 +68,37 @@ type IndexPostParams struct {
 	Slug      string
 	Body      template.HTML
 	Date      time.Time
+	Tags      []string
+	// TIL is true for a post from the TIL dir.
+	TIL   bool
+	Stats markdown.Stats
+}
+
+// PaginationParams links a page of posts to its neighbors.
+type PaginationParams struct {
+	// Page is the 1-based page number.
+	Page     int
+	NumPages int
+	// PrevPath is the URL path of the page with newer posts, or empty for the
+	// first page.
+	PrevPath string
+	// NextPath is the URL path of the page with older posts, or empty for the
+	// last page.
+	NextPath string
+}
+
+// ArchiveParams links to the archive page of a year.
+type ArchiveParams struct {
+	Year int
+	Path string
+}
+
+// TagParams is a link to the listing page of a tag.
+type TagParams struct {
+	Name string
+	Path string
+	// Count is the number of published posts with the tag.
+	Count int
 }
 
 func RenderIndex(w io.Writer, p IndexParams) error {
@@ -54,9 +110,58 @@ func RenderIndex(w io.Writer, p IndexParams) error {
 }
 
 type DetailParams struct {
+	Site     *config.Config
 	Title    string
+	Meta     PageMeta
 	Features *mdctx.FeatureSet
 	Content  template.HTML
+	Tags     []TagParams
+	// Revisions are the changes to the post, newest first. Empty unless the
+	// revision history is enabled in the site config.
+	Revisions []RevisionParams
+	// Series links to the other parts of the series of the post. Nil if the
+	// post isn't part of a series.
+	Series *SeriesParams
+	// Related are the posts most related to the post, most related first.
+	Related []PostLinkParams
+	// Backlinks are the published posts that link to the post, newest first.
+	Backlinks []PostLinkParams
+}
+
+// PostLinkParams is a link to another post.
+type PostLinkParams struct {
+	Title string
+	Path  string
+}
+
+// SeriesParams lists the parts of a series on a post in the series.
+type SeriesParams struct {
+	Name string
+	// Path is the URL path of the listing page of the series.
+	Path string
+	// Part is the 1-based position of the post in the series.
+	Part  int
+	Posts []SeriesPostParams
+	// Prev and Next are the neighboring parts of the post. Nil for the first
+	// and last parts.
+	Prev *SeriesPostParams
+	Next *SeriesPostParams
+}
+
+// SeriesPostParams is a link to a part of a series.
+type SeriesPostParams struct {
+	Title string
+	Path  string
+	// Current is true for the post that shows the series.
+	Current bool
+}
+
+// RevisionParams is a change to a post.
+type RevisionParams struct {
+	Date    time.Time
+	Summary string
+	// URL links to the change, like a commit on GitHub. Empty if unknown.
+	URL string
 }
 
 func RenderDetail(w io.Writer, p DetailParams) error {
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/jschaf/jsc/pkg/config"
 	"github.com/jschaf/jsc/pkg/markdown/mdctx"
 )
 
@@ -14,6 +15,7 @@ func TestRenderPost(t *testing.T) {
 	title := "foo_title"
 	content := "<b>foo_content</b>"
 	err := RenderDetail(w, DetailParams{
+		Site:     testSite(),
 		Title:    title,
 		Content:  template.HTML(content),
 		Features: mdctx.NewFeatureSet(),
@@ -29,10 +31,61 @@ func TestRenderPost(t *testing.T) {
 	}
 }
 
+func TestRenderPost_Series(t *testing.T) {
+	w := &bytes.Buffer{}
+	prev := SeriesPostParams{Title: "Part one", Path: "/one"}
+	err := RenderDetail(w, DetailParams{
+		Site:     testSite(),
+		Title:    "Part two",
+		Features: mdctx.NewFeatureSet(),
+		Series: &SeriesParams{
+			Name:  "perf",
+			Path:  "/series/perf/",
+			Part:  2,
+			Posts: []SeriesPostParams{prev, {Title: "Part two", Path: "/two", Current: true}},
+			Prev:  &prev,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<p>Part 2 of 2 in the series <a href="/series/perf/">perf</a></p>`,
+		`<li><span aria-current="page">Part two</span></li>`,
+		`<a href="/one" rel="prev">← Part one</a>`,
+	} {
+		if !strings.Contains(w.String(), want) {
+			t.Errorf("rendered content doesn't include %q:\n\n%s", want, w.String())
+		}
+	}
+}
+
+func TestRenderPost_Backlinks(t *testing.T) {
+	w := &bytes.Buffer{}
+	err := RenderDetail(w, DetailParams{
+		Site:      testSite(),
+		Title:     "Target",
+		Features:  mdctx.NewFeatureSet(),
+		Backlinks: []PostLinkParams{{Title: "Source", Path: "/source/"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<h2>Linked from</h2>`,
+		`<li><a href="/source/">Source</a></li>`,
+	} {
+		if !strings.Contains(w.String(), want) {
+			t.Errorf("rendered content doesn't include %q:\n\n%s", want, w.String())
+		}
+	}
+}
+
 func TestRenderIndex(t *testing.T) {
 	w := &bytes.Buffer{}
 	title := "foo_title"
 	data := IndexParams{
+		Site:  testSite(),
 		Title: title,
 		Posts: []IndexPostParams{
 			{TitleHTML: "post1", Body: template.HTML("body")},
@@ -54,3 +107,29 @@ func TestRenderIndex(t *testing.T) {
 		t.Errorf("rendered content doesn't include %q:\n\n%s", "post2", w.String())
 	}
 }
+
+func TestRenderIndex_Tags(t *testing.T) {
+	w := &bytes.Buffer{}
+	data := IndexParams{
+		Site:     testSite(),
+		Title:    "Tags",
+		Heading:  "Tags",
+		Tags:     []TagParams{{Name: "go", Path: "/tags/go/", Count: 2}},
+		Features: mdctx.NewFeatureSet(),
+	}
+	if err := RenderIndex(w, data); err != nil {
+		t.Fatal(err)
+	}
+	want := `<li><a href="/tags/go/" rel="tag">go</a> <span class="tag-count">2</span></li>`
+	if !strings.Contains(w.String(), want) {
+		t.Errorf("rendered content doesn't include %q:\n\n%s", want, w.String())
+	}
+}
+
+func testSite() *config.Config {
+	return &config.Config{
+		Title:  "Blog",
+		Author: "Alice",
+		Nav:    []config.NavLink{{Name: "GitHub", URL: "https://github.com/alice"}},
+	}
+}
@@ -0,0 +1,149 @@
+package linkio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// snapshotDir is the dir in the archive that holds page snapshots. Snapshots
+// are checked in with the records that point at them.
+const snapshotDir = "snapshots"
+
+// ArchiveRecord is the latest check of a link and the last time the link
+// was alive.
+type ArchiveRecord struct {
+	URL       string    `json:"url"`
+	CheckedAt time.Time `json:"checked_at"`
+	// StatusCode is the status of the final response. Zero if the request
+	// failed.
+	StatusCode int `json:"status_code,omitempty"`
+	// Err describes why the request failed, if it failed.
+	Err       string     `json:"error,omitempty"`
+	Redirects []Redirect `json:"redirects,omitempty"`
+	FinalURL  string     `json:"final_url,omitempty"`
+	// LastOK is the last time the link responded with a success status. Zero
+	// if never.
+	LastOK time.Time `json:"last_ok"`
+	// Snapshot is the path of the body of the last success response,
+	// relative to the archive dir. Empty if no snapshot.
+	Snapshot string `json:"snapshot,omitempty"`
+}
+
+// Dead returns true if the latest check found the page is gone.
+func (r ArchiveRecord) Dead() bool {
+	return isDead(r.StatusCode)
+}
+
+// Unreachable returns true if the latest check failed without a response.
+func (r ArchiveRecord) Unreachable() bool {
+	return r.Err != ""
+}
+
+// Moved returns true if the link permanently redirects to another URL.
+func (r ArchiveRecord) Moved() bool {
+	return isMoved(r.URL, r.FinalURL, r.Redirects)
+}
+
+// WaybackURL returns the Wayback Machine URL of the copy of the link nearest
+// to the last time the link was alive.
+func (r ArchiveRecord) WaybackURL() string {
+	if r.LastOK.IsZero() {
+		return "https://web.archive.org/web/" + r.URL
+	}
+	return "https://web.archive.org/web/" + r.LastOK.UTC().Format("20060102150405") + "/" + r.URL
+}
+
+// Archive stores the check history of links as JSON records in a dir, one
+// record per link, and a snapshot of each link while alive.
+type Archive struct {
+	dir string
+}
+
+func NewArchive(dir string) *Archive {
+	return &Archive{dir: dir}
+}
+
+// Path returns the path of the record for the link, whether or not the
+// record exists.
+func (a *Archive) Path(link string) string {
+	return filepath.Join(a.dir, fileStem(link)+".json")
+}
+
+// Get returns the record for the link. Returns false if the link was never
+// checked.
+func (a *Archive) Get(link string) (ArchiveRecord, bool, error) {
+	b, err := os.ReadFile(a.Path(link))
+	if errors.Is(err, os.ErrNotExist) {
+		return ArchiveRecord{}, false, nil
+	}
+	if err != nil {
+		return ArchiveRecord{}, false, fmt.Errorf("read link archive: %w", err)
+	}
+	r := ArchiveRecord{}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return ArchiveRecord{}, false, fmt.Errorf("decode link archive for %s: %w", link, err)
+	}
+	return r, true, nil
+}
+
+// Put records the check result and snapshots the page if the link is alive.
+// Keeps the last time alive and the snapshot of an earlier check if the link
+// is now dead. Returns the updated record.
+func (a *Archive) Put(c CheckResult) (ArchiveRecord, error) {
+	prev, _, err := a.Get(c.URL)
+	if err != nil {
+		return ArchiveRecord{}, err
+	}
+	r := ArchiveRecord{
+		URL:        c.URL,
+		CheckedAt:  c.Time,
+		StatusCode: c.StatusCode,
+		Err:        c.Err,
+		Redirects:  c.Redirects,
+		FinalURL:   c.FinalURL,
+		LastOK:     prev.LastOK,
+		Snapshot:   prev.Snapshot,
+	}
+	if c.OK() {
+		r.LastOK = c.Time
+	}
+	if c.OK() && len(c.Doc) > 0 {
+		r.Snapshot = filepath.ToSlash(filepath.Join(snapshotDir, fileStem(c.URL)+snapshotExt(c.MimeType)))
+		if err := os.MkdirAll(filepath.Join(a.dir, snapshotDir), 0o755); err != nil {
+			return ArchiveRecord{}, fmt.Errorf("make link snapshot dir: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(a.dir, filepath.FromSlash(r.Snapshot)), c.Doc, 0o644); err != nil {
+			return ArchiveRecord{}, fmt.Errorf("write link snapshot for %s: %w", c.URL, err)
+		}
+	}
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return ArchiveRecord{}, fmt.Errorf("encode link archive for %s: %w", c.URL, err)
+	}
+	if err := os.MkdirAll(a.dir, 0o755); err != nil {
+		return ArchiveRecord{}, fmt.Errorf("make link archive dir: %w", err)
+	}
+	if err := os.WriteFile(a.Path(c.URL), append(b, '\n'), 0o644); err != nil {
+		return ArchiveRecord{}, fmt.Errorf("write link archive for %s: %w", c.URL, err)
+	}
+	return r, nil
+}
+
+// snapshotExt returns the file extension for a snapshot with the MIME type.
+func snapshotExt(mimeType string) string {
+	switch mimeType {
+	case "text/html":
+		return ".html"
+	case "application/pdf":
+		return ".pdf"
+	}
+	if exts, _ := mime.ExtensionsByType(mimeType); len(exts) > 0 {
+		return exts[0]
+	}
+	return ".bin"
+}
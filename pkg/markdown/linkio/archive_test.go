@@ -0,0 +1,51 @@
+package linkio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestArchive_Put(t *testing.T) {
+	dir := t.TempDir()
+	a := NewArchive(dir)
+	const link = "https://example.com/paper"
+	alive := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	_, err := a.Put(CheckResult{URL: link, Time: alive, StatusCode: 200, FinalURL: link, MimeType: "text/html", Doc: []byte("<p>paper</p>")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A dead link keeps the last time alive and the snapshot.
+	dead := alive.AddDate(1, 0, 0)
+	if _, err := a.Put(CheckResult{URL: link, Time: dead, StatusCode: 404, FinalURL: link}); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := a.Get(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatalf("Get(%s) not found", link)
+	}
+	if !got.Dead() {
+		t.Errorf("record isn't dead: %+v", got)
+	}
+	if !got.CheckedAt.Equal(dead) {
+		t.Errorf("CheckedAt = %s; want %s", got.CheckedAt, dead)
+	}
+	if !got.LastOK.Equal(alive) {
+		t.Errorf("LastOK = %s; want %s", got.LastOK, alive)
+	}
+	snapshot, err := os.ReadFile(filepath.Join(dir, got.Snapshot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(snapshot) != "<p>paper</p>" {
+		t.Errorf("snapshot = %q; want the page from the last success", snapshot)
+	}
+	if want := "https://web.archive.org/web/20200102030405/" + link; got.WaybackURL() != want {
+		t.Errorf("WaybackURL() = %s; want %s", got.WaybackURL(), want)
+	}
+}
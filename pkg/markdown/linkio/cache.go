@@ -0,0 +1,77 @@
+package linkio
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Cache stores fetch results as JSON files in a checked-in dir, one file per
+// link, so that builds read link previews without network access.
+type Cache struct {
+	dir string
+}
+
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// Path returns the path of the cache file for the link, whether or not the
+// file exists. The name starts with the host so the dir is easy to browse,
+// like "en.wikipedia.org-1a2b3c4d5e6f7a8b.json".
+func (c *Cache) Path(link string) string {
+	return filepath.Join(c.dir, fileStem(link)+".json")
+}
+
+// fileStem returns a file name without an extension for the link, unique for
+// each link.
+func fileStem(link string) string {
+	host := "link"
+	if u, err := url.Parse(link); err == nil && u.Host != "" {
+		host = strings.ToLower(u.Host)
+	}
+	sum := sha256.Sum256([]byte(link))
+	return host + "-" + hex.EncodeToString(sum[:8])
+}
+
+// Get returns the cached result for the link. Returns false if the link isn't
+// cached.
+func (c *Cache) Get(link string) (FetchResult, bool, error) {
+	b, err := os.ReadFile(c.Path(link))
+	if errors.Is(err, os.ErrNotExist) {
+		return FetchResult{}, false, nil
+	}
+	if err != nil {
+		return FetchResult{}, false, fmt.Errorf("read link cache: %w", err)
+	}
+	r := FetchResult{}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return FetchResult{}, false, fmt.Errorf("decode link cache for %s: %w", link, err)
+	}
+	return r, true, nil
+}
+
+// Put writes the result to the cache, replacing any existing result for the
+// link.
+func (c *Cache) Put(r FetchResult) error {
+	if r.Path == "" {
+		return fmt.Errorf("put link cache: empty path")
+	}
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode link cache for %s: %w", r.Path, err)
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("make link cache dir: %w", err)
+	}
+	if err := os.WriteFile(c.Path(r.Path), append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write link cache for %s: %w", r.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package linkio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+)
+
+// maxRedirects is the most redirects followed when checking a link.
+const maxRedirects = 10
+
+// Redirect is a redirect followed while checking a link.
+type Redirect struct {
+	// URL is the URL that redirected.
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+}
+
+// IsPermanent returns true if the redirect means the link moved for good.
+func (r Redirect) IsPermanent() bool {
+	return r.StatusCode == http.StatusMovedPermanently || r.StatusCode == http.StatusPermanentRedirect
+}
+
+// CheckResult is the outcome of checking whether a link is alive.
+type CheckResult struct {
+	URL  string
+	Time time.Time
+	// StatusCode is the status of the final response. Zero if the request
+	// failed.
+	StatusCode int
+	// Err describes why the request failed, like a DNS or TLS error.
+	Err string
+	// Redirects are the redirects followed, in order.
+	Redirects []Redirect
+	// FinalURL is the URL after following redirects.
+	FinalURL string
+	// The MIME type and content of the final response, to snapshot the page.
+	MimeType string
+	Doc      []byte
+}
+
+// Checker checks whether a link is alive. A failed request is a result, not
+// an error, since a failed request says something about the link.
+type Checker interface {
+	Check(ctx context.Context, link string) CheckResult
+}
+
+// HTTPChecker checks links with GET requests, recording each redirect.
+type HTTPChecker struct {
+	Client *http.Client
+}
+
+func (h HTTPChecker) Check(ctx context.Context, link string) CheckResult {
+	r := CheckResult{URL: link, Time: time.Now().UTC()}
+	client := http.Client{}
+	if h.Client != nil {
+		client = *h.Client
+	}
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		r.Redirects = append(r.Redirects, Redirect{URL: via[len(via)-1].URL.String(), StatusCode: req.Response.StatusCode})
+		return nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		r.Err = err.Error()
+		return r
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		// Unwrap the *url.Error to avoid repeating the method and URL.
+		uErr := err
+		for errors.Unwrap(uErr) != nil {
+			uErr = errors.Unwrap(uErr)
+		}
+		r.Err = uErr.Error()
+		return r
+	}
+	defer resp.Body.Close()
+	r.StatusCode = resp.StatusCode
+	r.FinalURL = resp.Request.URL.String()
+	r.MimeType, _, _ = mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if r.OK() {
+		r.Doc, err = io.ReadAll(io.LimitReader(resp.Body, maxDocSize))
+		if err != nil {
+			r.Err = fmt.Sprintf("read body: %s", err)
+		}
+	}
+	return r
+}
+
+// OK returns true if the link responded with a success status.
+func (r CheckResult) OK() bool {
+	return isOK(r.Err, r.StatusCode)
+}
+
+// Dead returns true if the page is gone. Other error statuses, like 403 from
+// sites that block bots, and failed requests, like a timeout, don't mean the
+// link is dead.
+func (r CheckResult) Dead() bool {
+	return isDead(r.StatusCode)
+}
+
+// Unreachable returns true if the request failed without a response, like
+// from a DNS error or a timeout. Often temporary, so not a dead link.
+func (r CheckResult) Unreachable() bool {
+	return r.Err != ""
+}
+
+// Moved returns true if the link permanently redirects to another URL.
+func (r CheckResult) Moved() bool {
+	return isMoved(r.URL, r.FinalURL, r.Redirects)
+}
+
+func isOK(err string, status int) bool {
+	return err == "" && status >= 200 && status < 300
+}
+
+func isDead(status int) bool {
+	return status == http.StatusNotFound || status == http.StatusGone
+}
+
+func isMoved(link, final string, redirects []Redirect) bool {
+	if final == "" || final == link {
+		return false
+	}
+	for _, r := range redirects {
+		if r.IsPermanent() {
+			return true
+		}
+	}
+	return false
+}
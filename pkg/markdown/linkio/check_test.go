@@ -0,0 +1,85 @@
+package linkio
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func newLinkServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<p>ok</p>"))
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+	mux.HandleFunc("/forbidden", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/ok", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/ok", http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHTTPChecker_Check(t *testing.T) {
+	srv := newLinkServer(t)
+	tests := []struct {
+		path          string
+		wantStatus    int
+		wantRedirects []Redirect
+		wantOK        bool
+		wantDead      bool
+		wantMoved     bool
+	}{
+		{"/ok", 200, nil, true, false, false},
+		{"/missing", 404, nil, false, true, false},
+		{"/gone", 410, nil, false, true, false},
+		{"/forbidden", 403, nil, false, false, false},
+		{"/old", 200, []Redirect{{URL: srv.URL + "/old", StatusCode: 301}}, true, false, true},
+		{"/login", 200, []Redirect{{URL: srv.URL + "/login", StatusCode: 302}}, true, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got := HTTPChecker{Client: srv.Client()}.Check(context.Background(), srv.URL+tt.path)
+			if got.Err != "" {
+				t.Fatalf("Check error: %s", got.Err)
+			}
+			if got.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d; want %d", got.StatusCode, tt.wantStatus)
+			}
+			if diff := cmp.Diff(tt.wantRedirects, got.Redirects); diff != "" {
+				t.Errorf("Redirects mismatch (-want +got):\n%s", diff)
+			}
+			if got.OK() != tt.wantOK || got.Dead() != tt.wantDead || got.Moved() != tt.wantMoved {
+				t.Errorf("OK, Dead, Moved = %t, %t, %t; want %t, %t, %t",
+					got.OK(), got.Dead(), got.Moved(), tt.wantOK, tt.wantDead, tt.wantMoved)
+			}
+		})
+	}
+}
+
+func TestHTTPChecker_Check_unreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	link := srv.URL + "/page"
+	srv.Close()
+
+	got := HTTPChecker{}.Check(context.Background(), link)
+	if got.Err == "" {
+		t.Fatalf("Check of closed server has no error: %+v", got)
+	}
+	if !got.Unreachable() || got.Dead() {
+		t.Errorf("Check of closed server: Unreachable, Dead = %t, %t; want true, false", got.Unreachable(), got.Dead())
+	}
+}
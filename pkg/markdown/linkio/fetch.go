@@ -1,41 +1,218 @@
 package linkio
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"html"
+	"io"
+	"mime"
 	"net/http"
-	"path"
+	"net/url"
+	"strings"
 	"time"
+
+	xhtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
 )
 
+// userAgent identifies requests from the site tools.
+const userAgent = "jsc-linkio/1.0 (+https://joe.schafer.dev)"
+
+// maxDocSize is the most bytes read from a fetched doc. Page metadata is in the
+// head, so a truncated body still has it.
+const maxDocSize = 1 << 20
+
 type FetchResult struct {
 	// The link path that provided the result.
-	Path string
+	Path string `json:"path"`
 	// The time the result was fetched.
-	Time time.Time
+	Time time.Time `json:"time"`
 	// The MIME type of the fetched doc.
-	MimeType string
-	// The raw document content for this fetch.
-	Doc []byte
+	MimeType string `json:"mime_type"`
+	// The raw document content for this fetch. Not cached since only the
+	// title and snippet are shown.
+	Doc []byte `json:"-"`
+	// Title is the plain text title of the linked page.
+	Title string `json:"title"`
+	// SnippetHTML is the HTML summary of the linked page, like the first
+	// paragraph of a Wikipedia article.
+	SnippetHTML string `json:"snippet_html"`
 }
 
 type Fetcher interface {
-	Fetch(link string) (FetchResult, error)
+	Fetch(ctx context.Context, link string) (FetchResult, error)
+}
+
+// get sends a GET request for the URL and returns the response body, limited
+// to maxDocSize, and the MIME type.
+func get(ctx context.Context, client *http.Client, u string) ([]byte, string, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("GET %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("GET %s: status %s", u, resp.Status)
+	}
+	doc, err := io.ReadAll(io.LimitReader(resp.Body, maxDocSize))
+	if err != nil {
+		return nil, "", fmt.Errorf("read body of %s: %w", u, err)
+	}
+	mimeType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return doc, mimeType, nil
 }
 
 // WikiSummaryFetcher fetches link summaries from Wikipedia.
-type WikiSummaryFetcher struct{}
+type WikiSummaryFetcher struct {
+	Client *http.Client
+	// BaseURL is the URL of the page summary API. Defaults to the English
+	// Wikipedia REST API.
+	BaseURL string
+}
+
+// wikiSummary is the subset of the Wikipedia page summary response used for
+// previews.
+type wikiSummary struct {
+	Title       string `json:"title"`
+	ExtractHTML string `json:"extract_html"`
+}
 
-func (w WikiSummaryFetcher) Fetch(link string) (FetchResult, error) {
-	const url = "https://en.wikipedia.org/api/rest_v1/page/summary/"
-	name := path.Base(link)
-	if name == "" {
-		return FetchResult{}, fmt.Errorf("no base name for link %s", link)
+func (w WikiSummaryFetcher) Fetch(ctx context.Context, link string) (FetchResult, error) {
+	baseURL := w.BaseURL
+	if baseURL == "" {
+		baseURL = "https://en.wikipedia.org/api/rest_v1/page/summary/"
 	}
-	resp, err := http.Get(path.Join(url, name))
+	u, err := url.Parse(link)
 	if err != nil {
-		return FetchResult{}, fmt.Errorf("wiki summary fetcher GET: %w", err)
+		return FetchResult{}, fmt.Errorf("parse wiki link %s: %w", link, err)
 	}
-	defer resp.Body.Close()
+	// Titles may contain a slash, like "AC/DC", so take the whole path after
+	// "/wiki/" and escape the slashes.
+	title, ok := strings.CutPrefix(u.Path, "/wiki/")
+	if !ok || title == "" {
+		return FetchResult{}, fmt.Errorf("no wiki title for link %s", link)
+	}
+	summaryURL, err := url.JoinPath(baseURL, url.PathEscape(title))
+	if err != nil {
+		return FetchResult{}, fmt.Errorf("wiki summary URL for %s: %w", link, err)
+	}
+	doc, mimeType, err := get(ctx, w.Client, summaryURL)
+	if err != nil {
+		return FetchResult{}, fmt.Errorf("wiki summary fetcher: %w", err)
+	}
+	summary := wikiSummary{}
+	if err := json.Unmarshal(doc, &summary); err != nil {
+		return FetchResult{}, fmt.Errorf("decode wiki summary for %s: %w", link, err)
+	}
+	if summary.ExtractHTML == "" {
+		return FetchResult{}, fmt.Errorf("empty wiki summary for %s", link)
+	}
+	return FetchResult{
+		Path:        link,
+		Time:        time.Now().UTC(),
+		MimeType:    mimeType,
+		Doc:         doc,
+		Title:       summary.Title,
+		SnippetHTML: strings.TrimSpace(summary.ExtractHTML),
+	}, nil
+}
+
+// PageMetaFetcher fetches link summaries from the metadata of an HTML page,
+// preferring the OpenGraph title and description over the <title> and
+// <meta name="description">.
+type PageMetaFetcher struct {
+	Client *http.Client
+}
+
+func (p PageMetaFetcher) Fetch(ctx context.Context, link string) (FetchResult, error) {
+	doc, mimeType, err := get(ctx, p.Client, link)
+	if err != nil {
+		return FetchResult{}, fmt.Errorf("page meta fetcher: %w", err)
+	}
+	if mimeType != "text/html" && mimeType != "application/xhtml+xml" {
+		return FetchResult{}, fmt.Errorf("page meta fetcher: unsupported MIME type %q for %s", mimeType, link)
+	}
+	root, err := xhtml.Parse(strings.NewReader(string(doc)))
+	if err != nil {
+		return FetchResult{}, fmt.Errorf("parse HTML of %s: %w", link, err)
+	}
+	meta := readPageMeta(root)
+	if meta.desc == "" {
+		return FetchResult{}, fmt.Errorf("page meta fetcher: no description for %s", link)
+	}
+	return FetchResult{
+		Path:        link,
+		Time:        time.Now().UTC(),
+		MimeType:    mimeType,
+		Doc:         doc,
+		Title:       meta.title,
+		SnippetHTML: "<p>" + html.EscapeString(meta.desc) + "</p>",
+	}, nil
+}
 
-	panic("implement me")
+// pageMeta is the title and description of an HTML page.
+type pageMeta struct {
+	title string
+	desc  string
+}
+
+// readPageMeta reads the title and description from the head of an HTML
+// page. OpenGraph properties take precedence.
+func readPageMeta(root *xhtml.Node) pageMeta {
+	var title, ogTitle, desc, ogDesc string
+	var walk func(n *xhtml.Node)
+	walk = func(n *xhtml.Node) {
+		if n.Type == xhtml.ElementNode {
+			switch n.DataAtom {
+			case atom.Title:
+				if title == "" && n.FirstChild != nil {
+					title = n.FirstChild.Data
+				}
+			case atom.Meta:
+				content := attr(n, "content")
+				switch {
+				case attr(n, "property") == "og:title":
+					ogTitle = content
+				case attr(n, "property") == "og:description":
+					ogDesc = content
+				case strings.EqualFold(attr(n, "name"), "description"):
+					desc = content
+				}
+			case atom.Body:
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+	m := pageMeta{title: strings.TrimSpace(title), desc: strings.TrimSpace(desc)}
+	if s := strings.TrimSpace(ogTitle); s != "" {
+		m.title = s
+	}
+	if s := strings.TrimSpace(ogDesc); s != "" {
+		m.desc = s
+	}
+	return m
+}
+
+// attr returns the value of the attribute with the key, or empty if the node
+// doesn't have the attribute.
+func attr(n *xhtml.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
 }
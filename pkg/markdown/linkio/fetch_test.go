@@ -0,0 +1,110 @@
+package linkio
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWikiSummaryFetcher_Fetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		switch r.URL.EscapedPath() {
+		case "/page/summary/Inverted_index":
+			_, _ = w.Write([]byte(`{"title":"Inverted index","extract_html":"<p>An <b>inverted index</b> maps content to locations.</p>\n"}`))
+		case "/page/summary/AC%2FDC":
+			_, _ = w.Write([]byte(`{"title":"AC/DC","extract_html":"<p><b>AC/DC</b> are a rock band.</p>"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	f := WikiSummaryFetcher{Client: srv.Client(), BaseURL: srv.URL + "/page/summary/"}
+	got, err := f.Fetch(context.Background(), "https://en.wikipedia.org/wiki/Inverted_index")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Inverted index"; got.Title != want {
+		t.Errorf("Title = %q; want %q", got.Title, want)
+	}
+	if want := "<p>An <b>inverted index</b> maps content to locations.</p>"; got.SnippetHTML != want {
+		t.Errorf("SnippetHTML = %q; want %q", got.SnippetHTML, want)
+	}
+	if want := "application/json"; got.MimeType != want {
+		t.Errorf("MimeType = %q; want %q", got.MimeType, want)
+	}
+
+	slash, err := f.Fetch(context.Background(), "https://en.wikipedia.org/wiki/AC/DC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "AC/DC"; slash.Title != want {
+		t.Errorf("Title = %q; want %q", slash.Title, want)
+	}
+
+	if _, err := f.Fetch(context.Background(), "https://en.wikipedia.org/wiki/Missing"); err == nil {
+		t.Error("Fetch of missing article succeeded; want error")
+	}
+	if _, err := f.Fetch(context.Background(), "https://en.wikipedia.org/w/index.php"); err == nil {
+		t.Error("Fetch of non-article link succeeded; want error")
+	}
+}
+
+func TestPageMetaFetcher_Fetch(t *testing.T) {
+	tests := []struct {
+		name      string
+		head      string
+		wantTitle string
+		wantHTML  string
+	}{
+		{
+			"meta description",
+			`<title>Plain title</title><meta name="description" content="A plain description.">`,
+			"Plain title",
+			"<p>A plain description.</p>",
+		},
+		{
+			"prefer OpenGraph",
+			`<title>Plain title</title>
+			 <meta name="description" content="A plain description.">
+			 <meta property="og:title" content="OG title">
+			 <meta property="og:description" content="An OG description &amp; more.">`,
+			"OG title",
+			"<p>An OG description &amp; more.</p>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				_, _ = w.Write([]byte("<!doctype html><html><head>" + tt.head + "</head><body><p>body</p></body></html>"))
+			}))
+			defer srv.Close()
+
+			got, err := PageMetaFetcher{Client: srv.Client()}.Fetch(context.Background(), srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Title != tt.wantTitle {
+				t.Errorf("Title = %q; want %q", got.Title, tt.wantTitle)
+			}
+			if got.SnippetHTML != tt.wantHTML {
+				t.Errorf("SnippetHTML = %q; want %q", got.SnippetHTML, tt.wantHTML)
+			}
+		})
+	}
+}
+
+func TestPageMetaFetcher_Fetch_noDescription(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html><head><title>Title only</title></head></html>"))
+	}))
+	defer srv.Close()
+
+	if _, err := (PageMetaFetcher{Client: srv.Client()}).Fetch(context.Background(), srv.URL); err == nil {
+		t.Error("Fetch of page without description succeeded; want error")
+	}
+}
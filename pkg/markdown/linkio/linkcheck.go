@@ -0,0 +1,91 @@
+package linkio
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jschaf/jsc/pkg/markdown/diag"
+	"github.com/yuin/goldmark/ast"
+)
+
+// waybackPrefix is the prefix of links to archived copies. Archived copies
+// aren't checked since the archive outlives the original.
+const waybackPrefix = "https://web.archive.org/"
+
+// ExternalLink is an http link in a post.
+type ExternalLink struct {
+	URL string
+	// Offset is the byte offset of the link in the post source.
+	Offset int
+}
+
+// ExternalLinks returns the http links in the node in document order,
+// including autolinks. Skips links to archived copies.
+func ExternalLinks(node ast.Node, src []byte) []ExternalLink {
+	var links []ExternalLink
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		var dest string
+		offset := diag.NodeOffset(n)
+		switch n := n.(type) {
+		case *ast.Link:
+			dest = string(n.Destination)
+		case *ast.AutoLink:
+			dest = string(n.URL(src))
+			// An autolink has no segment, so NodeOffset is the start of the
+			// block. Find the URL after the start.
+			if offset >= 0 {
+				if i := bytes.Index(src[offset:], []byte("<"+dest)); i >= 0 {
+					offset += i
+				}
+			}
+		default:
+			return ast.WalkContinue, nil
+		}
+		if IsPreviewable(dest) && !strings.HasPrefix(dest, waybackPrefix) {
+			links = append(links, ExternalLink{URL: dest, Offset: offset})
+		}
+		return ast.WalkSkipChildren, nil
+	})
+	return links
+}
+
+// Diagnose returns an error for each dead link and a warning for each
+// unreachable link, moved link or link with an error status. Links without a
+// record aren't diagnosed.
+func Diagnose(links []ExternalLink, records map[string]ArchiveRecord) diag.List {
+	var diags diag.List
+	for _, l := range links {
+		r, ok := records[l.URL]
+		if !ok {
+			continue
+		}
+		switch {
+		case r.Unreachable():
+			diags = append(diags, warnf(l.Offset, "unreachable link %s: %s", l.URL, r.Err))
+		case r.Dead():
+			diags = append(diags, diag.Errorf("linkcheck", l.Offset, "dead link %s: %s", l.URL, statusText(r.StatusCode)))
+		case r.Moved():
+			diags = append(diags, warnf(l.Offset, "moved link %s: permanently redirects to %s", l.URL, r.FinalURL))
+		case !isOK(r.Err, r.StatusCode):
+			diags = append(diags, warnf(l.Offset, "link %s: %s", l.URL, statusText(r.StatusCode)))
+		}
+	}
+	return diags
+}
+
+func warnf(offset int, format string, args ...any) diag.Diagnostic {
+	d := diag.Errorf("linkcheck", offset, format, args...)
+	d.Severity = diag.SeverityWarning
+	return d
+}
+
+// statusText returns the status code with its text, like "status 404 Not
+// Found".
+func statusText(code int) string {
+	return strings.TrimSpace(fmt.Sprintf("status %d %s", code, http.StatusText(code)))
+}
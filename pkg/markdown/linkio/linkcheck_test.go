@@ -0,0 +1,72 @@
+package linkio
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jschaf/jsc/pkg/markdown/diag"
+	"github.com/jschaf/jsc/pkg/texts"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestDiagnose(t *testing.T) {
+	src := []byte(texts.Dedent(`
+		See [the paper](https://example.com/dead) and <https://example.com/moved>.
+
+		Also [a post](/other-post/), [a site](https://example.com/ok) and
+		[a copy](https://web.archive.org/web/2020/https://example.com/dead).
+
+		Blocked by [a site](https://example.com/forbidden). Timed out [a host](https://down.example.com/).
+	`))
+	doc := goldmark.New().Parser().Parse(text.NewReader(src))
+	links := ExternalLinks(doc, src)
+
+	var urls []string
+	for _, l := range links {
+		urls = append(urls, l.URL)
+	}
+	wantURLs := []string{
+		"https://example.com/dead",
+		"https://example.com/moved",
+		"https://example.com/ok",
+		"https://example.com/forbidden",
+		"https://down.example.com/",
+	}
+	if diff := cmp.Diff(wantURLs, urls); diff != "" {
+		t.Fatalf("ExternalLinks mismatch (-want +got):\n%s", diff)
+	}
+
+	records := map[string]ArchiveRecord{
+		"https://example.com/dead": {URL: "https://example.com/dead", StatusCode: 404},
+		"https://example.com/moved": {
+			URL:        "https://example.com/moved",
+			StatusCode: 200,
+			Redirects:  []Redirect{{URL: "https://example.com/moved", StatusCode: 301}},
+			FinalURL:   "https://example.com/new",
+		},
+		"https://example.com/ok":        {URL: "https://example.com/ok", StatusCode: 200},
+		"https://example.com/forbidden": {URL: "https://example.com/forbidden", StatusCode: 403},
+		"https://down.example.com/":     {URL: "https://down.example.com/", Err: "i/o timeout"},
+	}
+	diags := Diagnose(links, records)
+	for i := range diags {
+		diags[i].Resolve("post.md", src)
+	}
+	var got []string
+	for _, d := range diags {
+		got = append(got, d.Severity.String()+" "+d.Error())
+	}
+	want := []string{
+		"error post.md:1:6: linkcheck: dead link https://example.com/dead: status 404 Not Found",
+		"warning post.md:1:47: linkcheck: moved link https://example.com/moved: permanently redirects to https://example.com/new",
+		"warning post.md:6:13: linkcheck: link https://example.com/forbidden: status 403 Forbidden",
+		"warning post.md:6:64: linkcheck: unreachable link https://down.example.com/: i/o timeout",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Diagnose mismatch (-want +got):\n%s", diff)
+	}
+	if diags.Count(diag.SeverityError) != 1 {
+		t.Errorf("error count = %d; want 1", diags.Count(diag.SeverityError))
+	}
+}
@@ -1,4 +1,74 @@
 package linkio
 
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
 // Snippeter fetches snippets for linked content in a Markdown source file.
-type Snippeter struct{}
+// Reads the cache before fetching and caches each fetched snippet.
+type Snippeter struct {
+	Cache *Cache
+	// Wiki fetches snippets for Wikipedia links.
+	Wiki Fetcher
+	// Page fetches snippets for all other links.
+	Page Fetcher
+}
+
+// NewSnippeter creates a snippeter that fetches with the client and caches
+// results in the cache.
+func NewSnippeter(cache *Cache, client *http.Client) *Snippeter {
+	return &Snippeter{
+		Cache: cache,
+		Wiki:  WikiSummaryFetcher{Client: client},
+		Page:  PageMetaFetcher{Client: client},
+	}
+}
+
+// IsPreviewable returns true if the link is an absolute http or https URL,
+// the only links with a fetched preview.
+func IsPreviewable(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// isWikipedia returns true if the link is to a Wikipedia article.
+func isWikipedia(link string) bool {
+	return strings.HasPrefix(link, "https://en.wikipedia.org/wiki/")
+}
+
+// Snippet returns the cached snippet for the link, fetching and caching the
+// snippet if not cached. If refresh is true, fetches the snippet even if
+// cached.
+func (s *Snippeter) Snippet(ctx context.Context, link string, refresh bool) (FetchResult, error) {
+	if !IsPreviewable(link) {
+		return FetchResult{}, fmt.Errorf("snippet for %s: not an http link", link)
+	}
+	if !refresh {
+		r, ok, err := s.Cache.Get(link)
+		if err != nil {
+			return FetchResult{}, err
+		}
+		if ok {
+			return r, nil
+		}
+	}
+	f := s.Page
+	if isWikipedia(link) {
+		f = s.Wiki
+	}
+	r, err := f.Fetch(ctx, link)
+	if err != nil {
+		return FetchResult{}, fmt.Errorf("snippet for %s: %w", link, err)
+	}
+	if err := s.Cache.Put(r); err != nil {
+		return FetchResult{}, err
+	}
+	return r, nil
+}
@@ -0,0 +1,74 @@
+package linkio
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeFetcher returns a result with the title and counts fetches.
+type fakeFetcher struct {
+	title   string
+	fetches int
+}
+
+func (f *fakeFetcher) Fetch(_ context.Context, link string) (FetchResult, error) {
+	f.fetches++
+	return FetchResult{Path: link, Title: f.title, SnippetHTML: "<p>snippet</p>"}, nil
+}
+
+func TestSnippeter_Snippet(t *testing.T) {
+	ctx := context.Background()
+	wiki, page := &fakeFetcher{title: "wiki"}, &fakeFetcher{title: "page"}
+	s := &Snippeter{Cache: NewCache(t.TempDir()), Wiki: wiki, Page: page}
+
+	const wikiLink = "https://en.wikipedia.org/wiki/Go"
+	got, err := s.Snippet(ctx, wikiLink, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "wiki" {
+		t.Errorf("Snippet(%s).Title = %q; want wiki", wikiLink, got.Title)
+	}
+	if _, err := s.Snippet(ctx, "https://example.com/post", false); err != nil {
+		t.Fatal(err)
+	}
+	if page.fetches != 1 {
+		t.Errorf("page fetches = %d; want 1", page.fetches)
+	}
+
+	// Read the cache before fetching.
+	cached, err := s.Snippet(ctx, wikiLink, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, cached); diff != "" {
+		t.Errorf("cached snippet mismatch (-want +got):\n%s", diff)
+	}
+	if wiki.fetches != 1 {
+		t.Errorf("wiki fetches after cache hit = %d; want 1", wiki.fetches)
+	}
+
+	// Refresh fetches even if cached.
+	if _, err := s.Snippet(ctx, wikiLink, true); err != nil {
+		t.Fatal(err)
+	}
+	if wiki.fetches != 2 {
+		t.Errorf("wiki fetches after refresh = %d; want 2", wiki.fetches)
+	}
+
+	if _, err := s.Snippet(ctx, "/relative", false); err == nil {
+		t.Error("Snippet of relative link succeeded; want error")
+	}
+}
+
+func TestCache_Get_missing(t *testing.T) {
+	_, ok, err := NewCache(t.TempDir()).Get("https://example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Error("Get of uncached link returned ok")
+	}
+}
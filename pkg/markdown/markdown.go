@@ -2,17 +2,22 @@ package markdown
 
 import (
 	"fmt"
-	"github.com/yuin/goldmark/renderer/html"
 	"io"
+	"time"
 
+	"github.com/jschaf/bibtex"
 	"github.com/jschaf/jsc/pkg/cite"
+	"github.com/jschaf/jsc/pkg/git"
 	"github.com/jschaf/jsc/pkg/markdown/assets"
+	"github.com/jschaf/jsc/pkg/markdown/diag"
+	"github.com/jschaf/jsc/pkg/markdown/linkio"
 	"github.com/jschaf/jsc/pkg/markdown/mdctx"
 	"github.com/jschaf/jsc/pkg/markdown/mdext"
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
 	"github.com/yuin/goldmark/text"
 )
 
@@ -24,6 +29,47 @@ type AST struct {
 	// The full path to the Markdown file that this AST represents.
 	Path     string
 	Features *mdctx.FeatureSet
+	// Deps are the full paths of files, besides Path, used to build the AST,
+	// like bibtex files and embedded files.
+	Deps []string
+	// Warnings are the diagnostics found while parsing that don't fail the
+	// build.
+	Warnings diag.List
+	Stats    Stats
+	// History is the commits that changed the post, newest first. Empty if the
+	// post isn't committed or git isn't available.
+	History []git.Commit
+	// CiteKeys are the distinct BibTeX keys cited by the post, sorted.
+	CiteKeys []bibtex.CiteKey
+	// Links are the distinct URL paths of the site-relative links in the post,
+	// like "/foo", sorted. Paths don't have a query, fragment or trailing
+	// slash.
+	Links []string
+	// HeadingIDs are the IDs of the headings in the post, the targets of
+	// links to a section.
+	HeadingIDs map[string]struct{}
+}
+
+// LastModified returns the latest of the publish date, the updated date from
+// the front matter and the time of the latest commit that changed the post.
+func (a *AST) LastModified() time.Time {
+	t := a.Meta.Date
+	if a.Meta.Updated.After(t) {
+		t = a.Meta.Updated
+	}
+	if len(a.History) > 0 && a.History[0].Time.After(t) {
+		t = a.History[0].Time
+	}
+	return t
+}
+
+// Revision returns the hash of the latest commit that changed the post, or
+// empty if the post has no history.
+func (a *AST) Revision() string {
+	if len(a.History) == 0 {
+		return ""
+	}
+	return a.History[0].Hash
 }
 
 // Options are global configuration options for parsing and rendering Markdown.
@@ -34,6 +80,18 @@ type Options struct {
 	TOCStyle           mdext.TOCStyle
 	Extenders          []goldmark.Extender
 	HeadingAnchorStyle mdext.HeadingAnchorStyle
+	// LinkCache holds the fetched previews of links. Defaults to only showing
+	// previews from "::: preview" colon blocks.
+	LinkCache *linkio.Cache
+	// LinkArchive holds the check history of links. Defaults to never
+	// linking to archived copies of dead links.
+	LinkArchive *linkio.Archive
+	// PostStats returns the stats shown in the article header of each post.
+	// Defaults to showing no stats.
+	PostStats mdext.PostStatsFunc
+	// Now is the build clock. Posts with a publish_at after Now parse as
+	// drafts. Defaults to the zero time, which ignores publish_at.
+	Now time.Time
 }
 
 type Markdown struct {
@@ -59,6 +117,38 @@ func WithHeadingAnchorStyle(s mdext.HeadingAnchorStyle) Option {
 	}
 }
 
+// WithLinkCache shows the cached preview of links without a "::: preview"
+// colon block.
+func WithLinkCache(c *linkio.Cache) Option {
+	return func(m *Markdown) {
+		m.opts.LinkCache = c
+	}
+}
+
+// WithLinkArchive links to an archived copy of each link that the archive
+// marks as dead.
+func WithLinkArchive(a *linkio.Archive) Option {
+	return func(m *Markdown) {
+		m.opts.LinkArchive = a
+	}
+}
+
+// WithPostStats shows the reading time and last modified date returned by
+// stats in the article header.
+func WithPostStats(stats mdext.PostStatsFunc) Option {
+	return func(m *Markdown) {
+		m.opts.PostStats = stats
+	}
+}
+
+// WithNow parses posts scheduled to go live after now as drafts, so their
+// pages and assets live under the drafts dir.
+func WithNow(now time.Time) Option {
+	return func(m *Markdown) {
+		m.opts.Now = now
+	}
+}
+
 func WithExtender(e goldmark.Extender) Option {
 	parser.WithAutoHeadingID()
 	return func(m *Markdown) {
@@ -69,6 +159,8 @@ func WithExtender(e goldmark.Extender) Option {
 func defaultExtensions(opts Options) []goldmark.Extender {
 	return []goldmark.Extender{
 		mdext.NewArticleExt(),
+		mdext.NewAttributesExt(),
+		mdext.NewChangelogExt(),
 		mdext.NewCodeBlockExt(),
 		mdext.NewColonBlockExt(),
 		mdext.NewColonLineExt(),
@@ -80,14 +172,16 @@ func defaultExtensions(opts Options) []goldmark.Extender {
 		mdext.NewHeadingIDExt(),
 		mdext.NewImageExt(),
 		mdext.NewKatexExt(),
-		mdext.NewLinkExt(),
+		mdext.NewLinkExt(opts.LinkCache, opts.LinkArchive),
 		mdext.NewParagraphExt(),
+		mdext.NewPostStatsExt(opts.PostStats),
 		mdext.NewSmallCapsExt(),
 		mdext.NewTableExt(),
 		mdext.NewTOCExt(opts.TOCStyle),
 		mdext.NewTOMLExt(),
 		mdext.NewTimeExt(),
 		mdext.NewTypographyExt(),
+		mdext.NewWikiLinkExt(),
 		mdext.NewFigureExt(), // TODO: must come last, why?
 	}
 }
@@ -115,33 +209,46 @@ func New(opts ...Option) *Markdown {
 	return m
 }
 
-func (m *Markdown) Parse(path string, r io.Reader) (*AST, error) {
+// Parse parses the Markdown in r. Returns a diag.List error if any extension
+// reported an error.
+func (m *Markdown) Parse(path string, r io.Reader) (_ *AST, mErr error) {
+	defer recoverPanic(&mErr, path)
 	bs, err := io.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
 	ctx := parser.NewContext()
 	mdctx.SetFilePath(ctx, path)
+	mdctx.SetNow(ctx, m.opts.Now)
 	mdctx.SetRenderer(ctx, m.gm.Renderer())
 
 	node := m.gm.Parser().Parse(text.NewReader(bs), parser.WithContext(ctx))
-	if parseErrs := mdctx.PopErrors(ctx); len(parseErrs) == 1 {
-		return nil, fmt.Errorf("parse errors in context: %w", parseErrs[0])
-	} else if len(parseErrs) > 1 {
-		return nil, fmt.Errorf("parse errors in context: %v", parseErrs)
+	diags := mdctx.PopDiagnostics(ctx)
+	for i := range diags {
+		diags[i].Resolve(path, bs)
+	}
+	if err := diags.Err(); err != nil {
+		return nil, err
 	}
 	meta := mdext.GetTOMLMeta(ctx)
 	meta.Title = mdctx.GetTitle(ctx).Text
 	meta.TitleNode = mdctx.GetTitle(ctx).Node
 	mdAssets := mdctx.GetAssets(ctx)
 	mdFeats := mdctx.GetFeatures(ctx)
+	cites, links := collectRefs(node, meta.PageURL(), meta.Path)
 	return &AST{
-		Node:     node,
-		Meta:     meta,
-		Assets:   mdAssets,
-		Path:     path,
-		Source:   bs,
-		Features: mdFeats,
+		Node:       node,
+		Meta:       meta,
+		Assets:     mdAssets,
+		Path:       path,
+		Source:     bs,
+		Features:   mdFeats,
+		Deps:       mdctx.GetDependencies(ctx),
+		Warnings:   diags,
+		Stats:      computeStats(node, bs),
+		CiteKeys:   cites,
+		Links:      links,
+		HeadingIDs: mdctx.HeadingIDs(ctx),
 	}, nil
 }
 
@@ -150,6 +257,21 @@ func (m *Markdown) Renderer() renderer.Renderer {
 	return m.gm.Renderer()
 }
 
-func (m *Markdown) Render(w io.Writer, source []byte, p *AST) error {
+func (m *Markdown) Render(w io.Writer, source []byte, p *AST) (mErr error) {
+	defer recoverPanic(&mErr, p.Path)
 	return m.Renderer().Render(w, source, p.Node)
 }
+
+// recoverPanic converts a panic from an extension into a diagnostic so that a
+// bad post fails the build instead of crashing the process, like the dev
+// server. Extensions should report errors with mdctx instead of panicking.
+func recoverPanic(errp *error, path string) {
+	if r := recover(); r != nil {
+		*errp = diag.Diagnostic{
+			Path:     path,
+			Offset:   diag.NoOffset,
+			Severity: diag.SeverityError,
+			Err:      fmt.Errorf("panic: %v", r),
+		}
+	}
+}
@@ -2,12 +2,15 @@ package markdown
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
 
 	"github.com/jschaf/jsc/pkg/htmls"
+	"github.com/jschaf/jsc/pkg/markdown/diag"
 	"github.com/jschaf/jsc/pkg/markdown/mdext"
+	"github.com/jschaf/jsc/pkg/testing/difftest"
 	"github.com/jschaf/jsc/pkg/texts"
 )
 
@@ -90,3 +93,27 @@ func articleHTML(meta mdext.PostMeta, title, text string) string {
 	b.WriteString("\n</article>\n")
 	return b.String()
 }
+
+func TestParse_Diagnostics(t *testing.T) {
+	src := texts.Dedent(`
+    # title
+
+    alpha [^side:foo]
+
+    bravo [^side:bar]
+  `)
+	_, err := New().Parse("post.md", strings.NewReader(src))
+	var got diag.List
+	if !errors.As(err, &got) {
+		t.Fatalf("Parse error: want diag.List; got %v", err)
+	}
+	want := []string{
+		`post.md:3:1: footnote: no footnote body for footnote link "side:foo"`,
+		`post.md:5:1: footnote: no footnote body for footnote link "side:bar"`,
+	}
+	gotMsgs := make([]string, len(got))
+	for i, d := range got {
+		gotMsgs[i] = d.Error()
+	}
+	difftest.AssertSame(t, want, gotMsgs)
+}
@@ -1,7 +1,11 @@
 package mdctx
 
 import (
+	"errors"
+	"time"
+
 	"github.com/jschaf/jsc/pkg/markdown/assets"
+	"github.com/jschaf/jsc/pkg/markdown/diag"
 	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/renderer"
@@ -9,21 +13,49 @@ import (
 
 var errorsCtxKey = parser.NewContextKey()
 
+// PushError adds an error without a known source position. Prefer
+// PushErrorAt, which points the author at the offending source.
 func PushError(pc parser.Context, err error) {
-	var errs []error
-	if e := pc.Get(errorsCtxKey); e != nil {
-		errs = e.([]error)
+	var d diag.Diagnostic
+	if !errors.As(err, &d) {
+		d = diag.Diagnostic{Offset: diag.NoOffset, Severity: diag.SeverityError, Err: err}
 	}
-	errs = append(errs, err)
-	pc.Set(errorsCtxKey, errs)
+	PushDiagnostic(pc, d)
+}
+
+// PushErrorAt adds an error found by the extension named ext at the byte
+// offset in the source. Use diag.NodeOffset to get the offset of a node.
+func PushErrorAt(pc parser.Context, ext string, offset int, err error) {
+	PushDiagnostic(pc, diag.Diagnostic{Offset: offset, Severity: diag.SeverityError, Ext: ext, Err: err})
+}
+
+// PushWarningAt adds a warning found by the extension named ext at the byte
+// offset in the source. Warnings don't fail the build.
+func PushWarningAt(pc parser.Context, ext string, offset int, err error) {
+	PushDiagnostic(pc, diag.Diagnostic{Offset: offset, Severity: diag.SeverityWarning, Ext: ext, Err: err})
+}
+
+// PushDiagnostic adds a diagnostic to report after parsing.
+func PushDiagnostic(pc parser.Context, d diag.Diagnostic) {
+	ds, _ := pc.Get(errorsCtxKey).(diag.List)
+	pc.Set(errorsCtxKey, append(ds, d))
+}
+
+// PopDiagnostics returns and clears all diagnostics.
+func PopDiagnostics(pc parser.Context) diag.List {
+	ds, _ := pc.Get(errorsCtxKey).(diag.List)
+	pc.Set(errorsCtxKey, nil)
+	return ds
 }
 
+// PopErrors returns and clears all diagnostics with error severity.
 func PopErrors(pc parser.Context) []error {
 	var errs []error
-	if e := pc.Get(errorsCtxKey); e != nil {
-		errs = e.([]error)
+	for _, d := range PopDiagnostics(pc) {
+		if d.Severity == diag.SeverityError {
+			errs = append(errs, d)
+		}
 	}
-	pc.Set(errorsCtxKey, nil)
 	return errs
 }
 
@@ -102,6 +134,20 @@ func SetFilePath(pc parser.Context, val string) {
 	pc.Set(filePathCtxKey, val)
 }
 
+var nowCtxKey = parser.NewContextKey()
+
+// GetNow returns the build clock of the parse or the zero time if unset.
+// Posts scheduled to go live after now are parsed as drafts.
+func GetNow(pc parser.Context) time.Time {
+	t, _ := pc.Get(nowCtxKey).(time.Time)
+	return t
+}
+
+// SetNow sets the build clock of the parse.
+func SetNow(pc parser.Context, t time.Time) {
+	pc.Set(nowCtxKey, t)
+}
+
 var rendererCtxKey = parser.NewContextKey()
 
 // GetRenderer returns the main goldmark renderer or nil if none exists. Useful
@@ -134,3 +180,19 @@ func HeadingIDs(pc parser.Context) map[string]struct{} {
 	}
 	return rawIDs.(map[string]struct{})
 }
+
+var depsCtxKey = parser.NewContextKey()
+
+// AddDependency records a file, other than the main Markdown file, whose
+// contents affect the rendered output, like a bibtex file or an embedded HTML
+// file. Incremental builds rebuild a post if any dependency changes.
+func AddDependency(pc parser.Context, path string) {
+	deps, _ := pc.Get(depsCtxKey).([]string)
+	pc.Set(depsCtxKey, append(deps, path))
+}
+
+// GetDependencies returns all dependencies added with AddDependency.
+func GetDependencies(pc parser.Context) []string {
+	deps, _ := pc.Get(depsCtxKey).([]string)
+	return deps
+}
@@ -5,6 +5,8 @@ type Feature string
 const (
 	FeatureKatex    Feature = "katex"
 	FeatureComments Feature = "comments"
+	// FeatureDraft marks an unpublished post, built only for previews.
+	FeatureDraft Feature = "draft"
 )
 
 // FeatureSet is a special feature of a post. If a post has a feature, we might
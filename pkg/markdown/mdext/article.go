@@ -44,7 +44,7 @@ func (at *articleTransformer) Transform(doc *ast.Document, reader text.Reader, p
 	meta := GetTOMLMeta(pc)
 	heading := firstHeading(doc)
 	if heading == nil {
-		mdctx.PushError(pc, errors.New("no main heading in file: "+mdctx.GetFilePath(pc)))
+		mdctx.PushErrorAt(pc, "article", 0, errors.New("no main heading in file"))
 		return
 	}
 	titleText := renderTextTitle(reader, heading)
@@ -58,7 +58,7 @@ func (at *articleTransformer) Transform(doc *ast.Document, reader text.Reader, p
 	header := NewHeader()
 	link := ast.NewLink()
 	link.Title = []byte(titleText)
-	link.Destination = []byte(meta.Path)
+	link.Destination = []byte(meta.PageURL())
 	asts.Reparent(link, heading)
 	mdctx.SetTitle(pc, mdctx.Title{
 		Text: titleText,
@@ -69,6 +69,10 @@ func (at *articleTransformer) Transform(doc *ast.Document, reader text.Reader, p
 	newHeading.AppendChild(newHeading, link)
 	header.AppendChild(header, NewTime(meta.Date))
 	header.AppendChild(header, newHeading)
+	if !meta.Updated.IsZero() {
+		header.AppendChild(header, NewUpdated(meta.Updated))
+	}
+	header.AppendChild(header, NewPostStats())
 	article.AppendChild(article, header)
 
 	cur := heading.NextSibling()
@@ -77,6 +81,14 @@ func (at *articleTransformer) Transform(doc *ast.Document, reader text.Reader, p
 		article.AppendChild(article, cur)
 		cur = next
 	}
+	if len(meta.Changes) > 0 {
+		changelog, err := newChangelog(pc, meta.Changes)
+		if err != nil {
+			mdctx.PushErrorAt(pc, "article", FrontMatterKeyOffset(reader.Source(), "changes"), err)
+		} else {
+			article.AppendChild(article, changelog)
+		}
+	}
 	// This step must come last. When we move a node in Goldmark, it detaches
 	// from the parent and connects its prev sibling to the next sibling. Since
 	// we use heading for location info, move it last so we don't disconnect it.
@@ -2,12 +2,14 @@ package mdext
 
 import (
 	"testing"
+	"time"
 
 	"github.com/jschaf/jsc/pkg/markdown/mdctx"
 	"github.com/jschaf/jsc/pkg/markdown/mdtest"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jschaf/jsc/pkg/texts"
+	"github.com/yuin/goldmark/ast"
 )
 
 func TestArticleExt(t *testing.T) {
@@ -83,3 +85,66 @@ func TestArticleExt(t *testing.T) {
 		})
 	}
 }
+
+func TestArticleExt_Changelog(t *testing.T) {
+	src := texts.Dedent(`
+		+++
+		slug = "a"
+		date = 2020-01-02
+
+		[[changes]]
+		date = 2020-01-03
+		note = "Fix typo."
+
+		[[changes]]
+		date = 2020-02-04
+		note = "Correct the *runtime* of [quicksort](/qs)."
+		+++
+
+		# header
+
+		foo
+		`)
+	want := texts.Dedent(`
+		<article>
+		<header>
+		<time datetime="2020-01-02">January  2, 2020</time>
+		<h1 class="title"><a href="/a/" title="header">header</a></h1>
+		<p class="updated">Updated <time datetime="2020-02-04">February 4, 2020</time></p>
+		</header>
+		<p>foo</p>
+		<section class="changelog">
+		<h2>Changelog</h2>
+		<ul>
+		<li><time datetime="2020-02-04">February 4, 2020</time>: Correct the <em>runtime</em> of <a href="/qs">quicksort</a>.</li>
+		<li><time datetime="2020-01-03">January 3, 2020</time>: Fix typo.</li>
+		</ul>
+		</section>
+		</article>`)
+	md, ctx := mdtest.NewTester(t, NewTOMLExt(), NewArticleExt(), NewTimeExt(), NewHeaderExt(), NewChangelogExt())
+	doc := mdtest.MustParseMarkdown(t, md, ctx, src)
+	mdtest.AssertNoRenderDiff(t, doc, md, src, want)
+}
+
+func TestArticleExt_PostStats(t *testing.T) {
+	src := texts.Dedent(`
+		# header
+		foo
+	`)
+	stats := func(ast.Node) (PostStatsData, bool) {
+		return PostStatsData{ReadingMinutes: 3, Updated: time.Date(2021, time.March, 4, 0, 0, 0, 0, time.UTC)}, true
+	}
+	want := texts.Dedent(`
+		<article>
+		<header>
+		<time datetime="0001-01-01">January  1, 0001</time>
+		<h1 class="title"><a href="" title="header">header</a></h1>
+		<p class="post-stats">3 min read · Last modified <time datetime="2021-03-04">March 4, 2021</time></p>
+		</header>
+		<p>foo</p>
+		</article>
+	`)
+	md, ctx := mdtest.NewTester(t, NewArticleExt(), NewHeaderExt(), NewTimeExt(), NewPostStatsExt(stats))
+	doc := mdtest.MustParseMarkdown(t, md, ctx, src)
+	mdtest.AssertNoRenderDiff(t, doc, md, src, want)
+}
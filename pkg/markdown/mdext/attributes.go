@@ -0,0 +1,222 @@
+package mdext
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/jschaf/jsc/pkg/markdown/asts"
+	"github.com/jschaf/jsc/pkg/markdown/attrs"
+	"github.com/jschaf/jsc/pkg/markdown/diag"
+	"github.com/jschaf/jsc/pkg/markdown/extenders"
+	"github.com/jschaf/jsc/pkg/markdown/mdctx"
+	"github.com/jschaf/jsc/pkg/markdown/ord"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+var KindAttributes = ast.NewNodeKind("Attributes")
+
+// Attributes is a pandoc-style extended attribute list, like
+// {#id .class data-key="val"}, for the node it follows:
+//
+//	## Heading {#custom-id}
+//	[link](/foo){.external}, [[slug]]{.internal} and ![image](foo.png){.wide}
+//	A paragraph. {.lead}
+//
+//	| table |
+//	|-------|
+//
+//	{#results .wide}
+//
+// The attributes transformer applies the attributes to the node and removes
+// the Attributes node. An attribute list that doesn't follow a supported node
+// renders as the original text.
+type Attributes struct {
+	ast.BaseInline
+	Values attrs.Values
+	// Segment is the source text of the attribute list.
+	Segment text.Segment
+	// afterInline is true if the list immediately follows a link, wiki link or
+	// image.
+	// Otherwise, the list ends a line of a block.
+	afterInline bool
+}
+
+func NewAttributes() *Attributes {
+	return &Attributes{}
+}
+
+func (a *Attributes) Kind() ast.NodeKind {
+	return KindAttributes
+}
+
+func (a *Attributes) Dump(source []byte, level int) {
+	ast.DumpHelper(a, source, level, map[string]string{"Segment": string(a.Segment.Value(source))}, nil)
+}
+
+// attributesParser is an inline parser for extended attribute lists.
+type attributesParser struct{}
+
+func (p attributesParser) Trigger() []byte {
+	return []byte{'{'}
+}
+
+func (p attributesParser) Parse(parent ast.Node, block text.Reader, _ parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	end, vals, ok := parseAttributesPrefix(line)
+	if !ok {
+		return nil
+	}
+	prev := parent.LastChild()
+	prevChar := block.PrecendingCharacter()
+	afterInline := prev != nil && (prev.Kind() == ast.KindLink || prev.Kind() == ast.KindImage || prev.Kind() == KindWikiLink) &&
+		(prevChar == ')' || prevChar == ']')
+	if !afterInline && !util.IsBlank(line[end:]) {
+		return nil
+	}
+	a := NewAttributes()
+	a.Values = vals
+	a.Segment = text.NewSegment(segment.Start, segment.Start+end)
+	a.afterInline = afterInline
+	block.Advance(end)
+	return a
+}
+
+// parseAttributesPrefix parses the non-empty attribute list at the start of
+// the line. Returns the length of the list. Quoted values may contain '}', so tries
+// each '}' until the list parses.
+func parseAttributesPrefix(line []byte) (int, attrs.Values, bool) {
+	offs := 0
+	for {
+		i := bytes.IndexByte(line[offs:], '}')
+		if i < 0 {
+			return 0, nil, false
+		}
+		offs += i + 1
+		// Skip empty lists so prose like "an empty object {}" stays text.
+		if vals, err := attrs.ParseValues(string(line[:offs])); err == nil && len(vals) > 0 {
+			return offs, vals, true
+		}
+	}
+}
+
+// attributesTransformer applies each attribute list to its node.
+type attributesTransformer struct{}
+
+func (t attributesTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	var nodes []*Attributes
+	err := asts.WalkKind(KindAttributes, doc, func(n ast.Node) (ast.WalkStatus, error) {
+		nodes = append(nodes, n.(*Attributes))
+		return ast.WalkSkipChildren, nil
+	})
+	if err != nil {
+		mdctx.PushErrorAt(pc, "attributes", diag.NoOffset, fmt.Errorf("walk attributes: %w", err))
+		return
+	}
+	src := reader.Source()
+	for _, a := range nodes {
+		parent := a.Parent()
+		if a.afterInline {
+			t.apply(pc, a, a.PreviousSibling())
+			continue
+		}
+		if !isLastInline(a, src) {
+			continue // in the middle of a block, so leave as text
+		}
+		switch parent.Kind() {
+		case ast.KindHeading:
+			removeTrailingInlines(a, src)
+			t.apply(pc, a, parent)
+		case ast.KindParagraph:
+			removeTrailingInlines(a, src)
+			if a.PreviousSibling() != nil {
+				t.apply(pc, a, parent)
+				continue
+			}
+			// A paragraph with only attributes applies to the previous block,
+			// like a table.
+			target := parent.PreviousSibling()
+			if target == nil {
+				mdctx.PushErrorAt(pc, "attributes", a.Segment.Start, fmt.Errorf("no block before attributes %s", a.Segment.Value(src)))
+				continue
+			}
+			t.apply(pc, a, target)
+			parent.Parent().RemoveChild(parent.Parent(), parent)
+		}
+	}
+}
+
+// apply applies the attributes to the node and removes the Attributes node.
+// Registers an explicit ID so generated heading IDs don't reuse it.
+func (t attributesTransformer) apply(pc parser.Context, a *Attributes, n ast.Node) {
+	if id := a.Values.ID(); id != "" {
+		ids := mdctx.HeadingIDs(pc)
+		if _, ok := ids[id]; ok {
+			mdctx.PushErrorAt(pc, "attributes", a.Segment.Start, fmt.Errorf("duplicate ID %q", id))
+		}
+		ids[id] = struct{}{}
+	}
+	a.Values.Apply(n)
+	if parent := a.Parent(); parent != nil {
+		parent.RemoveChild(parent, a)
+	}
+}
+
+// isLastInline returns true if no inline node with text follows the node in
+// the block.
+func isLastInline(n ast.Node, src []byte) bool {
+	for s := n.NextSibling(); s != nil; s = s.NextSibling() {
+		if txt, ok := s.(*ast.Text); !ok || !util.IsBlank(txt.Segment.Value(src)) {
+			return false
+		}
+	}
+	return true
+}
+
+// removeTrailingInlines removes the blank text nodes after the attributes at
+// the end of a block and trims the space before the attributes.
+func removeTrailingInlines(a *Attributes, src []byte) {
+	parent := a.Parent()
+	for s := a.NextSibling(); s != nil; {
+		next := s.NextSibling()
+		parent.RemoveChild(parent, s)
+		s = next
+	}
+	if txt, ok := a.PreviousSibling().(*ast.Text); ok {
+		txt.Segment = txt.Segment.TrimRightSpace(src)
+		txt.SetSoftLineBreak(false)
+	}
+}
+
+// attributesRenderer renders an attribute list not applied to a node as the
+// original text.
+type attributesRenderer struct{}
+
+func (r attributesRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindAttributes, r.render)
+}
+
+func (r attributesRenderer) render(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		_, _ = w.Write(util.EscapeHTML(node.(*Attributes).Segment.Value(src)))
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+// AttributesExt is the Goldmark extension to parse extended attributes and
+// apply them to headings, links, images, paragraphs and blocks.
+type AttributesExt struct{}
+
+func NewAttributesExt() *AttributesExt {
+	return &AttributesExt{}
+}
+
+func (e *AttributesExt) Extend(m goldmark.Markdown) {
+	extenders.AddInlineParser(m, attributesParser{}, ord.AttributesParser)
+	extenders.AddASTTransform(m, attributesTransformer{}, ord.AttributesTransformer)
+	extenders.AddRenderer(m, attributesRenderer{}, ord.AttributesRenderer)
+}
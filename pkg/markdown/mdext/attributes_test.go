@@ -0,0 +1,149 @@
+package mdext
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jschaf/jsc/pkg/markdown/mdctx"
+	"github.com/jschaf/jsc/pkg/markdown/mdtest"
+	"github.com/jschaf/jsc/pkg/texts"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestNewAttributesExt(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			"heading id",
+			`## Heading {#custom}`,
+			`<h2 id="custom">Heading</h2>`,
+		},
+		{
+			"heading class and data",
+			`# Heading {.wide data-note="a b"}`,
+			`<h1 class="wide" data-note="a b" id="heading">Heading</h1>`,
+		},
+		{
+			"heading generated ID avoids explicit ID",
+			texts.Dedent(`
+				## Foo
+				## Bar {#foo}
+			`),
+			texts.Dedent(`
+				<h2 id="foo-1">Foo</h2>
+				<h2 id="foo">Bar</h2>
+			`),
+		},
+		{
+			"link",
+			`see [foo](/foo){.external data-kind="x"} now`,
+			`<p>see <a href="/foo" class="external" data-kind="x">foo</a> now</p>`,
+		},
+		{
+			"image",
+			`an ![alt](qux.png){#pic .wide} image`,
+			`<p>an <img src="qux.png" alt="alt" id="pic" class="wide"> image</p>`,
+		},
+		{
+			"wiki link",
+			`see [[foo]]{.internal}`,
+			`<p>see <a href="/foo" class="internal">foo</a></p>`,
+		},
+		{
+			"paragraph",
+			texts.Dedent(`
+				Some text
+				and more. {.lead}
+			`),
+			`<p class="lead">Some text
+and more.</p>`,
+		},
+		{
+			"table",
+			texts.Dedent(`
+				| a |
+				|---|
+				| b |
+
+				{#results .wide}
+			`),
+			texts.Dedent(`
+				<table id="results" class="wide">
+				<thead><tr><th>a</th></tr></thead>
+				<tbody><tr><td>b</td></tr></tbody>
+				</table>
+			`),
+		},
+		{
+			"braces in prose",
+			`a {#foo} b`,
+			`<p>a {#foo} b</p>`,
+		},
+		{
+			"empty attributes",
+			`an empty object {}`,
+			`<p>an empty object {}</p>`,
+		},
+		{
+			"invalid attributes",
+			`foo {#a #b}`,
+			`<p>foo {#a #b}</p>`,
+		},
+		{
+			"middle of paragraph",
+			texts.Dedent(`
+				foo {.bar}
+				baz
+			`),
+			"<p>foo {.bar}\nbaz</p>",
+		},
+		{
+			"quoted brace",
+			`## Heading {data-x="}"}`,
+			`<h2 data-x="}" id="heading">Heading</h2>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md, ctx := mdtest.NewTester(t, NewAttributesExt(), NewHeadingIDExt(), NewTableExt(), NewParagraphExt(), NewWikiLinkExt())
+			doc := mdtest.MustParseMarkdown(t, md, ctx, tt.src)
+			mdtest.AssertNoRenderDiff(t, doc, md, tt.src, tt.want)
+		})
+	}
+}
+
+func TestNewAttributesExt_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			"duplicate ID",
+			texts.Dedent(`
+				## Foo {#foo}
+				## Bar {#foo}
+			`),
+			`duplicate ID "foo"`,
+		},
+		{
+			"no block before",
+			`{.wide}`,
+			"no block before attributes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md, ctx := mdtest.NewTester(t, NewAttributesExt(), NewHeadingIDExt())
+			md.Parser().Parse(text.NewReader([]byte(tt.src)), parser.WithContext(ctx))
+			errs := mdctx.PopErrors(ctx)
+			if len(errs) != 1 || !strings.Contains(errs[0].Error(), tt.want) {
+				t.Errorf("want one error containing %q; got %v", tt.want, errs)
+			}
+		})
+	}
+}
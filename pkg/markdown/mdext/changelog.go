@@ -0,0 +1,162 @@
+package mdext
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jschaf/jsc/pkg/markdown/extenders"
+	"github.com/jschaf/jsc/pkg/markdown/mdctx"
+	"github.com/jschaf/jsc/pkg/markdown/ord"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+var (
+	KindUpdated   = ast.NewNodeKind("Updated")
+	KindChangelog = ast.NewNodeKind("Changelog")
+)
+
+// Updated is the date of the last substantive change to a post, shown in the
+// header of the post.
+type Updated struct {
+	ast.BaseBlock
+	Date time.Time
+}
+
+func NewUpdated(date time.Time) *Updated {
+	return &Updated{Date: date}
+}
+
+func (u *Updated) Dump(source []byte, level int) {
+	ast.DumpHelper(u, source, level, nil, nil)
+}
+
+func (u *Updated) Kind() ast.NodeKind {
+	return KindUpdated
+}
+
+// Changelog is the list of substantive changes to a post at the end of the
+// article, newest first.
+type Changelog struct {
+	ast.BaseBlock
+	Entries []ChangelogEntry
+}
+
+// ChangelogEntry is a change with the note rendered as HTML. The note is
+// rendered while transforming since the note isn't part of the Markdown
+// source.
+type ChangelogEntry struct {
+	Date     time.Time
+	NoteHTML []byte
+}
+
+func NewChangelog() *Changelog {
+	return &Changelog{}
+}
+
+func (c *Changelog) Dump(source []byte, level int) {
+	ast.DumpHelper(c, source, level, nil, nil)
+}
+
+func (c *Changelog) Kind() ast.NodeKind {
+	return KindChangelog
+}
+
+// newChangelog renders the note of each change to HTML with the main
+// renderer. A note that's a single paragraph renders without the <p> tag so
+// it reads inline after the date.
+func newChangelog(pc parser.Context, changes []Change) (*Changelog, error) {
+	r, ok := mdctx.GetRenderer(pc)
+	if !ok {
+		return nil, fmt.Errorf("render changelog: no renderer")
+	}
+	p := goldmark.DefaultParser()
+	cl := NewChangelog()
+	for _, c := range changes {
+		src := []byte(c.Note)
+		doc := p.Parse(text.NewReader(src))
+		var nodes []ast.Node
+		if para := doc.FirstChild(); para != nil && para.Kind() == ast.KindParagraph && para.NextSibling() == nil {
+			for n := para.FirstChild(); n != nil; n = n.NextSibling() {
+				nodes = append(nodes, n)
+			}
+		} else {
+			nodes = []ast.Node{doc}
+		}
+		b := &bytes.Buffer{}
+		for _, n := range nodes {
+			if err := r.Render(b, src, n); err != nil {
+				return nil, fmt.Errorf("render changelog note: %w", err)
+			}
+		}
+		cl.Entries = append(cl.Entries, ChangelogEntry{Date: c.Date, NoteHTML: bytes.TrimSpace(b.Bytes())})
+	}
+	// Newest first. The sort is stable so changes on the same day keep the
+	// front matter order.
+	slices.SortStableFunc(cl.Entries, func(a, b ChangelogEntry) int {
+		return b.Date.Compare(a.Date)
+	})
+	return cl, nil
+}
+
+// changelogRenderer is the HTML renderer for the updated date and changelog
+// nodes.
+type changelogRenderer struct{}
+
+func (cr changelogRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindUpdated, cr.renderUpdated)
+	reg.Register(KindChangelog, cr.renderChangelog)
+}
+
+func (cr changelogRenderer) renderUpdated(w util.BufWriter, _ []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		n := node.(*Updated)
+		_, _ = w.WriteString("<p class=\"updated\">Updated ")
+		writeTimeTag(w, n.Date)
+		_, _ = w.WriteString("</p>\n")
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+func (cr changelogRenderer) renderChangelog(w util.BufWriter, _ []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkSkipChildren, nil
+	}
+	n := node.(*Changelog)
+	_, _ = w.WriteString("<section class=\"changelog\">\n<h2>Changelog</h2>\n<ul>\n")
+	for _, e := range n.Entries {
+		_, _ = w.WriteString("<li>")
+		writeTimeTag(w, e.Date)
+		_, _ = w.WriteString(": ")
+		_, _ = w.Write(e.NoteHTML)
+		_, _ = w.WriteString("</li>\n")
+	}
+	_, _ = w.WriteString("</ul>\n</section>\n")
+	return ast.WalkSkipChildren, nil
+}
+
+func writeTimeTag(w util.BufWriter, date time.Time) {
+	_, _ = w.WriteString("<time datetime=\"")
+	_, _ = w.WriteString(date.UTC().Format("2006-01-02"))
+	_, _ = w.WriteString("\">")
+	_, _ = w.WriteString(date.Format("January 2, 2006"))
+	_, _ = w.WriteString("</time>")
+}
+
+// ChangelogExt is the Goldmark extension to render the updated date and the
+// changelog added by the article transformer.
+type ChangelogExt struct{}
+
+func NewChangelogExt() *ChangelogExt {
+	return &ChangelogExt{}
+}
+
+func (c *ChangelogExt) Extend(m goldmark.Markdown) {
+	extenders.AddRenderer(m, changelogRenderer{}, ord.ChangelogRenderer)
+}
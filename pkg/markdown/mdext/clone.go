@@ -132,6 +132,8 @@ func newNode(src ast.Node) ast.Node {
 		return NewFigCaption()
 	case *Header:
 		return NewHeader()
+	case *PostStats:
+		return NewPostStats()
 	case *SmallCaps:
 		sc := NewSmallCaps()
 		sc.Segment = n.Segment
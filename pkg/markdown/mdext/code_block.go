@@ -6,6 +6,7 @@ import (
 	"github.com/alecthomas/chroma"
 	"github.com/alecthomas/chroma/lexers"
 	"github.com/jschaf/jsc/pkg/markdown/attrs"
+	"github.com/jschaf/jsc/pkg/markdown/diag"
 	"github.com/jschaf/jsc/pkg/markdown/extenders"
 	"github.com/jschaf/jsc/pkg/markdown/ord"
 	"github.com/yuin/goldmark"
@@ -30,23 +31,32 @@ func (c codeBlockRenderer) render(w util.BufWriter, source []byte, node ast.Node
 	if entering {
 		info, err := parseCodeBlockInfo(n, source)
 		if err != nil {
-			return ast.WalkStop, fmt.Errorf("parse code block info: %w", err)
+			return ast.WalkStop, diag.Errorf("code_block", codeBlockOffset(n), "parse code block info: %w", err)
 		}
 
 		lexer := getLexer(info.lang)
 
 		tokenIter, err := lexer.Tokenise(nil, readAllCodeBlockLines(n, source))
 		if err != nil {
-			panic(err)
+			return ast.WalkStop, diag.Errorf("code_block", codeBlockOffset(n), "tokenize code block: %w", err)
 		}
 		if err := formatCodeBlock(w, tokenIter, info); err != nil {
-			panic(err)
+			return ast.WalkStop, diag.Errorf("code_block", codeBlockOffset(n), "format code block: %w", err)
 		}
 
 	}
 	return ast.WalkContinue, nil
 }
 
+// codeBlockOffset returns the offset of the info string of the code block,
+// like "go" in "```go", or the offset of the first line of code.
+func codeBlockOffset(n *ast.FencedCodeBlock) int {
+	if n.Info != nil {
+		return n.Info.Segment.Start
+	}
+	return diag.NodeOffset(n)
+}
+
 type codeInfo struct {
 	lang        string
 	name        string
@@ -68,11 +78,14 @@ func parseCodeBlockInfo(n *ast.FencedCodeBlock, source []byte) (codeInfo, error)
 	if err != nil {
 		return codeInfo{}, fmt.Errorf("parse extented attribute values: %w", err)
 	}
+	if err := vals.Only("name", "description"); err != nil {
+		return codeInfo{}, fmt.Errorf("code block attributes: %w", err)
+	}
 
 	return codeInfo{
 		lang:        lang,
-		name:        vals.Name,
-		description: vals.Description,
+		name:        vals.Name(),
+		description: vals.Description(),
 	}, nil
 }
 
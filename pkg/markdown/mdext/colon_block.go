@@ -2,10 +2,13 @@ package mdext
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/jschaf/jsc/pkg/markdown/asts"
+	"github.com/jschaf/jsc/pkg/markdown/attrs"
+	"github.com/jschaf/jsc/pkg/markdown/diag"
 	"github.com/jschaf/jsc/pkg/markdown/extenders"
 	"github.com/jschaf/jsc/pkg/markdown/mdctx"
 	"github.com/jschaf/jsc/pkg/markdown/ord"
@@ -103,6 +106,9 @@ func GetFootnoteBodies(pc parser.Context) map[FootnoteName]*FootnoteBody {
 //	# heading
 //	Some *content*
 //	:::
+//
+// The opening line may end with extended attributes, like
+// "::: footnote foo {.wide}".
 type ColonBlock struct {
 	ast.BaseBlock
 
@@ -142,8 +148,12 @@ func (cbp colonBlockParser) Open(_ ast.Node, reader text.Reader, _ parser.Contex
 	}
 	reader.AdvanceLine()
 	rest := bytes.Trim(line[len(colonBlockDelim):], " \t\n")
-	nameArgs := bytes.SplitN(rest, []byte{' '}, 2)
 	block := NewColonBlock()
+	if before, vals, ok := attrs.CutTrailing(string(rest)); ok {
+		rest = []byte(before)
+		vals.Apply(block)
+	}
+	nameArgs := bytes.SplitN(rest, []byte{' '}, 2)
 	if len(nameArgs) >= 1 {
 		block.Name = ColonBlockName(strings.Trim(string(nameArgs[0]), " "))
 	}
@@ -166,6 +176,9 @@ func (cbp colonBlockParser) Close(node ast.Node, _ text.Reader, pc parser.Contex
 	block := node.(*ColonBlock)
 	switch block.Name {
 	case ColonBlockPreview:
+		if block.Attributes() != nil {
+			mdctx.PushErrorAt(pc, "colon_block", diag.NodeOffset(node), errors.New("preview colon block doesn't support attributes"))
+		}
 		url := block.Args
 		preview := Preview{
 			URL:    url,
@@ -176,18 +189,24 @@ func (cbp colonBlockParser) Close(node ast.Node, _ text.Reader, pc parser.Contex
 		// Replace the ColonBlock with a FootnoteBody.
 		name, variant, err := parseFootnoteName(block.Args)
 		if err != nil {
-			mdctx.PushError(pc, fmt.Errorf("close colon block footnote: %w", err))
+			mdctx.PushErrorAt(pc, "colon_block", diag.NodeOffset(node), fmt.Errorf("close colon block footnote: %w", err))
+		}
+		if _, ok := block.AttributeString("id"); ok {
+			mdctx.PushErrorAt(pc, "colon_block", diag.NodeOffset(node), errors.New("footnote colon block doesn't support an ID; the ID is generated"))
 		}
 		body := NewFootnoteBody()
 		body.Name = name
 		body.Variant = variant
+		for _, attr := range block.Attributes() {
+			body.SetAttribute(attr.Name, attr.Value)
+		}
 		asts.Reparent(body, node)
 		parent := node.Parent()
 		parent.ReplaceChild(parent, node, body)
 		AddFootnoteBody(pc, body)
 
 	default:
-		mdctx.PushError(pc, fmt.Errorf("unknown colon block name %q", block.Name))
+		mdctx.PushErrorAt(pc, "colon_block", diag.NodeOffset(node), fmt.Errorf("unknown colon block name %q", block.Name))
 	}
 }
 
@@ -2,6 +2,7 @@ package mdext
 
 import (
 	"bytes"
+	"github.com/jschaf/jsc/pkg/markdown/attrs"
 	"github.com/jschaf/jsc/pkg/markdown/mdctx"
 	"path/filepath"
 
@@ -92,6 +93,10 @@ func (clp ColonLineParser) Open(_ ast.Node, reader text.Reader, pc parser.Contex
 	case ColonLineEmbed:
 		sourceDir := filepath.Dir(mdctx.GetFilePath(pc))
 		embed := NewEmbed(sourceDir, cl.RawAttrs)
+		// Errors are reported when rendering the embed.
+		if vals, err := attrs.ParseValues(cl.RawAttrs); err == nil && vals.Name() != "" {
+			mdctx.AddDependency(pc, filepath.Join(sourceDir, vals.Name()))
+		}
 		return embed, parser.Close
 	default:
 		return nil, parser.NoChildren
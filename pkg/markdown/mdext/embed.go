@@ -57,10 +57,13 @@ func renderEmbed(w util.BufWriter, _ []byte, node ast.Node, entering bool) (ast.
 	if err != nil {
 		return ast.WalkContinue, fmt.Errorf("parse embed attrs: %w", err)
 	}
-	if vals.Name == "" {
+	if err := vals.Only("name"); err != nil {
+		return ast.WalkContinue, fmt.Errorf("embed attrs: %w", err)
+	}
+	if vals.Name() == "" {
 		return ast.WalkContinue, fmt.Errorf("embed directive missing name; :embed: {name='<path>'}")
 	}
-	embedPath := filepath.Join(n.sourceDir, vals.Name)
+	embedPath := filepath.Join(n.sourceDir, vals.Name())
 	bs, err := os.ReadFile(embedPath)
 	if err != nil {
 		return ast.WalkContinue, fmt.Errorf("read embed file: %w", err)
@@ -1,10 +1,13 @@
 package mdext
 
 import (
+	"fmt"
 	"path"
 	"strings"
 
+	"github.com/jschaf/jsc/pkg/markdown/diag"
 	"github.com/jschaf/jsc/pkg/markdown/extenders"
+	"github.com/jschaf/jsc/pkg/markdown/mdctx"
 	"github.com/jschaf/jsc/pkg/markdown/ord"
 
 	"github.com/jschaf/jsc/pkg/markdown/asts"
@@ -97,7 +100,8 @@ func (f *figureASTTransformer) Transform(doc *ast.Document, r text.Reader, pc pa
 		}
 	})
 	if err != nil {
-		panic(err)
+		mdctx.PushErrorAt(pc, "figure", diag.NoOffset, fmt.Errorf("walk figure images: %w", err))
+		return
 	}
 
 	// Replace each image with a figure.
@@ -113,8 +117,16 @@ func (f *figureASTTransformer) Transform(doc *ast.Document, r text.Reader, pc pa
 		fig.Destination = []byte(newDest)
 		fig.Title = img.Title
 		fig.AltText = img.Text(r.Source())
-
+		// The figure renders attributes on the img, like {.wide}, from either
+		// the paragraph or the image.
 		para := img.Parent()
+		for _, attr := range para.Attributes() {
+			fig.SetAttribute(attr.Name, attr.Value)
+		}
+		for _, attr := range img.Attributes() {
+			fig.SetAttribute(attr.Name, attr.Value)
+		}
+
 		root := para.Parent()
 		root.ReplaceChild(root, para, fig)
 		figs = append(figs, fig)
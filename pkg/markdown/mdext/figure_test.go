@@ -149,10 +149,22 @@ func TestNewFigureExt(t *testing.T) {
 			  </figure>
     `),
 		},
+		{
+			"single image with attributes",
+			texts.Dedent(`
+		 ![alt text](./qux.png "title"){#pic .wide}`),
+			texts.Dedent(`
+			  <figure>
+		  <picture>
+		    <img src="qux.png" loading="lazy" alt="alt text" title="title" id="pic" class="wide">
+		  </picture>
+			  </figure>
+		`),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			md, ctx := mdtest.NewTester(t, NewTOMLExt(), NewFigureExt())
+			md, ctx := mdtest.NewTester(t, NewTOMLExt(), NewAttributesExt(), NewFigureExt())
 			doc := mdtest.MustParseMarkdown(t, md, ctx, tt.src)
 			mdtest.AssertNoRenderDiff(t, doc, md, tt.src, tt.want)
 		})
@@ -12,6 +12,7 @@ import (
 	"github.com/jschaf/bibtex"
 	"github.com/jschaf/jsc/pkg/cite"
 	"github.com/jschaf/jsc/pkg/markdown/attrs"
+	"github.com/jschaf/jsc/pkg/markdown/diag"
 	"github.com/jschaf/jsc/pkg/markdown/extenders"
 	"github.com/jschaf/jsc/pkg/markdown/mdctx"
 	"github.com/jschaf/jsc/pkg/markdown/ord"
@@ -153,7 +154,7 @@ func (f footnoteLinkParser) Parse(_ ast.Node, block text.Reader, pc parser.Conte
 	link := NewFootnoteLink()
 	name, variant, err := parseFootnoteName(value)
 	if err != nil {
-		mdctx.PushError(pc, fmt.Errorf("parse inline footnote: %w", err))
+		mdctx.PushErrorAt(pc, "footnote", segment.Start+open, fmt.Errorf("parse inline footnote: %w", err))
 		return nil
 	}
 	link.Name = name
@@ -198,10 +199,10 @@ func (fb footnoteBodyTransformer) Transform(doc *ast.Document, source text.Reade
 	bibs := GetTOMLMeta(pc).BibPaths
 	bibEntries, err := fb.readBibs(bibs)
 	if err != nil {
-		mdctx.PushError(pc, err)
+		mdctx.PushErrorAt(pc, "footnote", diag.NoOffset, err)
 		return
 	}
-	absPath := GetTOMLMeta(pc).Path
+	absPath := GetTOMLMeta(pc).PageURL()
 	seenOrders := make(map[string]int)
 	order := 1
 
@@ -221,7 +222,7 @@ func (fb footnoteBodyTransformer) Transform(doc *ast.Document, source text.Reade
 			// All other variants must have a corresponding body node.
 			b, ok := bodies[link.Name]
 			if !ok {
-				mdctx.PushError(pc, fmt.Errorf("no footnote body for footnote link %q", link.Name))
+				mdctx.PushErrorAt(pc, "footnote", diag.NodeOffset(link), fmt.Errorf("no footnote body for footnote link %q", link.Name))
 				continue
 			}
 			body = b
@@ -255,7 +256,7 @@ func (fb footnoteBodyTransformer) Transform(doc *ast.Document, source text.Reade
 			c.Key = bibtex.CiteKey(link.Name)
 			bib, ok := bibEntries[c.Key]
 			if !ok {
-				mdctx.PushError(pc, fmt.Errorf("footnote: no bibtex found for key: %s", c.Key))
+				mdctx.PushErrorAt(pc, "footnote", diag.NodeOffset(link), fmt.Errorf("no bibtex found for key: %s", c.Key))
 				return
 			}
 			c.Bibtex = bib
@@ -330,7 +331,7 @@ func (fb footnoteBodyTransformer) Transform(doc *ast.Document, source text.Reade
 	// Attach the citation references.
 	if fb.citeRefsAttacher != nil {
 		if err := fb.citeRefsAttacher.Attach(doc, refs); err != nil {
-			mdctx.PushError(pc, fmt.Errorf("attach cite references: %w", err))
+			mdctx.PushErrorAt(pc, "footnote", diag.NoOffset, fmt.Errorf("attach cite references: %w", err))
 		}
 	}
 }
@@ -395,7 +396,7 @@ func (fb footnoteBodyTransformer) calcDistance(source text.Reader, link *Footnot
 		return ast.WalkContinue, nil
 	})
 	if err != nil {
-		mdctx.PushError(pc, fmt.Errorf("calc distance for footnote body offset: %w", err))
+		mdctx.PushErrorAt(pc, "footnote", diag.NodeOffset(link), fmt.Errorf("calc distance for footnote body offset: %w", err))
 		return 0
 	}
 	return endPos - linkPos
@@ -44,6 +44,16 @@ func (c *Citation) CiteID(count int) string {
 	return "footnote-link-" + c.Key + "-" + strconv.Itoa(count)
 }
 
+// Title returns the title of the cited work or empty if the bibtex entry has
+// no title.
+func (c *Citation) Title() string {
+	t := c.Bibtex.Tags[bibtex.FieldTitle]
+	if t == nil {
+		return ""
+	}
+	return assertSimpleText(t)
+}
+
 // ReferenceID returns the HTML ID that links to the full reference for a
 // citation, displayed in the reference section, if any.
 func (c *Citation) ReferenceID() string {
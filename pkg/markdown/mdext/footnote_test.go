@@ -74,6 +74,25 @@ func TestNewFootnoteExt(t *testing.T) {
         <aside class="footnote-body" id="footnote-body-margin:foo" role="doc-endnote" style="margin-top: -18px">
           <p>body-text</p>
         </aside>
+      `),
+		},
+		{
+			"margin note with attributes",
+			texts.Dedent(`
+        [^margin:foo] alpha bravo charlie
+
+        ::: footnote margin:foo {.wide data-note="x"}
+        body-text
+        :::
+      `),
+			texts.Dedent(`
+        <p>
+          <a href="#footnote-body-margin:foo" class="footnote-link" role="doc-noteref" id="footnote-link-margin:foo"></a>
+          alpha bravo charlie
+        </p>
+        <aside class="wide footnote-body" data-note="x" id="footnote-body-margin:foo" role="doc-endnote" style="margin-top: -18px">
+          <p>body-text</p>
+        </aside>
       `),
 		},
 	}
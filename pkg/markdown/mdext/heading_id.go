@@ -1,7 +1,10 @@
 package mdext
 
 import (
+	"fmt"
+
 	"github.com/jschaf/jsc/pkg/markdown/asts"
+	"github.com/jschaf/jsc/pkg/markdown/diag"
 	"github.com/jschaf/jsc/pkg/markdown/extenders"
 	"github.com/jschaf/jsc/pkg/markdown/mdctx"
 	"github.com/jschaf/jsc/pkg/markdown/ord"
@@ -19,23 +22,29 @@ import (
 const maxHeadingIDLen = 36
 
 // headingIDTransformer is an AST transformer that adds an ID attribute to each
-// heading.
+// heading without an explicit ID.
 type headingIDTransformer struct{}
 
 func (h headingIDTransformer) Transform(node *ast.Document, reader text.Reader, pc parser.Context) {
 	ids := mdctx.HeadingIDs(pc)
 	_ = asts.WalkHeadings(node, func(h *ast.Heading) (ast.WalkStatus, error) {
-		id := generateHeadingID(ids, h, reader.Source())
+		if _, ok := h.AttributeString("id"); ok {
+			return ast.WalkSkipChildren, nil // explicit ID, like {#foo}
+		}
+		id, err := generateHeadingID(ids, h, reader.Source())
+		if err != nil {
+			mdctx.PushErrorAt(pc, "heading_id", diag.NodeOffset(h), err)
+		}
 		h.SetAttribute([]byte("id"), id)
 		return ast.WalkSkipChildren, nil
 	})
 }
 
-func generateHeadingID(ids map[string]struct{}, h *ast.Heading, src []byte) []byte {
+func generateHeadingID(ids map[string]struct{}, h *ast.Heading, src []byte) ([]byte, error) {
 	b := asts.WriteSlugText(make([]byte, maxHeadingIDLen, maxHeadingIDLen+2), h, src)
 	if !hasHeadingID(ids, b) {
 		ids[string(b)] = struct{}{}
-		return b
+		return b, nil
 	}
 
 	// Starting appending -1, -2, ... etc.
@@ -47,10 +56,10 @@ func generateHeadingID(ids map[string]struct{}, h *ast.Heading, src []byte) []by
 		b[last] = d
 		if !hasHeadingID(ids, b) {
 			ids[string(b)] = struct{}{}
-			return b
+			return b, nil
 		}
 	}
-	panic("no unique heading found after 10 iterations: " + string(b))
+	return b, fmt.Errorf("no unique heading ID found after 10 iterations: %s", b)
 }
 
 func hasHeadingID(ids map[string]struct{}, b []byte) bool {
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/jschaf/jsc/pkg/markdown/assets"
+	"github.com/jschaf/jsc/pkg/markdown/diag"
 	"github.com/jschaf/jsc/pkg/markdown/extenders"
 	"github.com/jschaf/jsc/pkg/markdown/mdctx"
 	"github.com/jschaf/jsc/pkg/markdown/ord"
@@ -52,7 +53,7 @@ func (f imageASTTransformer) Transform(doc *ast.Document, _ text.Reader, pc pars
 		return ast.WalkSkipChildren, nil
 	})
 	if err != nil {
-		panic(err)
+		mdctx.PushErrorAt(pc, "image", diag.NoOffset, fmt.Errorf("walk image assets: %w", err))
 	}
 }
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/graemephi/goldmark-qjs-katex"
+	"github.com/jschaf/jsc/pkg/markdown/diag"
 	"github.com/jschaf/jsc/pkg/markdown/extenders"
 	"github.com/jschaf/jsc/pkg/markdown/mdctx"
 	"github.com/jschaf/jsc/pkg/markdown/ord"
@@ -33,7 +34,7 @@ func (kt *katexTransformer) Transform(doc *ast.Document, _ text.Reader, pc parse
 		return ast.WalkContinue, nil
 	})
 	if err != nil {
-		mdctx.PushError(pc, fmt.Errorf("find katex nodes: %w", err))
+		mdctx.PushErrorAt(pc, "katex", diag.NoOffset, fmt.Errorf("find katex nodes: %w", err))
 	}
 }
 
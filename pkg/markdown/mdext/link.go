@@ -3,12 +3,15 @@ package mdext
 import (
 	"bytes"
 	"fmt"
+	"html"
 	"path"
 	"path/filepath"
 	"strings"
 
 	"github.com/jschaf/jsc/pkg/markdown/assets"
+	"github.com/jschaf/jsc/pkg/markdown/diag"
 	"github.com/jschaf/jsc/pkg/markdown/extenders"
+	"github.com/jschaf/jsc/pkg/markdown/linkio"
 	"github.com/jschaf/jsc/pkg/markdown/mdctx"
 	"github.com/jschaf/jsc/pkg/markdown/ord"
 
@@ -55,21 +58,33 @@ func (l *linkAssetTransformer) Transform(doc *ast.Document, _ text.Reader, pc pa
 		return ast.WalkSkipChildren, nil
 	})
 	if err != nil {
-		panic(err)
+		mdctx.PushErrorAt(pc, "link", diag.NoOffset, fmt.Errorf("walk link assets: %w", err))
 	}
 }
 
 // linkDecorationTransform is an AST transformer that adds preview information
-// to links.
-type linkDecorationTransform struct{}
+// to links. A link uses the preview in a "::: preview" colon block if one
+// exists, otherwise the cached preview, if any. A link that the link archive
+// marks as dead gets a link to an archived copy.
+type linkDecorationTransform struct {
+	// cache holds fetched link previews. Nil to only use colon block previews.
+	cache *linkio.Cache
+	// archive holds the check history of links. Nil to never link to
+	// archived copies.
+	archive *linkio.Archive
+}
 
 const (
 	LinkCitation linkType = "citation"
 	LinkPDF      linkType = "pdf"
 	LinkWiki     linkType = "wikipedia"
+	LinkArchive  linkType = "archive"
 )
 
 func (l linkDecorationTransform) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	// Add archived copies after the walk so the walk doesn't visit them.
+	var dead []*ast.Link
+	var deadRecords []linkio.ArchiveRecord
 	err := ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
 		if !entering {
 			return ast.WalkSkipChildren, nil
@@ -89,34 +104,82 @@ func (l linkDecorationTransform) Transform(doc *ast.Document, reader text.Reader
 			link.SetAttribute([]byte("data-link-type"), []byte(LinkWiki))
 		}
 
-		renderPreview(pc, origDest, reader, link)
+		if _, ok := GetPreview(pc, origDest); ok {
+			if err := renderPreview(pc, origDest, reader, link); err != nil {
+				mdctx.PushErrorAt(pc, "link", diag.NodeOffset(link), err)
+			}
+		} else if err := l.renderCachedPreview(pc, origDest, link); err != nil {
+			mdctx.PushErrorAt(pc, "link", diag.NodeOffset(link), err)
+		}
+
+		rec, isDead, err := l.deadRecord(pc, origDest)
+		if err != nil {
+			mdctx.PushErrorAt(pc, "link", diag.NodeOffset(link), err)
+		}
+		if isDead {
+			dead = append(dead, link)
+			deadRecords = append(deadRecords, rec)
+		}
 
 		return ast.WalkSkipChildren, nil
 	})
 	if err != nil {
-		panic(err)
+		mdctx.PushErrorAt(pc, "link", diag.NoOffset, fmt.Errorf("walk link decorations: %w", err))
+	}
+	for i, link := range dead {
+		addArchivedLink(link, deadRecords[i])
+	}
+}
+
+// deadRecord returns the archive record of an http link if the latest check
+// found the link dead. The record file is a dependency even if missing so
+// that checking the link rebuilds the post.
+func (l linkDecorationTransform) deadRecord(pc parser.Context, origDest string) (linkio.ArchiveRecord, bool, error) {
+	if l.archive == nil || !linkio.IsPreviewable(origDest) {
+		return linkio.ArchiveRecord{}, false, nil
+	}
+	mdctx.AddDependency(pc, l.archive.Path(origDest))
+	rec, ok, err := l.archive.Get(origDest)
+	if err != nil {
+		return linkio.ArchiveRecord{}, false, fmt.Errorf("link archive for %s: %w", origDest, err)
 	}
+	return rec, ok && rec.Dead(), nil
 }
 
-func renderPreview(pc parser.Context, origDest string, reader text.Reader, link *ast.Link) {
+// addArchivedLink adds a link to the archived copy of a dead link after the
+// link, like: "dead link (archived copy)".
+func addArchivedLink(link *ast.Link, rec linkio.ArchiveRecord) {
+	archived := ast.NewLink()
+	archived.Destination = []byte(rec.WaybackURL())
+	archived.SetAttribute([]byte("class"), []byte("archived-link"))
+	archived.SetAttribute([]byte("data-link-type"), []byte(LinkArchive))
+	archived.AppendChild(archived, ast.NewString([]byte("archived copy")))
+	parent := link.Parent()
+	open := ast.NewString([]byte(" ("))
+	parent.InsertAfter(parent, link, open)
+	parent.InsertAfter(parent, open, archived)
+	parent.InsertAfter(parent, archived, ast.NewString([]byte(")")))
+}
+
+func renderPreview(pc parser.Context, origDest string, reader text.Reader, link *ast.Link) error {
 	// If we have a preview, render it into the attributes.
 	preview, ok := GetPreview(pc, origDest)
 	if !ok {
-		return
+		return nil
 	}
 	renderer, ok := mdctx.GetRenderer(pc)
 	if !ok {
-		panic("link preview: no renderer")
+		return fmt.Errorf("link preview for %s: no renderer", origDest)
 	}
 
 	colonBlock := preview.Parent
 	if colonBlock == nil {
-		return
+		return nil
 	}
 	// Assume title is the first child.
 	title := colonBlock.FirstChild()
 	if title == nil {
-		return
+		return nil
 	}
 	attrs.AddClass(title, "preview-title")
 	titleLink := ast.NewLink()
@@ -126,9 +189,9 @@ func renderPreview(pc parser.Context, origDest string, reader text.Reader, link
 	title.SetAttributeString(attrs.CustomTagAttr, "div")
 	titleHTML := &bytes.Buffer{}
 	if err := renderer.Render(titleHTML, reader.Source(), title); err != nil {
-		panic(fmt.Sprintf("render preview title to HTML for %s: %s", mdctx.GetFilePath(pc), err.Error()))
+		return fmt.Errorf("render preview title to HTML: %w", err)
 	}
-	link.SetAttribute([]byte("class"), []byte("preview-target"))
+	attrs.AddClass(link, "preview-target")
 	link.SetAttribute([]byte("data-preview-title"), bytes.Trim(titleHTML.Bytes(), " \n"))
 
 	// Assume the rest of the children are the body.
@@ -136,20 +199,55 @@ func renderPreview(pc parser.Context, origDest string, reader text.Reader, link
 	snippetNode := title.NextSibling()
 	for snippetNode != nil {
 		if err := renderer.Render(snippetHTML, reader.Source(), snippetNode); err != nil {
-			panic(fmt.Sprintf("render preview snippet to HTML for %s: %s", mdctx.GetFilePath(pc), err.Error()))
+			return fmt.Errorf("render preview snippet to HTML: %w", err)
 		}
 		snippetNode = snippetNode.NextSibling()
 	}
 	link.SetAttribute([]byte("data-preview-snippet"), bytes.Trim(snippetHTML.Bytes(), " \n"))
+	return nil
+}
+
+// renderCachedPreview renders the cached preview of an http link, if any, into
+// the link attributes. The cache file is a dependency even if missing so that
+// fetching the preview rebuilds the post.
+func (l linkDecorationTransform) renderCachedPreview(pc parser.Context, origDest string, link *ast.Link) error {
+	if l.cache == nil || !linkio.IsPreviewable(origDest) {
+		return nil
+	}
+	mdctx.AddDependency(pc, l.cache.Path(origDest))
+	r, ok, err := l.cache.Get(origDest)
+	if err != nil {
+		return fmt.Errorf("link preview for %s: %w", origDest, err)
+	}
+	if !ok || r.SnippetHTML == "" {
+		return nil
+	}
+	title := r.Title
+	if title == "" {
+		title = origDest
+	}
+	titleHTML := `<div class="preview-title"><a href="` + html.EscapeString(origDest) + `">` +
+		html.EscapeString(title) + `</a></div>`
+	attrs.AddClass(link, "preview-target")
+	link.SetAttribute([]byte("data-preview-title"), []byte(titleHTML))
+	link.SetAttribute([]byte("data-preview-snippet"), []byte(r.SnippetHTML))
+	return nil
 }
 
-type LinkExt struct{}
+type LinkExt struct {
+	cache   *linkio.Cache
+	archive *linkio.Archive
+}
 
-func NewLinkExt() *LinkExt {
-	return &LinkExt{}
+// NewLinkExt creates the link extension. Links without a "::: preview" colon
+// block use the preview in the cache, if any. Dead links in the archive link
+// to an archived copy. A nil cache disables cached previews; a nil archive
+// disables archived copies.
+func NewLinkExt(cache *linkio.Cache, archive *linkio.Archive) *LinkExt {
+	return &LinkExt{cache: cache, archive: archive}
 }
 
 func (l *LinkExt) Extend(m goldmark.Markdown) {
-	extenders.AddASTTransform(m, &linkDecorationTransform{}, ord.LinkDecorationTransformer)
+	extenders.AddASTTransform(m, &linkDecorationTransform{cache: l.cache, archive: l.archive}, ord.LinkDecorationTransformer)
 	extenders.AddASTTransform(m, &linkAssetTransformer{}, ord.LinkAssetTransformer)
 }
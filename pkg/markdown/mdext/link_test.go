@@ -2,10 +2,12 @@ package mdext
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jschaf/jsc/pkg/htmls/tags"
 	"github.com/jschaf/jsc/pkg/markdown/assets"
+	"github.com/jschaf/jsc/pkg/markdown/linkio"
 	"github.com/jschaf/jsc/pkg/markdown/mdctx"
 	"github.com/jschaf/jsc/pkg/markdown/mdtest"
 
@@ -78,7 +80,7 @@ func TestNewLinkExt_context(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			md, ctx := mdtest.NewTester(t,
-				NewColonBlockExt(), NewTOMLExt(), NewLinkExt(), NewParagraphExt())
+				NewColonBlockExt(), NewTOMLExt(), NewLinkExt(nil, nil), NewParagraphExt())
 			mdctx.SetFilePath(ctx, path)
 
 			doc := mdtest.MustParseMarkdown(t, md, ctx, tt.src)
@@ -121,11 +123,35 @@ func TestNewLinkExt_Preview(t *testing.T) {
 				),
 			),
 		},
+		{
+			"link with preview and class",
+			texts.Dedent(`
+				[wiki link](https://en.wikipedia.org/wiki/Wiki){.big}
+
+				::: preview https://en.wikipedia.org/wiki/Wiki
+				preview title
+
+				foo bar
+				:::
+      `),
+			tags.Join(
+				tags.P(
+					tags.AAttrs(
+						tags.Attrs(
+							`href="https://en.wikipedia.org/wiki/Wiki"`,
+							`class="big preview-target"`,
+							"data-link-type=wikipedia",
+							`data-preview-title="<div class=&quot;preview-title&quot;><a href=&quot;https://en.wikipedia.org/wiki/Wiki&quot;>preview title</a></div>"`,
+							`data-preview-snippet="<p>foo bar</p>"`),
+						"wiki link"),
+				),
+			),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			md, ctx := mdtest.NewTester(t,
-				NewColonBlockExt(), NewTOMLExt(), NewLinkExt(), NewParagraphExt())
+				NewAttributesExt(), NewColonBlockExt(), NewTOMLExt(), NewLinkExt(nil, nil), NewParagraphExt())
 			mdctx.SetFilePath(ctx, path)
 
 			doc := mdtest.MustParseMarkdown(t, md, ctx, tt.src)
@@ -133,3 +159,91 @@ func TestNewLinkExt_Preview(t *testing.T) {
 		})
 	}
 }
+
+func TestNewLinkExt_CachedPreview(t *testing.T) {
+	const path = "/home/joe/file.md"
+	cache := linkio.NewCache(t.TempDir())
+	err := cache.Put(linkio.FetchResult{
+		Path:        "https://en.wikipedia.org/wiki/Wiki",
+		Title:       "Wiki",
+		SnippetHTML: "<p>A wiki.</p>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := texts.Dedent(`
+		[wiki link](https://en.wikipedia.org/wiki/Wiki) and [other](https://example.com)
+  `)
+	want := tags.Join(
+		tags.P(
+			tags.AAttrs(
+				tags.Attrs(
+					`href="https://en.wikipedia.org/wiki/Wiki"`,
+					"data-link-type=wikipedia",
+					`class="preview-target"`,
+					`data-preview-title="<div class=&quot;preview-title&quot;><a href=&quot;https://en.wikipedia.org/wiki/Wiki&quot;>Wiki</a></div>"`,
+					`data-preview-snippet="<p>A wiki.</p>"`),
+				"wiki link"),
+			"and",
+			tags.AAttrs(`href="https://example.com"`, "other"),
+		),
+	)
+	md, ctx := mdtest.NewTester(t,
+		NewColonBlockExt(), NewTOMLExt(), NewLinkExt(cache, nil), NewParagraphExt())
+	mdctx.SetFilePath(ctx, path)
+
+	doc := mdtest.MustParseMarkdown(t, md, ctx, src)
+	mdtest.AssertNoRenderDiff(t, doc, md, src, want)
+	wantDeps := []string{
+		cache.Path("https://en.wikipedia.org/wiki/Wiki"),
+		cache.Path("https://example.com"),
+	}
+	if diff := cmp.Diff(wantDeps, mdctx.GetDependencies(ctx)); diff != "" {
+		t.Errorf("dependencies mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewLinkExt_ArchivedCopy(t *testing.T) {
+	const path = "/home/joe/file.md"
+	archive := linkio.NewArchive(t.TempDir())
+	alive := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, c := range []linkio.CheckResult{
+		{URL: "https://example.com/dead", Time: alive, StatusCode: 200},
+		{URL: "https://example.com/dead", Time: alive.AddDate(1, 0, 0), StatusCode: 404},
+		{URL: "https://example.com/ok", Time: alive, StatusCode: 200},
+		// One timeout doesn't mean the link is dead.
+		{URL: "https://example.com/slow", Time: alive, StatusCode: 200},
+		{URL: "https://example.com/slow", Time: alive.AddDate(1, 0, 0), Err: "i/o timeout"},
+	} {
+		if _, err := archive.Put(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	src := texts.Dedent(`
+		A [dead link](https://example.com/dead), [slow](https://example.com/slow) and [alive](https://example.com/ok).
+  `)
+	want := tags.Join(
+		tags.P(
+			"A",
+			tags.AAttrs(`href="https://example.com/dead"`, "dead link"),
+			"(",
+			tags.AAttrs(
+				tags.Attrs(
+					`href="https://web.archive.org/web/20200102030405/https://example.com/dead"`,
+					`class="archived-link"`,
+					"data-link-type=archive"),
+				"archived copy"),
+			"),",
+			tags.AAttrs(`href="https://example.com/slow"`, "slow"),
+			"and",
+			tags.AAttrs(`href="https://example.com/ok"`, "alive"),
+			".",
+		),
+	)
+	md, ctx := mdtest.NewTester(t,
+		NewColonBlockExt(), NewTOMLExt(), NewLinkExt(nil, archive), NewParagraphExt())
+	mdctx.SetFilePath(ctx, path)
+
+	doc := mdtest.MustParseMarkdown(t, md, ctx, src)
+	mdtest.AssertNoRenderDiff(t, doc, md, src, want)
+}
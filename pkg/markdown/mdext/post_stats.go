@@ -0,0 +1,92 @@
+package mdext
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/jschaf/jsc/pkg/markdown/extenders"
+	"github.com/jschaf/jsc/pkg/markdown/ord"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+var KindPostStats = ast.NewNodeKind("PostStats")
+
+// PostStats marks where the article header shows the reading time and last
+// modified date of the post. The node holds no data since the last modified
+// date comes from the git history, which isn't known while parsing.
+type PostStats struct {
+	ast.BaseBlock
+}
+
+func NewPostStats() *PostStats {
+	return &PostStats{}
+}
+
+func (p *PostStats) Dump(source []byte, level int) {
+	ast.DumpHelper(p, source, level, nil, nil)
+}
+
+func (p *PostStats) Kind() ast.NodeKind {
+	return KindPostStats
+}
+
+// PostStatsData is the reading time and last modified date of a post.
+type PostStatsData struct {
+	ReadingMinutes int
+	// Updated is the date the post last changed. Zero if the post hasn't
+	// changed since the day it was published.
+	Updated time.Time
+}
+
+// PostStatsFunc returns the stats of the post with the document node. Returns
+// false to show no stats.
+type PostStatsFunc func(doc ast.Node) (PostStatsData, bool)
+
+// postStatsRenderer renders the stats of a post as a line in the header.
+type postStatsRenderer struct {
+	stats PostStatsFunc
+}
+
+func (r postStatsRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindPostStats, r.render)
+}
+
+func (r postStatsRenderer) render(w util.BufWriter, _ []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering || r.stats == nil {
+		return ast.WalkSkipChildren, nil
+	}
+	doc := node
+	for doc.Parent() != nil {
+		doc = doc.Parent()
+	}
+	s, ok := r.stats(doc)
+	if !ok {
+		return ast.WalkSkipChildren, nil
+	}
+	_, _ = w.WriteString("<p class=\"post-stats\">")
+	_, _ = w.WriteString(strconv.Itoa(s.ReadingMinutes))
+	_, _ = w.WriteString(" min read")
+	if !s.Updated.IsZero() {
+		_, _ = w.WriteString(" · Last modified ")
+		writeTimeTag(w, s.Updated.UTC())
+	}
+	_, _ = w.WriteString("</p>\n")
+	return ast.WalkSkipChildren, nil
+}
+
+// PostStatsExt is the Goldmark extension to render the stats of a post in the
+// article header. A nil stats func renders nothing, like for the index pages.
+type PostStatsExt struct {
+	stats PostStatsFunc
+}
+
+func NewPostStatsExt(stats PostStatsFunc) *PostStatsExt {
+	return &PostStatsExt{stats: stats}
+}
+
+func (e *PostStatsExt) Extend(m goldmark.Markdown) {
+	extenders.AddRenderer(m, postStatsRenderer{stats: e.stats}, ord.PostStatsRenderer)
+}
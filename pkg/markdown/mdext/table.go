@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/jschaf/jsc/pkg/markdown/asts"
+	"github.com/jschaf/jsc/pkg/markdown/diag"
 	"github.com/jschaf/jsc/pkg/markdown/extenders"
 	"github.com/jschaf/jsc/pkg/markdown/mdctx"
 	"github.com/jschaf/jsc/pkg/markdown/ord"
@@ -82,7 +83,7 @@ func (t tableCaptionTransformer) Transform(doc *ast.Document, r text.Reader, pc
 		return ast.WalkSkipChildren, nil
 	})
 	if err != nil {
-		mdctx.PushError(pc, fmt.Errorf("table caption transform walk: %w", err))
+		mdctx.PushErrorAt(pc, "table", diag.NoOffset, fmt.Errorf("table caption transform walk: %w", err))
 	}
 }
 
@@ -95,6 +96,31 @@ func isTableCaption(n ast.Node, r text.Reader) bool {
 	return strings.HasPrefix(s, tableCaptionMarker)
 }
 
+// tableAlignTransformer is an AST transformer that converts the alignment of
+// each table cell into a style attribute. The goldmark table renderer appends
+// the alignment style to the cell attributes at render time, so rendering the
+// same AST twice, like for the index and detail pages, duplicates the style.
+type tableAlignTransformer struct{}
+
+func (t tableAlignTransformer) Transform(doc *ast.Document, _ text.Reader, pc parser.Context) {
+	err := asts.WalkKind(ast2.KindTableCell, doc, func(n ast.Node) (ast.WalkStatus, error) {
+		cell := n.(*ast2.TableCell)
+		if cell.Alignment == ast2.AlignNone {
+			return ast.WalkSkipChildren, nil
+		}
+		style := "text-align:" + cell.Alignment.String()
+		if v, ok := cell.AttributeString("style"); ok {
+			style = string(v.([]byte)) + ";" + style
+		}
+		cell.SetAttributeString("style", []byte(style))
+		cell.Alignment = ast2.AlignNone
+		return ast.WalkSkipChildren, nil
+	})
+	if err != nil {
+		mdctx.PushErrorAt(pc, "table", diag.NoOffset, fmt.Errorf("table align transform walk: %w", err))
+	}
+}
+
 type tableCaptionRenderer struct{}
 
 func (t tableCaptionRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
@@ -123,6 +149,7 @@ func NewTableExt() TableExt {
 func (t TableExt) Extend(m goldmark.Markdown) {
 	extenders.AddParaTransform(m, extension.NewTableParagraphTransformer(), ord.TableParaTransformer)
 	extenders.AddASTTransform(m, tableCaptionTransformer{}, ord.TableCaptionTransformer)
+	extenders.AddASTTransform(m, tableAlignTransformer{}, ord.TableAlignTransformer)
 	extenders.AddRenderer(m, extension.NewTableHTMLRenderer(), ord.TableRenderer)
 	extenders.AddRenderer(m, tableCaptionRenderer{}, ord.TableCaptionRenderer)
 }
@@ -105,3 +105,32 @@ func TestNewTableExt(t *testing.T) {
 		})
 	}
 }
+
+func TestNewTableExt_AlignRenderTwice(t *testing.T) {
+	src := texts.Dedent(`
+    | head 1 | head 2 |
+    |--------|-------:|
+    | val 1  | val 2  |
+	`)
+	want := texts.Dedent(`
+		<table>
+			<thead>
+			<tr>
+				<th>head 1</th>
+				<th style="text-align:right">head 2</th>
+			</tr>
+			</thead>
+			<tbody>
+			<tr>
+				<td>val 1</td>
+				<td style="text-align:right">val 2</td>
+			</tr>
+			</tbody>
+		</table>
+	`)
+	md, ctx := mdtest.NewTester(t, NewTableExt())
+	doc := mdtest.MustParseMarkdown(t, md, ctx, src)
+	// The index and detail compilers render the same AST.
+	mdtest.AssertNoRenderDiff(t, doc, md, src, want)
+	mdtest.AssertNoRenderDiff(t, doc, md, src, want)
+}
@@ -4,12 +4,18 @@ package mdext
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/jschaf/jsc/pkg/dirs"
 	"github.com/jschaf/jsc/pkg/git"
+	"github.com/jschaf/jsc/pkg/markdown/assets"
+	"github.com/jschaf/jsc/pkg/markdown/diag"
 	"github.com/jschaf/jsc/pkg/markdown/extenders"
 	"github.com/jschaf/jsc/pkg/markdown/mdctx"
 	"github.com/jschaf/jsc/pkg/markdown/ord"
@@ -26,23 +32,86 @@ const tomlSep = '+'
 
 const (
 	VisibilityPublished = "published"
+	VisibilityDraft     = "draft"
 )
 
-// PostMeta is the TOML metadata of a post.
+// tagRegexp matches a valid tag, like "distributed-systems".
+var tagRegexp = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// DraftPathPrefix is the URL path prefix for draft posts. Drafts are only
+// built for previews and aren't linked from any page.
+const DraftPathPrefix = "/drafts/"
+
+// PostMeta is the TOML metadata of a post. Fields without a TOML key are
+// computed and can't be set in the frontmatter.
 type PostMeta struct {
 	// The slug from the markdown frontmatter.
-	Slug string
+	Slug string `toml:"slug"`
 	// The absolute URL path for this post, e.g. "/foo-bar". Has trailing slash.
-	Path string
+	Path string `toml:"-"`
 	// The title extracted from the first header.
-	Title     string
-	TitleNode ast.Node
+	Title     string   `toml:"-"`
+	TitleNode ast.Node `toml:"-"`
 	// The date from the markdown frontmatter.
-	Date time.Time
+	Date time.Time `toml:"date"`
 	// Either draft or published.
-	Visibility string
+	Visibility string `toml:"visibility"`
+	// The time a published post goes live. Until then, the post is built as
+	// a draft. Zero means the post is live immediately.
+	PublishAt time.Time `toml:"publish_at"`
 	// Paths (relative or absolute) to bibtex files to resolve references.
 	BibPaths []string `toml:"bib_paths"`
+	// Tags classify the post, like "postgres". Each tag has a listing page at
+	// /tags/<tag>/. Tags are lowercase words separated by hyphens.
+	Tags []string `toml:"tags"`
+	// Description summarizes the post for link previews and search engines.
+	// Defaults to the text of the first paragraph.
+	Description string `toml:"description"`
+	// Image is the preview image for link previews. A relative path is
+	// resolved from the post dir and copied to the dist dir. After parsing,
+	// Image is the absolute URL path or URL of the image. Defaults to the
+	// first figure.
+	Image string `toml:"image"`
+	// Updated is the date of the last substantive change to the post, shown
+	// in the header. Defaults to the date of the latest change.
+	Updated time.Time `toml:"updated"`
+	// Changes are the substantive changes to the post, like corrections,
+	// shown in a changelog at the end of the article.
+	Changes []Change `toml:"changes"`
+	// Aliases are old URL paths of the post, like "/old-slug/", that redirect
+	// to the post with a 301. After parsing, each alias is a clean path with
+	// a trailing slash, like Path.
+	Aliases []string `toml:"aliases"`
+	// Series groups a multi-part post with the other parts, which link to
+	// each other and have a listing page at /series/<series>/. The series is
+	// lowercase words separated by hyphens, like a tag.
+	Series string `toml:"series"`
+	// SeriesOrder is the 1-based position of the post in the series. Zero
+	// means the post is ordered by date after the posts with an order.
+	SeriesOrder int `toml:"series_order"`
+}
+
+// Change is a substantive change to a post announced in the changelog.
+type Change struct {
+	Date time.Time `toml:"date"`
+	// Note is Markdown describing the change.
+	Note string `toml:"note"`
+}
+
+// PageURL returns the URL path of the detail page of the post, like
+// "/foo-bar/". Differs from Path for published TIL posts, whose assets live
+// under "/til/<slug>/" while the page is served from the site root.
+func (m PostMeta) PageURL() string {
+	if strings.HasPrefix(m.Path, "/"+dirs.TIL+"/") {
+		return "/" + m.Slug + "/"
+	}
+	return m.Path
+}
+
+// IsScheduled returns true if the post is published but not live until after
+// now.
+func (m PostMeta) IsScheduled(now time.Time) bool {
+	return m.Visibility == VisibilityPublished && now.Before(m.PublishAt)
 }
 
 var tomlCtxKey = parser.NewContextKey()
@@ -70,8 +139,14 @@ func newTOMLParser() parser.BlockParser {
 	return defaultTOMLMetaParser
 }
 
+// isTOMLSep returns true if the line is a front matter separator, like
+// "+++". A blank line isn't a separator so the front matter can separate
+// tables with blank lines.
 func isTOMLSep(line []byte) bool {
 	line = util.TrimRightSpace(util.TrimLeftSpace(line))
+	if len(line) < 3 {
+		return false
+	}
 	for i := 0; i < len(line); i++ {
 		if line[i] != tomlSep {
 			return false
@@ -114,17 +189,44 @@ func (t *tomlParser) Close(node ast.Node, reader text.Reader, pc parser.Context)
 		buf.Write(segment.Value(reader.Source()))
 	}
 	meta := &PostMeta{}
-	if err := toml.Unmarshal(buf.Bytes(), &meta); err != nil {
-		panic(err)
+	md, err := toml.Decode(buf.String(), meta)
+	if err != nil {
+		mdctx.PushErrorAt(pc, "toml", tomlErrOffset(lines, err), tomlErr(err))
+		node.Parent().RemoveChild(node.Parent(), node)
+		return
+	}
+	validateTOMLMeta(pc, reader.Source(), md, meta)
+	for _, c := range meta.Changes {
+		if !md.IsDefined("updated") && c.Date.After(meta.Updated) {
+			meta.Updated = c.Date
+		}
+	}
+	for i, a := range meta.Aliases {
+		meta.Aliases[i] = path.Clean(a) + "/"
+	}
+	// Decide the visibility before deriving paths so a scheduled post's assets
+	// stay under the drafts dir until it goes live.
+	if now := mdctx.GetNow(pc); !now.IsZero() && meta.IsScheduled(now) {
+		meta.Visibility = VisibilityDraft
 	}
 	switch {
+	case meta.Visibility == VisibilityDraft:
+		meta.Path = DraftPathPrefix + meta.Slug + "/"
 	case strings.Contains(mdctx.GetFilePath(pc), `/`+dirs.TIL+`/`):
-		meta.Path = "/til/" + meta.Slug + "/"
+		meta.Path = "/" + dirs.TIL + "/" + meta.Slug + "/"
 	default:
 		meta.Path = "/" + meta.Slug + "/"
 	}
 
 	postPath := mdctx.GetFilePath(pc)
+	if img := meta.Image; img != "" && !path.IsAbs(img) && !strings.HasPrefix(img, "http") {
+		meta.Image = path.Join(meta.Path, img)
+		mdctx.AddAsset(pc, assets.Blob{
+			Src:  filepath.Join(filepath.Dir(postPath), img),
+			Dest: meta.Image,
+		})
+	}
+
 	root := git.RootDir()
 	for i, bib := range meta.BibPaths {
 		if filepath.IsAbs(bib) {
@@ -134,6 +236,7 @@ func (t *tomlParser) Close(node ast.Node, reader text.Reader, pc parser.Context)
 			// Relative starts from the post dir.
 			meta.BibPaths[i] = filepath.Join(filepath.Dir(postPath), bib)
 		}
+		mdctx.AddDependency(pc, meta.BibPaths[i])
 	}
 
 	SetTOMLMeta(pc, *meta)
@@ -141,6 +244,153 @@ func (t *tomlParser) Close(node ast.Node, reader text.Reader, pc parser.Context)
 	node.Parent().RemoveChild(node.Parent(), node)
 }
 
+// validateTOMLMeta reports unknown keys and invalid values in the
+// frontmatter. Required fields are checked by the catalog since tests parse
+// posts with partial frontmatter.
+func validateTOMLMeta(pc parser.Context, src []byte, md toml.MetaData, meta *PostMeta) {
+	for _, key := range md.Undecoded() {
+		mdctx.PushErrorAt(pc, "toml", FrontMatterKeyOffset(src, key[0]),
+			fmt.Errorf("unknown front matter key %q", key.String()))
+	}
+	switch meta.Visibility {
+	case "", VisibilityPublished, VisibilityDraft:
+	default:
+		mdctx.PushErrorAt(pc, "toml", FrontMatterKeyOffset(src, "visibility"),
+			fmt.Errorf("invalid visibility %q; want %q or %q", meta.Visibility, VisibilityPublished, VisibilityDraft))
+	}
+	if !meta.PublishAt.IsZero() && meta.Visibility == VisibilityDraft {
+		mdctx.PushWarningAt(pc, "toml", FrontMatterKeyOffset(src, "publish_at"),
+			fmt.Errorf("publish_at has no effect on a draft; set visibility to %q to schedule the post", VisibilityPublished))
+	}
+	seenTags := make(map[string]bool, len(meta.Tags))
+	for _, tag := range meta.Tags {
+		switch {
+		case !tagRegexp.MatchString(tag):
+			mdctx.PushErrorAt(pc, "toml", FrontMatterKeyOffset(src, "tags"),
+				fmt.Errorf("invalid tag %q; want lowercase words separated by hyphens", tag))
+		case seenTags[tag]:
+			mdctx.PushErrorAt(pc, "toml", FrontMatterKeyOffset(src, "tags"),
+				fmt.Errorf("duplicate tag %q", tag))
+		}
+		seenTags[tag] = true
+	}
+	if strings.Contains(meta.Description, "\n") {
+		mdctx.PushErrorAt(pc, "toml", FrontMatterKeyOffset(src, "description"),
+			errors.New("description must be a single line"))
+	}
+	if !meta.Updated.IsZero() && meta.Updated.Before(meta.Date) {
+		mdctx.PushErrorAt(pc, "toml", FrontMatterKeyOffset(src, "updated"),
+			fmt.Errorf("updated %s is before the post date %s", meta.Updated.Format(time.DateOnly), meta.Date.Format(time.DateOnly)))
+	}
+	for i, c := range meta.Changes {
+		switch {
+		case c.Date.IsZero() || strings.TrimSpace(c.Note) == "":
+			mdctx.PushErrorAt(pc, "toml", FrontMatterKeyOffset(src, "changes"),
+				fmt.Errorf("changes[%d]: want date and note", i))
+		case c.Date.Before(meta.Date):
+			mdctx.PushErrorAt(pc, "toml", FrontMatterKeyOffset(src, "changes"),
+				fmt.Errorf("changes[%d]: date %s is before the post date %s", i, c.Date.Format(time.DateOnly), meta.Date.Format(time.DateOnly)))
+		case md.IsDefined("updated") && c.Date.After(meta.Updated):
+			mdctx.PushErrorAt(pc, "toml", FrontMatterKeyOffset(src, "updated"),
+				fmt.Errorf("updated %s is before changes[%d] on %s", meta.Updated.Format(time.DateOnly), i, c.Date.Format(time.DateOnly)))
+		}
+	}
+	for _, a := range meta.Aliases {
+		if !isAliasPath(a) {
+			mdctx.PushErrorAt(pc, "toml", FrontMatterKeyOffset(src, "aliases"),
+				fmt.Errorf("invalid alias %q; want an absolute URL path like \"/old-slug/\"", a))
+		}
+	}
+	if meta.Series != "" && !tagRegexp.MatchString(meta.Series) {
+		mdctx.PushErrorAt(pc, "toml", FrontMatterKeyOffset(src, "series"),
+			fmt.Errorf("invalid series %q; want lowercase words separated by hyphens", meta.Series))
+	}
+	switch {
+	case md.IsDefined("series_order") && meta.Series == "":
+		mdctx.PushErrorAt(pc, "toml", FrontMatterKeyOffset(src, "series_order"),
+			errors.New("series_order has no effect without a series"))
+	case meta.SeriesOrder < 0 || md.IsDefined("series_order") && meta.SeriesOrder == 0:
+		mdctx.PushErrorAt(pc, "toml", FrontMatterKeyOffset(src, "series_order"),
+			fmt.Errorf("invalid series_order %d; want a positive number", meta.SeriesOrder))
+	}
+	if md.IsDefined("slug") && meta.Slug == "" {
+		mdctx.PushErrorAt(pc, "toml", FrontMatterKeyOffset(src, "slug"), errors.New("empty slug"))
+	}
+}
+
+// isAliasPath returns true if the alias is an absolute URL path without a
+// query, fragment or relative segments, like "/old-slug/".
+func isAliasPath(a string) bool {
+	if !strings.HasPrefix(a, "/") || a == "/" || strings.ContainsAny(a, "?#*{} \t") {
+		return false
+	}
+	return path.Clean(a) == strings.TrimSuffix(a, "/")
+}
+
+// FrontMatterKeyOffset returns the offset in src of the line that defines the
+// top-level key in the TOML frontmatter, either as a key-value pair or as the
+// first table header, like [[key]]. Returns 0, the start of the file, if the
+// key isn't defined.
+func FrontMatterKeyOffset(src []byte, key string) int {
+	offset := 0
+	for i, line := range bytes.SplitAfter(src, []byte{'\n'}) {
+		isSep := bytes.HasPrefix(bytes.TrimSpace(line), []byte("+++"))
+		if (i == 0) != isSep {
+			break
+		}
+		rest, ok := bytes.CutPrefix(bytes.TrimLeft(line, " \t"), []byte(key))
+		if ok && bytes.HasPrefix(bytes.TrimLeft(rest, " \t"), []byte{'='}) {
+			return offset + bytes.Index(line, []byte(key))
+		}
+		if name := bytes.Trim(bytes.TrimSpace(line), "[] \t"); bytes.HasPrefix(bytes.TrimSpace(line), []byte{'['}) && string(name) == key {
+			return offset + bytes.IndexByte(line, '[')
+		}
+		offset += len(line)
+	}
+	return 0
+}
+
+// tomlErrOffset returns the offset in the Markdown source of a TOML parse
+// error. Returns the start of the front matter if the error has no position.
+func tomlErrOffset(lines *text.Segments, err error) int {
+	if lines.Len() == 0 {
+		return diag.NoOffset
+	}
+	var pe toml.ParseError
+	if !errors.As(err, &pe) {
+		return lines.At(0).Start
+	}
+	// The TOML is the concatenation of the lines, so find the line that
+	// contains the error offset.
+	rest := pe.Position.Start
+	for i := 0; i < lines.Len(); i++ {
+		seg := lines.At(i)
+		if rest < seg.Len() {
+			return seg.Start + rest
+		}
+		rest -= seg.Len()
+	}
+	return lines.At(lines.Len() - 1).Stop
+}
+
+// tomlErr returns a TOML parse error without the line number, which is
+// relative to the front matter instead of the Markdown file.
+func tomlErr(err error) error {
+	var pe toml.ParseError
+	if !errors.As(err, &pe) {
+		return fmt.Errorf("parse front matter: %w", err)
+	}
+	prefix := fmt.Sprintf("toml: line %d", pe.Position.Line)
+	if pe.LastKey != "" {
+		prefix += fmt.Sprintf(" (last key %q)", pe.LastKey)
+	}
+	msg := strings.TrimPrefix(pe.Error(), prefix+": ")
+	if pe.LastKey != "" {
+		return fmt.Errorf("parse front matter: key %q: %s", pe.LastKey, msg)
+	}
+	return fmt.Errorf("parse front matter: %s", msg)
+}
+
 func (t *tomlParser) CanInterruptParagraph() bool {
 	return false
 }
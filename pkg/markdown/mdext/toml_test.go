@@ -6,10 +6,13 @@ import (
 	"time"
 
 	"github.com/jschaf/jsc/pkg/git"
+	"github.com/jschaf/jsc/pkg/markdown/mdctx"
 	"github.com/jschaf/jsc/pkg/markdown/mdtest"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/jschaf/jsc/pkg/texts"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
 )
 
 func TestMeta(t *testing.T) {
@@ -40,6 +43,48 @@ func TestMeta(t *testing.T) {
 				BibPaths: []string{"/md/test/ref.bib", filepath.Join(root, "r1/r2.bib")},
 			},
 		},
+		{
+			"draft",
+			texts.Dedent(`
+				+++
+				slug = "a_slug"
+				date = 2019-09-20
+				visibility = "draft"
+				+++
+				# Draft
+      `),
+			texts.Dedent(`
+        <h1>Draft</h1>
+      `),
+			PostMeta{
+				Path:       "/drafts/a_slug/",
+				Slug:       "a_slug",
+				Date:       time.Date(2019, time.September, 20, 0, 0, 0, 0, time.Local),
+				Visibility: VisibilityDraft,
+			},
+		},
+		{
+			"description and relative image",
+			texts.Dedent(`
+				+++
+				slug = "a_slug"
+				date = 2019-09-20
+				description = "A summary."
+				image = "cover.png"
+				+++
+				# Image
+      `),
+			texts.Dedent(`
+        <h1>Image</h1>
+      `),
+			PostMeta{
+				Path:        "/a_slug/",
+				Slug:        "a_slug",
+				Date:        time.Date(2019, time.September, 20, 0, 0, 0, 0, time.Local),
+				Description: "A summary.",
+				Image:       "/a_slug/cover.png",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -59,3 +104,148 @@ func cmpTimeDate() cmp.Option {
 		return t.Format(time.DateOnly)
 	})
 }
+
+func TestMeta_InvalidTOML(t *testing.T) {
+	src := texts.Dedent(`
+    +++
+    slug = "a_slug"
+    date = 2019-09-20
+    visibility = draft
+    +++
+    # Hello goldmark-meta
+  `)
+	md, ctx := mdtest.NewTester(t, NewTOMLExt())
+	md.Parser().Parse(text.NewReader([]byte(src)), parser.WithContext(ctx))
+	diags := mdctx.PopDiagnostics(ctx)
+	if len(diags) != 1 {
+		t.Fatalf("want 1 diagnostic; got %v", diags)
+	}
+	d := diags[0]
+	d.Resolve("post.md", []byte(src))
+	want := `post.md:4:14: toml: parse front matter: key "visibility": expected value but found "draft" instead`
+	if d.Error() != want {
+		t.Errorf("TOML diagnostic mismatch:\nwant: %s\ngot:  %s", want, d.Error())
+	}
+}
+
+func TestMeta_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			"unknown key",
+			"+++\nslug = \"a\"\nauthor = \"me\"\n+++\n# Hi\n",
+			[]string{`post.md:3:1: toml: unknown front matter key "author"`},
+		},
+		{
+			"computed key",
+			"+++\nslug = \"a\"\npath = \"/b/\"\n+++\n# Hi\n",
+			[]string{`post.md:3:1: toml: unknown front matter key "path"`},
+		},
+		{
+			"invalid visibility",
+			"+++\nslug = \"a\"\nvisibility = \"public\"\n+++\n# Hi\n",
+			[]string{`post.md:3:1: toml: invalid visibility "public"; want "published" or "draft"`},
+		},
+		{
+			"empty slug",
+			"+++\nslug = \"\"\n+++\n# Hi\n",
+			[]string{`post.md:2:1: toml: empty slug`},
+		},
+		{
+			"invalid tags",
+			"+++\nslug = \"a\"\ntags = [\"Go\", \"go\", \"go\"]\n+++\n# Hi\n",
+			[]string{
+				`post.md:3:1: toml: invalid tag "Go"; want lowercase words separated by hyphens`,
+				`post.md:3:1: toml: duplicate tag "go"`,
+			},
+		},
+		{
+			"publish_at on draft",
+			"+++\nslug = \"a\"\nvisibility = \"draft\"\npublish_at = 2020-01-02\n+++\n# Hi\n",
+			[]string{`post.md:4:1: toml: publish_at has no effect on a draft; set visibility to "published" to schedule the post`},
+		},
+		{
+			"multiline description",
+			"+++\nslug = \"a\"\ndescription = \"\"\"\nfoo\nbar\"\"\"\n+++\n# Hi\n",
+			[]string{`post.md:3:1: toml: description must be a single line`},
+		},
+		{
+			"invalid aliases",
+			"+++\nslug = \"a\"\naliases = [\"old\", \"/a/../b/\", \"/ok/\"]\n+++\n# Hi\n",
+			[]string{
+				`post.md:3:1: toml: invalid alias "old"; want an absolute URL path like "/old-slug/"`,
+				`post.md:3:1: toml: invalid alias "/a/../b/"; want an absolute URL path like "/old-slug/"`,
+			},
+		},
+		{
+			"invalid series",
+			"+++\nslug = \"a\"\nseries = \"Perf Analysis\"\nseries_order = 0\n+++\n# Hi\n",
+			[]string{
+				`post.md:3:1: toml: invalid series "Perf Analysis"; want lowercase words separated by hyphens`,
+				`post.md:4:1: toml: invalid series_order 0; want a positive number`,
+			},
+		},
+		{
+			"series_order without series",
+			"+++\nslug = \"a\"\nseries_order = 2\n+++\n# Hi\n",
+			[]string{`post.md:3:1: toml: series_order has no effect without a series`},
+		},
+		{
+			"updated before date",
+			"+++\nslug = \"a\"\ndate = 2020-01-02\nupdated = 2020-01-01\n+++\n# Hi\n",
+			[]string{`post.md:4:1: toml: updated 2020-01-01 is before the post date 2020-01-02`},
+		},
+		{
+			"change without note",
+			"+++\nslug = \"a\"\ndate = 2020-01-02\n\n[[changes]]\ndate = 2020-01-03\n+++\n# Hi\n",
+			[]string{`post.md:5:1: toml: changes[0]: want date and note`},
+		},
+		{
+			"change before date",
+			"+++\nslug = \"a\"\ndate = 2020-01-02\n\n[[changes]]\ndate = 2020-01-01\nnote = \"Fix typo.\"\n+++\n# Hi\n",
+			[]string{`post.md:5:1: toml: changes[0]: date 2020-01-01 is before the post date 2020-01-02`},
+		},
+		{
+			"updated before change",
+			"+++\nslug = \"a\"\ndate = 2020-01-02\nupdated = 2020-01-03\n\n[[changes]]\ndate = 2020-01-04\nnote = \"Fix typo.\"\n+++\n# Hi\n",
+			[]string{`post.md:4:1: toml: updated 2020-01-03 is before changes[0] on 2020-01-04`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md, ctx := mdtest.NewTester(t, NewTOMLExt())
+			md.Parser().Parse(text.NewReader([]byte(tt.src)), parser.WithContext(ctx))
+			var got []string
+			for _, d := range mdctx.PopDiagnostics(ctx) {
+				d.Resolve("post.md", []byte(tt.src))
+				got = append(got, d.Error())
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("diagnostics mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMeta_TILPath(t *testing.T) {
+	src := texts.Dedent(`
+		+++
+		slug = "a_slug"
+		date = 2019-09-20
+		+++
+		# TIL
+	`)
+	md, ctx := mdtest.NewTester(t, NewTOMLExt())
+	mdctx.SetFilePath(ctx, filepath.Join(git.RootDir(), "til", "a.md"))
+	_ = mdtest.MustParseMarkdown(t, md, ctx, src)
+	meta := GetTOMLMeta(ctx)
+	if meta.Path != "/til/a_slug/" {
+		t.Errorf("Path = %q; want /til/a_slug/", meta.Path)
+	}
+	if got := meta.PageURL(); got != "/a_slug/" {
+		t.Errorf("PageURL() = %q; want /a_slug/", got)
+	}
+}
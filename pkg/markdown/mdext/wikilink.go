@@ -0,0 +1,156 @@
+package mdext
+
+import (
+	"bytes"
+
+	"github.com/jschaf/jsc/pkg/markdown/extenders"
+	"github.com/jschaf/jsc/pkg/markdown/ord"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+var KindWikiLink = ast.NewNodeKind("WikiLink")
+
+// WikiLink is a link to another post by slug:
+//
+//	[[slug]] or [[slug#heading-id]] or [[slug|link text]]
+//
+// The catalog resolves the link after parsing every post since the target is
+// another post. Until resolved, the link points to the slug and the link text
+// is the slug.
+type WikiLink struct {
+	ast.BaseInline
+	Slug string
+	// Fragment is the ID of a heading in the target post, without the "#".
+	// Empty to link to the top of the post.
+	Fragment string
+	// HasText is true if the link has explicit link text. Otherwise, the link
+	// text is the title of the target post once resolved.
+	HasText bool
+	// Dest is the URL path of the target post, set when resolved.
+	Dest string
+	// Title is the title of the target post, set when resolved.
+	Title string
+	// Offset is the byte offset of the link in the source, for diagnostics.
+	Offset int
+}
+
+func NewWikiLink() *WikiLink {
+	return &WikiLink{}
+}
+
+func (w *WikiLink) Kind() ast.NodeKind {
+	return KindWikiLink
+}
+
+func (w *WikiLink) Dump(source []byte, level int) {
+	ast.DumpHelper(w, source, level, map[string]string{"Slug": w.Slug, "Fragment": w.Fragment}, nil)
+}
+
+// Resolve points the link at the URL path of the target post. Replaces the
+// link text with the title of the target unless the link has explicit text.
+func (w *WikiLink) Resolve(dest, title string) {
+	w.Dest = dest
+	w.Title = title
+	if w.HasText {
+		return
+	}
+	w.RemoveChildren(w)
+	w.AppendChild(w, ast.NewString([]byte(title)))
+}
+
+// href returns the link destination with the fragment.
+func (w *WikiLink) href() string {
+	dest := w.Dest
+	if dest == "" {
+		dest = "/" + w.Slug
+	}
+	if w.Fragment != "" {
+		dest += "#" + w.Fragment
+	}
+	return dest
+}
+
+// wikiLinkParser is an inline parser for wiki links like [[slug#id]].
+type wikiLinkParser struct{}
+
+func (p wikiLinkParser) Trigger() []byte {
+	return []byte{'['}
+}
+
+func (p wikiLinkParser) Parse(_ ast.Node, block text.Reader, _ parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	if len(line) < 4 || line[1] != '[' {
+		return nil
+	}
+	end := bytes.Index(line[2:], []byte("]]"))
+	if end < 0 {
+		return nil
+	}
+	body := line[2 : 2+end]
+	if bytes.ContainsAny(body, "[]\n") {
+		return nil
+	}
+	target, label, hasText := bytes.Cut(body, []byte{'|'})
+	slug, fragment, _ := bytes.Cut(target, []byte{'#'})
+	slug = bytes.TrimSpace(slug)
+	if len(slug) == 0 || bytes.ContainsAny(slug, " \t/") {
+		return nil
+	}
+
+	link := NewWikiLink()
+	link.Slug = string(slug)
+	link.Fragment = string(bytes.TrimSpace(fragment))
+	link.Offset = segment.Start
+	if hasText && len(bytes.TrimSpace(label)) > 0 {
+		link.HasText = true
+		start := segment.Start + 2 + len(target) + 1
+		seg := text.NewSegment(start, start+len(label))
+		seg = seg.TrimLeftSpace(block.Source())
+		link.AppendChild(link, ast.NewTextSegment(seg.TrimRightSpace(block.Source())))
+	} else {
+		link.AppendChild(link, ast.NewString(slug))
+	}
+	block.Advance(2 + end + 2)
+	return link
+}
+
+// wikiLinkRenderer renders a wiki link as an anchor around the link text.
+type wikiLinkRenderer struct{}
+
+func (r wikiLinkRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindWikiLink, r.render)
+}
+
+func (r wikiLinkRenderer) render(w util.BufWriter, _ []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		_, _ = w.WriteString("</a>")
+		return ast.WalkContinue, nil
+	}
+	n := node.(*WikiLink)
+	_, _ = w.WriteString(`<a href="`)
+	_, _ = w.Write(util.EscapeHTML(util.URLEscape([]byte(n.href()), true)))
+	_ = w.WriteByte('"')
+	if n.Attributes() != nil {
+		html.RenderAttributes(w, n, html.LinkAttributeFilter)
+	}
+	_ = w.WriteByte('>')
+	return ast.WalkContinue, nil
+}
+
+// WikiLinkExt is the Goldmark extension to parse and render wiki links.
+type WikiLinkExt struct{}
+
+func NewWikiLinkExt() *WikiLinkExt {
+	return &WikiLinkExt{}
+}
+
+func (e *WikiLinkExt) Extend(m goldmark.Markdown) {
+	extenders.AddInlineParser(m, wikiLinkParser{}, ord.WikiLinkParser)
+	extenders.AddRenderer(m, wikiLinkRenderer{}, ord.WikiLinkRenderer)
+}
@@ -0,0 +1,57 @@
+package mdext
+
+import (
+	"testing"
+
+	"github.com/jschaf/jsc/pkg/markdown/mdtest"
+	"github.com/yuin/goldmark/ast"
+
+	"github.com/jschaf/jsc/pkg/htmls/tags"
+)
+
+func TestWikiLinkExt(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"[[foo]]", `<a href="/foo">foo</a>`},
+		{"see [[foo#bar-baz]].", `see <a href="/foo#bar-baz">foo</a>.`},
+		{"[[foo|the *foo* post]]", `<a href="/foo">the *foo* post</a>`},
+		{"[[foo | bar ]]", `<a href="/foo">bar</a>`},
+		{"[[]]", `[[]]`},
+		{"[[foo bar]]", `[[foo bar]]`},
+		{"[[foo]", `[[foo]`},
+		{"[foo](/bar)", `<a href="/bar">foo</a>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			md, ctx := mdtest.NewTester(t, NewWikiLinkExt())
+			doc := mdtest.MustParseMarkdown(t, md, ctx, tt.src)
+			mdtest.AssertNoRenderDiff(t, doc, md, tt.src, tags.P(tt.want))
+		})
+	}
+}
+
+func TestWikiLink_Resolve(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"[[foo]]", `<a href="/til/foo">Foo &amp; bar</a>`},
+		{"[[foo#qux]]", `<a href="/til/foo#qux">Foo &amp; bar</a>`},
+		{"[[foo|text]]", `<a href="/til/foo">text</a>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			md, ctx := mdtest.NewTester(t, NewWikiLinkExt())
+			doc := mdtest.MustParseMarkdown(t, md, ctx, tt.src)
+			_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+				if link, ok := n.(*WikiLink); ok && entering {
+					link.Resolve("/til/foo", "Foo & bar")
+				}
+				return ast.WalkContinue, nil
+			})
+			mdtest.AssertNoRenderDiff(t, doc, md, tt.src, tags.P(tt.want))
+		})
+	}
+}
@@ -13,7 +13,9 @@ const (
 	TOMLParser            ParserPriority = 0
 	ColonBlockParser      ParserPriority = 10
 	ColonLineParser       ParserPriority = 12
+	WikiLinkParser        ParserPriority = 19
 	FootnoteLinkParser    ParserPriority = 20
+	AttributesParser      ParserPriority = 100
 	KatexParser           ParserPriority = 150
 	ContinueReadingParser ParserPriority = 800
 	SmallCapsParser       ParserPriority = 999
@@ -25,6 +27,7 @@ const (
 )
 
 const (
+	AttributesTransformer      ASTTransformerPriority = 500
 	HeadingIdTransformer       ASTTransformerPriority = 600
 	ArticleTransformer         ASTTransformerPriority = 900
 	LinkDecorationTransformer  ASTTransformerPriority = 900
@@ -32,6 +35,7 @@ const (
 	FigureTransformer          ASTTransformerPriority = 999
 	ImageTransformer           ASTTransformerPriority = 999
 	TableCaptionTransformer    ASTTransformerPriority = 999
+	TableAlignTransformer      ASTTransformerPriority = 999
 	FootnoteBodyTransformer    ASTTransformerPriority = 1000
 	TOCTransformer             ASTTransformerPriority = 1000
 	ContinueReadingTransformer ASTTransformerPriority = 1001
@@ -47,16 +51,20 @@ const (
 	TableRenderer           RendererPriority = 500
 	TimeRenderer            RendererPriority = 500
 	ArticleRenderer         RendererPriority = 999
+	ChangelogRenderer       RendererPriority = 999
 	CitationRenderer        RendererPriority = 999
 	CodeBlockRenderer       RendererPriority = 999
 	CustomRenderer          RendererPriority = 999
 	FigureRenderer          RendererPriority = 999
 	HeaderRenderer          RendererPriority = 999
+	PostStatsRenderer       RendererPriority = 999
 	SmallCapsRenderer       RendererPriority = 999
 	ImageRenderer           RendererPriority = 500
+	AttributesRenderer      RendererPriority = 1000
 	FootnoteRenderer        RendererPriority = 1000
 	ColonBlockRenderer      RendererPriority = 1000
 	ColonLineRenderer       RendererPriority = 1000
 	TOCRenderer             RendererPriority = 1000
+	WikiLinkRenderer        RendererPriority = 1000
 	EmbedRenderer           RendererPriority = 1000
 )
@@ -0,0 +1,68 @@
+package markdown
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/jschaf/bibtex"
+	"github.com/jschaf/jsc/pkg/markdown/mdext"
+	"github.com/yuin/goldmark/ast"
+)
+
+// collectRefs returns the distinct cite keys and the distinct URL paths of
+// the site-relative links and wiki links in the document node, like "/foo",
+// both sorted. Link paths don't have a query, fragment or trailing slash.
+// Skips links to the post at the self paths, like the title link, and to its
+// assets.
+func collectRefs(node ast.Node, self ...string) ([]bibtex.CiteKey, []string) {
+	var cites []bibtex.CiteKey
+	var links []string
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch x := n.(type) {
+		case *mdext.Citation:
+			cites = append(cites, x.Key)
+		case *ast.Link:
+			p, ok := sitePath(string(x.Destination))
+			if ok && !isSelfPath(p, self) {
+				links = append(links, p)
+			}
+		case *mdext.WikiLink:
+			links = append(links, "/"+x.Slug)
+		}
+		return ast.WalkContinue, nil
+	})
+	slices.Sort(cites)
+	slices.Sort(links)
+	return slices.Compact(cites), slices.Compact(links)
+}
+
+// sitePath returns the URL path of a site-relative link destination without
+// the query, fragment and trailing slash. Returns false for links to other
+// sites and for protocol-relative links.
+func sitePath(dest string) (string, bool) {
+	if !strings.HasPrefix(dest, "/") || strings.HasPrefix(dest, "//") {
+		return "", false
+	}
+	if i := strings.IndexAny(dest, "?#"); i >= 0 {
+		dest = dest[:i]
+	}
+	if dest != "/" {
+		dest = strings.TrimSuffix(dest, "/")
+	}
+	return dest, true
+}
+
+// isSelfPath returns true if the URL path is one of the self paths or under
+// one of them.
+func isSelfPath(p string, self []string) bool {
+	for _, s := range self {
+		s = strings.TrimSuffix(s, "/")
+		if s != "" && (p == s || strings.HasPrefix(p, s+"/")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jschaf/jsc/pkg/markdown/mdext"
+	"github.com/jschaf/jsc/pkg/testing/difftest"
+	"github.com/jschaf/jsc/pkg/testing/require"
+)
+
+func TestParse_Links(t *testing.T) {
+	src := withFrontmatter(mdext.PostMeta{Slug: "foo"}, `
+    # Title
+
+    See [bar](/bar/), [bar again](/bar#intro), [query](/baz?q=1) and
+    [home](/).
+
+    Not [external](https://example.com/qux) or [relative](qux.pdf).
+  `)
+	ast, err := New().Parse("", strings.NewReader(src))
+	require.NoError(t, err)
+	difftest.AssertSame(t, []string{"/", "/bar", "/baz"}, ast.Links)
+}
@@ -0,0 +1,82 @@
+package markdown
+
+import (
+	"strings"
+	"time"
+
+	qjskatex "github.com/graemephi/goldmark-qjs-katex"
+	"github.com/jschaf/bibtex"
+	"github.com/jschaf/jsc/pkg/markdown/mdext"
+	"github.com/yuin/goldmark/ast"
+)
+
+// wordsPerMinute is the reading speed used to estimate the reading time of a
+// post. 230 words per minute is a common estimate for adults reading
+// non-fiction.
+const wordsPerMinute = 230
+
+// Stats are counts of the content of a post.
+type Stats struct {
+	// Words is the number of words of prose, excluding the title, code blocks,
+	// math and the list of references.
+	Words      int
+	CodeBlocks int
+	Figures    int
+	// Citations is the number of distinct works cited.
+	Citations int
+	HasMath   bool
+}
+
+// ReadingMinutes returns the estimated minutes to read the post, rounded up
+// to at least one minute.
+func (s Stats) ReadingMinutes() int {
+	return max(1, (s.Words+wordsPerMinute-1)/wordsPerMinute)
+}
+
+// ReadingTime returns the estimated time to read the post.
+func (s Stats) ReadingTime() time.Duration {
+	return time.Duration(s.ReadingMinutes()) * time.Minute
+}
+
+// computeStats counts the content of the document node.
+func computeStats(node ast.Node, src []byte) Stats {
+	s := Stats{}
+	cites := make(map[bibtex.CiteKey]struct{})
+	text := &strings.Builder{}
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			// Separate the words of adjacent blocks, like list items.
+			if n.Type() == ast.TypeBlock {
+				text.WriteByte(' ')
+			}
+			return ast.WalkContinue, nil
+		}
+		switch n.Kind() {
+		case ast.KindCodeBlock, ast.KindFencedCodeBlock:
+			s.CodeBlocks++
+			return ast.WalkSkipChildren, nil
+		case qjskatex.KindTex:
+			s.HasMath = true
+			return ast.WalkSkipChildren, nil
+		case mdext.KindFigure:
+			s.Figures++
+		case mdext.KindHeader, mdext.KindTOC, mdext.KindCitationReferences:
+			return ast.WalkSkipChildren, nil
+		}
+		switch x := n.(type) {
+		case *mdext.Citation:
+			cites[x.Key] = struct{}{}
+		case *ast.String:
+			text.Write(x.Value)
+		case *ast.Text:
+			text.Write(x.Segment.Value(src))
+			if x.SoftLineBreak() || x.HardLineBreak() {
+				text.WriteByte(' ')
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	s.Words = len(strings.Fields(text.String()))
+	s.Citations = len(cites)
+	return s
+}
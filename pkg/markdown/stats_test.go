@@ -0,0 +1,50 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jschaf/jsc/pkg/markdown/mdext"
+	"github.com/jschaf/jsc/pkg/testing/difftest"
+	"github.com/jschaf/jsc/pkg/testing/require"
+)
+
+func TestParse_Stats(t *testing.T) {
+	src := withFrontmatter(mdext.PostMeta{Slug: "foo"}, `
+    # Title words ignored
+
+    One two *three*
+    four.
+
+    - five
+    - six
+
+    ![Alt text](./foo.png)
+
+    Math $x^2$ here.
+
+    `+"```"+`
+    code is not counted
+    `+"```"+`
+  `)
+	ast, err := New().Parse("", strings.NewReader(src))
+	require.NoError(t, err)
+	want := Stats{Words: 8, CodeBlocks: 1, Figures: 1, HasMath: true}
+	difftest.AssertSame(t, want, ast.Stats)
+}
+
+func TestStats_ReadingTime(t *testing.T) {
+	tests := []struct {
+		words int
+		want  time.Duration
+	}{
+		{0, time.Minute},
+		{230, time.Minute},
+		{231, 2 * time.Minute},
+		{2760, 12 * time.Minute},
+	}
+	for _, tt := range tests {
+		difftest.AssertSame(t, tt.want, Stats{Words: tt.words}.ReadingTime())
+	}
+}
@@ -4,40 +4,99 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"os"
+	"sync/atomic"
 	"time"
 
+	"github.com/jschaf/jsc/pkg/config"
 	"github.com/jschaf/jsc/pkg/css"
 	"github.com/jschaf/jsc/pkg/dirs"
 	"github.com/jschaf/jsc/pkg/js"
+	"github.com/jschaf/jsc/pkg/markdown/catalog"
 	"github.com/jschaf/jsc/pkg/markdown/compiler"
+	"github.com/jschaf/jsc/pkg/markdown/diag"
 	"github.com/jschaf/jsc/pkg/static"
 	"golang.org/x/sync/errgroup"
 )
 
-// Rebuild rebuilds everything on the site into distDir.
-func Rebuild(distDir string) error {
+// RebuildOpts configures Rebuild.
+type RebuildOpts struct {
+	// Drafts builds draft posts under /drafts/ for previews. Drafts are never
+	// listed on the index or linked from published posts.
+	Drafts bool
+	// Clean deletes everything in the dist dir before building, so dist holds
+	// only the outputs of this build. Without Clean, outputs of removed pages,
+	// like a deleted tag page or a renamed CSS file, stay in dist. The dev
+	// server rebuilds incrementally and reuses the outputs of unchanged posts.
+	Clean bool
+	// Now returns the build time, which decides whether a post scheduled with
+	// publish_at is live. Defaults to time.Now.
+	Now func() time.Time
+	// Redirects, if not nil, is set to the alias redirects of the posts, as
+	// returned by catalog.Redirects, after each build that validates. The dev
+	// server uses the redirects to mirror the Firebase redirects.
+	Redirects *atomic.Pointer[map[string]string]
+}
+
+// Rebuild rebuilds everything on the site into distDir using the site config.
+// Posts are rebuilt incrementally, so only posts with changed inputs are
+// recompiled.
+func Rebuild(cfg *config.Config, distDir string, opts RebuildOpts) error {
 	slog.Info("start rebuild site")
 	start := time.Now()
 
-	if err := dirs.CleanDir(distDir); err != nil {
-		return fmt.Errorf("failed to clean public dir: %w", err)
+	if opts.Clean {
+		if err := dirs.CleanDir(distDir); err != nil {
+			return fmt.Errorf("clean public dir: %w", err)
+		}
+	} else if err := os.MkdirAll(distDir, 0o755); err != nil {
+		return fmt.Errorf("make public dir: %w", err)
 	}
 
+	// redirects are the alias redirects of the posts, set after the catalog
+	// validates.
+	var redirects map[string]string
 	g, _ := errgroup.WithContext(context.Background())
 	g.Go(func() error {
-		slog.Debug("rebuild compile details")
-		c := compiler.NewDetailCompiler(distDir)
-		if err := c.Compile(""); err != nil {
-			return fmt.Errorf("compile all detail posts: %w", err)
+		slog.Debug("rebuild load catalog")
+		// Collect the diagnostics of every post so one build reports every
+		// problem instead of only the first.
+		diags := &diag.Collector{}
+		now := time.Now
+		if opts.Now != nil {
+			now = opts.Now
 		}
-		return nil
-	})
+		cat, err := catalog.Load("", now())
+		if cat == nil {
+			return fmt.Errorf("load catalog: %w", err)
+		}
+		diags.Add(err)
+		diags.AddAll(cat.Warnings()...)
 
-	g.Go(func() error {
-		slog.Debug("rebuild compile index")
-		ic := compiler.NewIndexCompiler(distDir)
-		if err := ic.Compile(); err != nil {
-			return fmt.Errorf("compile main index: %w", err)
+		// Don't write anything if posts conflict, like with duplicate slugs,
+		// since the outputs would overwrite each other.
+		if err := cat.Validate(); err != nil {
+			diags.Add(err)
+		} else {
+			redirects = cat.Redirects()
+			if opts.Redirects != nil {
+				opts.Redirects.Store(&redirects)
+			}
+			if !opts.Drafts {
+				cat = cat.WithoutDrafts()
+			}
+			// Compile the index before the details instead of concurrently
+			// since both render the same ASTs and renderers like KaTeX keep
+			// per-document state on the AST.
+			slog.Debug("rebuild compile index")
+			ic := compiler.NewIndexCompiler(cfg, distDir)
+			diags.Add(ic.Compile(cat))
+			slog.Debug("rebuild compile details")
+			c := compiler.NewDetailCompiler(cfg, distDir)
+			diags.Add(c.Compile(cat))
+		}
+		if err := diag.Report(os.Stderr, diags.List()); err != nil {
+			return fmt.Errorf("compile posts: %w", err)
 		}
 		return nil
 	})
@@ -86,6 +145,17 @@ func Rebuild(distDir string) error {
 		return fmt.Errorf("rebuild wait err group: %w", err)
 	}
 
+	// Verify after every output is written since pages link to the CSS, JS
+	// and papers.
+	slog.Debug("rebuild verify links")
+	verifyOpts := VerifyOpts{SiteURL: cfg.URL, Redirects: redirects}
+	for _, r := range cfg.Firebase.Rewrites {
+		verifyOpts.Rewrites = append(verifyOpts.Rewrites, r.Glob)
+	}
+	if err := diag.Report(os.Stderr, diag.FromError(Verify(distDir, verifyOpts))); err != nil {
+		return fmt.Errorf("verify links: %w", err)
+	}
+
 	slog.Info("finish rebuild site", "duration", time.Since(start))
 	return nil
 }
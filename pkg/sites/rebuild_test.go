@@ -1,14 +1,21 @@
 package sites
 
 import (
+	"path/filepath"
 	"testing"
 
+	"github.com/jschaf/jsc/pkg/config"
 	"github.com/jschaf/jsc/pkg/dirs"
+	"github.com/jschaf/jsc/pkg/git"
 )
 
 func BenchmarkRebuild(b *testing.B) {
+	cfg, err := config.Load(filepath.Join(git.RootDir(), config.FileName), config.EnvProd)
+	if err != nil {
+		b.Fatal(err)
+	}
 	for i := 0; i < b.N; i++ {
-		if err := Rebuild(dirs.Dist); err != nil {
+		if err := Rebuild(cfg, dirs.Dist, RebuildOpts{}); err != nil {
 			b.Fatal(err)
 		}
 	}
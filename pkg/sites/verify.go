@@ -0,0 +1,262 @@
+package sites
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jschaf/jsc/pkg/markdown/diag"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// VerifyOpts configures Verify.
+type VerifyOpts struct {
+	// SiteURL is the origin of the site, like "https://joe.schafer.dev".
+	// Absolute links to the origin are internal links.
+	SiteURL string
+	// Redirects maps URL paths without a trailing slash to the redirect
+	// location, as returned by catalog.Redirects. Links to a redirect are
+	// valid since the host serves the redirect.
+	Redirects map[string]string
+	// Rewrites are the Firebase globs of URL paths that the host serves from a
+	// rewrite, like a Cloud Run service, instead of from the dist dir. Globs
+	// support "*" and "**", like "/_/heap/**".
+	Rewrites []string
+}
+
+// linkRef is an internal link from an attribute like href or src.
+type linkRef struct {
+	// val is the attribute value.
+	val string
+	// offset is the byte offset of the start tag with the link.
+	offset int
+}
+
+// verifyPage is the element IDs and internal links of an HTML page.
+type verifyPage struct {
+	// ids maps each element ID to the offset of the first element with the
+	// ID.
+	ids  map[string]int
+	refs []linkRef
+	// dupes are the elements with an ID used by an earlier element.
+	dupes []linkRef
+	src   []byte
+}
+
+// verifier resolves internal links against the files in the dist dir.
+type verifier struct {
+	distDir string
+	opts    VerifyOpts
+	// pages are the scanned HTML pages by slash-separated path relative to the
+	// dist dir.
+	pages map[string]*verifyPage
+}
+
+// Verify checks that each internal link in the HTML pages in distDir, from
+// attributes like href and src, resolves to a file in distDir or to a
+// redirect, and that the fragment of each link, like "#footnote-body-1",
+// names an element ID in the target page. Also checks that no page has
+// duplicate element IDs. Returns a diag.List error with every problem.
+func Verify(distDir string, opts VerifyOpts) error {
+	v := &verifier{distDir: distDir, opts: opts, pages: make(map[string]*verifyPage)}
+	var htmlFiles []string
+	err := filepath.WalkDir(distDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && filepath.Ext(p) == ".html" {
+			rel, err := filepath.Rel(distDir, p)
+			if err != nil {
+				return fmt.Errorf("rel path: %w", err)
+			}
+			htmlFiles = append(htmlFiles, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("walk dist dir: %w", err)
+	}
+
+	diags := &diag.Collector{}
+	for _, rel := range htmlFiles {
+		page, err := v.page(rel)
+		if err != nil {
+			return err
+		}
+		var ds diag.List
+		for _, d := range page.dupes {
+			ds = append(ds, diag.Errorf("verify", d.offset, "duplicate element ID %q; first used on line %d", d.val, lineOf(page.src, page.ids[d.val])))
+		}
+		for _, ref := range page.refs {
+			if err := v.resolve(rel, ref.val); err != nil {
+				ds = append(ds, diag.Errorf("verify", ref.offset, "broken link %q: %w", ref.val, err))
+			}
+		}
+		if len(ds) > 0 {
+			diags.AddFile(filepath.Join(distDir, filepath.FromSlash(rel)), page.src, ds)
+		}
+	}
+	return diags.Err()
+}
+
+// page returns the scanned HTML page at the slash-separated path relative to
+// the dist dir, scanning the page if not yet scanned.
+func (v *verifier) page(rel string) (*verifyPage, error) {
+	if p, ok := v.pages[rel]; ok {
+		return p, nil
+	}
+	src, err := os.ReadFile(filepath.Join(v.distDir, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil, fmt.Errorf("read page: %w", err)
+	}
+	p, err := scanPage(src)
+	if err != nil {
+		return nil, fmt.Errorf("scan page %s: %w", rel, err)
+	}
+	v.pages[rel] = p
+	return p, nil
+}
+
+// scanPage collects the element IDs and the link attributes of the page.
+func scanPage(src []byte) (*verifyPage, error) {
+	p := &verifyPage{ids: make(map[string]int), src: src}
+	z := html.NewTokenizer(bytes.NewReader(src))
+	offset := 0
+	for {
+		tt := z.Next()
+		start := offset
+		offset += len(z.Raw())
+		switch tt {
+		case html.ErrorToken:
+			if errors.Is(z.Err(), io.EOF) {
+				return p, nil
+			}
+			return nil, z.Err()
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			for _, a := range tok.Attr {
+				switch {
+				case a.Key == "id" || (a.Key == "name" && tok.DataAtom == atom.A):
+					if _, ok := p.ids[a.Val]; ok {
+						p.dupes = append(p.dupes, linkRef{val: a.Val, offset: start})
+					} else {
+						p.ids[a.Val] = start
+					}
+				case isLinkAttr(tok.DataAtom, a.Key):
+					p.refs = append(p.refs, linkRef{val: a.Val, offset: start})
+				case a.Key == "srcset":
+					for _, c := range strings.Split(a.Val, ",") {
+						if fields := strings.Fields(c); len(fields) > 0 {
+							p.refs = append(p.refs, linkRef{val: fields[0], offset: start})
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// isLinkAttr returns true if the attribute of the element is a URL to load or
+// navigate to.
+func isLinkAttr(a atom.Atom, key string) bool {
+	switch key {
+	case "href":
+		return a == atom.A || a == atom.Link || a == atom.Area
+	case "src":
+		return a != atom.Input
+	case "poster":
+		return a == atom.Video
+	default:
+		return false
+	}
+}
+
+// resolve returns an error if the link from the page at the slash-separated
+// path relative to the dist dir doesn't resolve. External links always
+// resolve.
+func (v *verifier) resolve(from, link string) error {
+	u, err := url.Parse(link)
+	if err != nil {
+		return fmt.Errorf("parse URL: %w", err)
+	}
+	if u.Scheme != "" || u.Host != "" {
+		site, err := url.Parse(v.opts.SiteURL)
+		if err != nil || v.opts.SiteURL == "" || u.Host != site.Host || (u.Scheme != "" && u.Scheme != site.Scheme) {
+			return nil // external link
+		}
+		u.Scheme, u.Host = "", ""
+	}
+	fromURL := &url.URL{Path: "/" + strings.TrimSuffix(from, "index.html")}
+	target := fromURL.ResolveReference(u)
+
+	file, err := v.targetFile(target.Path)
+	if err != nil {
+		return err
+	}
+	if u.Fragment == "" || u.Fragment == "top" || file == "" || path.Ext(file) != ".html" {
+		return nil
+	}
+	page, err := v.page(file)
+	if err != nil {
+		return err
+	}
+	if _, ok := page.ids[u.Fragment]; !ok {
+		return fmt.Errorf("no element with ID %q in %s", u.Fragment, file)
+	}
+	return nil
+}
+
+// targetFile returns the slash-separated path, relative to the dist dir, of
+// the file served for the URL path. Returns the empty string if the host
+// serves the path with a redirect or rewrite.
+func (v *verifier) targetFile(urlPath string) (string, error) {
+	rel := strings.TrimPrefix(path.Clean("/"+urlPath), "/")
+	if rel == "" {
+		rel = "."
+	}
+	info, err := os.Stat(filepath.Join(v.distDir, filepath.FromSlash(rel)))
+	switch {
+	case err == nil && !info.IsDir():
+		return rel, nil
+	case err == nil:
+		index := path.Join(rel, "index.html")
+		if _, err := os.Stat(filepath.Join(v.distDir, filepath.FromSlash(index))); err == nil {
+			return index, nil
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return "", fmt.Errorf("stat target: %w", err)
+	}
+	clean := "/" + strings.TrimPrefix(rel, ".")
+	if _, ok := v.opts.Redirects[clean]; ok {
+		return "", nil
+	}
+	for _, glob := range v.opts.Rewrites {
+		if matchGlob(glob, clean) {
+			return "", nil
+		}
+	}
+	return "", fmt.Errorf("no file for %s", clean)
+}
+
+// matchGlob returns true if the URL path matches the Firebase glob. "**"
+// matches any path and "*" matches any path segment.
+func matchGlob(glob, urlPath string) bool {
+	if prefix, ok := strings.CutSuffix(glob, "/**"); ok {
+		return urlPath == prefix || strings.HasPrefix(urlPath, prefix+"/")
+	}
+	ok, _ := path.Match(glob, urlPath)
+	return ok
+}
+
+// lineOf returns the 1-based line of the offset in src.
+func lineOf(src []byte, offset int) int {
+	return bytes.Count(src[:offset], []byte{'\n'}) + 1
+}
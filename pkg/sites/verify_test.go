@@ -0,0 +1,78 @@
+package sites
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jschaf/jsc/pkg/markdown/diag"
+	"github.com/jschaf/jsc/pkg/texts"
+)
+
+func writeDistFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestVerify(t *testing.T) {
+	dir := writeDistFiles(t, map[string]string{
+		"index.html": texts.Dedent(`
+			<link rel="stylesheet" href="/style/main.css">
+			<a href="/post/">post</a> <a href="post/#h1">heading</a>
+			<a href="https://example.com/missing">external</a>
+			<a href="https://joe.schafer.dev/post#footnote-body-1">absolute</a>
+			<a href="/old-slug/">alias</a> <script src="/_/heap/js/heap.js"></script>
+			<a href="#top">top</a> <a href="mailto:joe@example.com">mail</a>
+		`),
+		"style/main.css": "",
+		"post/index.html": texts.Dedent(`
+			<h1 id="h1">Title</h1>
+			<img src="fig.png" srcset="fig.png 1x, fig@2x.png 2x">
+			<p id="footnote-body-1">note</p> <a href="#footnote-body-1">cite</a>
+			<a href="#missing">missing fragment</a> <a href="/gone/">gone</a>
+			<span id="h1">dupe</span>
+		`),
+		"post/fig.png": "",
+	})
+	err := Verify(dir, VerifyOpts{
+		SiteURL:   "https://joe.schafer.dev",
+		Redirects: map[string]string{"/old-slug": "/post"},
+		Rewrites:  []string{"/_/heap/**"},
+	})
+	var got []string
+	for _, d := range diag.FromError(err) {
+		rel, _ := filepath.Rel(dir, d.Path)
+		d.Path = filepath.ToSlash(rel)
+		got = append(got, d.Error())
+	}
+	want := []string{
+		`post/index.html:2:1: verify: broken link "fig@2x.png": no file for /post/fig@2x.png`,
+		`post/index.html:4:1: verify: broken link "#missing": no element with ID "missing" in post/index.html`,
+		`post/index.html:4:41: verify: broken link "/gone/": no file for /gone`,
+		`post/index.html:5:1: verify: duplicate element ID "h1"; first used on line 1`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Verify mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestVerify_valid(t *testing.T) {
+	dir := writeDistFiles(t, map[string]string{
+		"index.html":     `<a href="/">home</a> <a href="/til/">til</a> <a href="til/#x">x</a>`,
+		"til/index.html": `<h2 id="x">x</h2>`,
+	})
+	if err := Verify(dir, VerifyOpts{}); err != nil {
+		t.Errorf("Verify of valid dist: %v", err)
+	}
+}
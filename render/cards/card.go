@@ -0,0 +1,267 @@
+// Package cards renders social preview images, the image shown when a post is
+// shared on sites like Twitter, LinkedIn and Slack.
+package cards
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jschaf/jsc/pkg/dirs"
+	"github.com/jschaf/jsc/pkg/git"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// The size of a card, the size recommended by OpenGraph consumers.
+const (
+	Width  = 1200
+	Height = 630
+)
+
+const (
+	margin = 80
+	// maxTitleLines is the most lines of the title. Longer titles are
+	// truncated with an ellipsis at the smallest font size.
+	maxTitleLines = 4
+	metaFontSize  = 32
+)
+
+// titleFontSizes are the font sizes to try for the title, largest first. Uses
+// the largest size that fits the title in maxTitleLines.
+var titleFontSizes = []float64{72, 60, 48}
+
+var (
+	bgColor     = color.RGBA{R: 0xf8, G: 0xfa, B: 0xfc, A: 0xff} // --slate-50
+	accentColor = color.RGBA{R: 0x33, G: 0x41, B: 0x55, A: 0xff} // --slate-700
+	titleColor  = color.RGBA{R: 0x1b, G: 0x1b, B: 0x1b, A: 0xff} // --text-color
+	metaColor   = color.RGBA{R: 0x64, G: 0x74, B: 0x8b, A: 0xff} // --slate-500
+)
+
+// Card is the content of a social preview image.
+type Card struct {
+	// Title is the plain text title of the post, including Unicode math.
+	Title    string
+	Date     time.Time
+	SiteName string
+}
+
+// boldFontFiles and regularFontFiles are the bundled fonts in order of
+// preference for each rune. The main font lacks some math symbols, so fall
+// back to the other KaTeX fonts. Uses the TrueType copy of each font since
+// sfnt can't parse WOFF2.
+var (
+	boldFontFiles    = []string{"KaTeX_Main-Bold.ttf", "KaTeX_Math-BoldItalic.ttf", "KaTeX_AMS-Regular.ttf", "KaTeX_Size1-Regular.ttf"}
+	regularFontFiles = []string{"KaTeX_Main-Regular.ttf", "KaTeX_Math-Italic.ttf", "KaTeX_AMS-Regular.ttf", "KaTeX_Size1-Regular.ttf"}
+)
+
+type fonts struct {
+	bold    []*sfnt.Font
+	regular []*sfnt.Font
+}
+
+var loadFonts = sync.OnceValues(func() (*fonts, error) {
+	bold, err := parseFonts(boldFontFiles)
+	if err != nil {
+		return nil, err
+	}
+	regular, err := parseFonts(regularFontFiles)
+	if err != nil {
+		return nil, err
+	}
+	return &fonts{bold: bold, regular: regular}, nil
+})
+
+// FontPaths returns the full paths of the font files used to render cards.
+func FontPaths() []string {
+	fontDir := filepath.Join(git.RootDir(), dirs.Style, dirs.Fonts)
+	paths := make([]string, 0, len(boldFontFiles)+len(regularFontFiles))
+	for _, names := range [][]string{boldFontFiles, regularFontFiles} {
+		for _, name := range names {
+			paths = append(paths, filepath.Join(fontDir, name))
+		}
+	}
+	return paths
+}
+
+func parseFonts(names []string) ([]*sfnt.Font, error) {
+	fontDir := filepath.Join(git.RootDir(), dirs.Style, dirs.Fonts)
+	fs := make([]*sfnt.Font, len(names))
+	for i, name := range names {
+		bs, err := os.ReadFile(filepath.Join(fontDir, name))
+		if err != nil {
+			return nil, fmt.Errorf("read font: %w", err)
+		}
+		f, err := opentype.Parse(bs)
+		if err != nil {
+			return nil, fmt.Errorf("parse font %s: %w", name, err)
+		}
+		fs[i] = f
+	}
+	return fs, nil
+}
+
+// Render writes the card as a PNG image to w.
+func Render(w io.Writer, c Card) error {
+	fs, err := loadFonts()
+	if err != nil {
+		return fmt.Errorf("load card fonts: %w", err)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(bgColor), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, Width, 16), image.NewUniform(accentColor), image.Point{}, draw.Src)
+
+	// Title at the top, wrapped to fit the width.
+	title, err := fitTitle(fs.bold, c.Title)
+	if err != nil {
+		return err
+	}
+	defer title.face.close()
+	y := fixed.I(margin) + title.face.lineHeight
+	for _, line := range title.lines {
+		title.face.draw(img, titleColor, fixed.P(margin, y.Round()), line)
+		y += title.face.lineHeight
+	}
+
+	// Date and site name at the bottom.
+	meta, err := newFallbackFace(fs.regular, metaFontSize)
+	if err != nil {
+		return err
+	}
+	defer meta.close()
+	baseline := Height - margin
+	if !c.Date.IsZero() {
+		meta.draw(img, metaColor, fixed.P(margin, baseline), c.Date.Format("January 2, 2006"))
+	}
+	siteWidth := meta.measure(c.SiteName)
+	meta.draw(img, metaColor, fixed.Point26_6{X: fixed.I(Width-margin) - siteWidth, Y: fixed.I(baseline)}, c.SiteName)
+
+	if err := png.Encode(w, img); err != nil {
+		return fmt.Errorf("encode card png: %w", err)
+	}
+	return nil
+}
+
+type fittedTitle struct {
+	face  *fallbackFace
+	lines []string
+}
+
+// fitTitle wraps the title using the largest font size that fits the title
+// in maxTitleLines. Truncates the title at the smallest size.
+func fitTitle(fs []*sfnt.Font, title string) (fittedTitle, error) {
+	maxWidth := fixed.I(Width - 2*margin)
+	var face *fallbackFace
+	var lines []string
+	for i, size := range titleFontSizes {
+		if face != nil {
+			face.close()
+		}
+		var err error
+		face, err = newFallbackFace(fs, size)
+		if err != nil {
+			return fittedTitle{}, err
+		}
+		lines = face.wrap(title, maxWidth)
+		if len(lines) <= maxTitleLines || i == len(titleFontSizes)-1 {
+			break
+		}
+	}
+	if len(lines) > maxTitleLines {
+		lines = lines[:maxTitleLines]
+		last := lines[maxTitleLines-1] + "…"
+		for face.measure(last) > maxWidth && strings.Contains(last, " ") {
+			last = last[:strings.LastIndexByte(strings.TrimSuffix(last, "…"), ' ')] + "…"
+		}
+		lines[maxTitleLines-1] = last
+	}
+	return fittedTitle{face: face, lines: lines}, nil
+}
+
+// fallbackFace draws each rune with the first font that has a glyph for the
+// rune.
+type fallbackFace struct {
+	fonts      []*sfnt.Font
+	faces      []font.Face
+	lineHeight fixed.Int26_6
+	buf        sfnt.Buffer
+}
+
+func newFallbackFace(fs []*sfnt.Font, size float64) (*fallbackFace, error) {
+	ff := &fallbackFace{fonts: fs, faces: make([]font.Face, len(fs))}
+	for i, f := range fs {
+		face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return nil, fmt.Errorf("new font face: %w", err)
+		}
+		ff.faces[i] = face
+	}
+	ff.lineHeight = fixed.I(int(size * 1.25))
+	return ff, nil
+}
+
+func (ff *fallbackFace) close() {
+	for _, f := range ff.faces {
+		_ = f.Close()
+	}
+}
+
+// faceFor returns the face of the first font with a glyph for r, or the main
+// face if no font has a glyph.
+func (ff *fallbackFace) faceFor(r rune) font.Face {
+	for i, f := range ff.fonts {
+		if idx, err := f.GlyphIndex(&ff.buf, r); err == nil && idx != 0 {
+			return ff.faces[i]
+		}
+	}
+	return ff.faces[0]
+}
+
+func (ff *fallbackFace) measure(s string) fixed.Int26_6 {
+	w := fixed.I(0)
+	for _, r := range s {
+		adv, _ := ff.faceFor(r).GlyphAdvance(r)
+		w += adv
+	}
+	return w
+}
+
+func (ff *fallbackFace) draw(dst draw.Image, c color.Color, dot fixed.Point26_6, s string) {
+	d := &font.Drawer{Dst: dst, Src: image.NewUniform(c), Dot: dot}
+	for _, r := range s {
+		d.Face = ff.faceFor(r)
+		d.DrawString(string(r))
+	}
+}
+
+// wrap splits s into lines no wider than maxWidth, breaking on spaces. A word
+// wider than maxWidth gets its own line.
+func (ff *fallbackFace) wrap(s string, maxWidth fixed.Int26_6) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		next := word
+		if line != "" {
+			next = line + " " + word
+		}
+		if line != "" && ff.measure(next) > maxWidth {
+			lines = append(lines, line)
+			next = word
+		}
+		line = next
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,45 @@
+package cards
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jschaf/jsc/pkg/testing/difftest"
+	"github.com/jschaf/jsc/pkg/testing/require"
+)
+
+func TestRender(t *testing.T) {
+	b := &bytes.Buffer{}
+	err := Render(b, Card{
+		Title:    "Bitmaps and the path to x² + y²",
+		Date:     time.Date(2020, time.January, 2, 0, 0, 0, 0, time.UTC),
+		SiteName: "Blog",
+	})
+	require.NoError(t, err)
+
+	img, err := png.Decode(b)
+	require.NoError(t, err)
+	difftest.AssertSame(t, Width, img.Bounds().Dx())
+	difftest.AssertSame(t, Height, img.Bounds().Dy())
+}
+
+func TestFitTitle(t *testing.T) {
+	fs, err := loadFonts()
+	require.NoError(t, err)
+
+	short, err := fitTitle(fs.bold, "A short title")
+	require.NoError(t, err)
+	defer short.face.close()
+	difftest.AssertSame(t, []string{"A short title"}, short.lines)
+
+	long, err := fitTitle(fs.bold, strings.Repeat("word ", 200))
+	require.NoError(t, err)
+	defer long.face.close()
+	difftest.AssertSame(t, maxTitleLines, len(long.lines))
+	if last := long.lines[maxTitleLines-1]; !strings.HasSuffix(last, "…") {
+		t.Errorf("want truncated last line to end with an ellipsis; got %q", last)
+	}
+}
@@ -0,0 +1,93 @@
+package feeds
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Links   []atomLink  `xml:"link"`
+	Updated string      `xml:"updated"`
+	Author  *atomPerson `xml:"author,omitempty"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Categories []atomCategory `xml:"category"`
+	Content    atomContent    `xml:"content"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",cdata"`
+}
+
+// BuildAtom renders the feed as an Atom document served at selfURL.
+func (f *Feed) BuildAtom(selfURL string) (string, error) {
+	if err := f.validate(selfURL); err != nil {
+		return "", err
+	}
+	doc := atomFeed{
+		Title: f.Title,
+		ID:    selfURL,
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: selfURL},
+			{Rel: "alternate", Type: "text/html", Href: f.HomeURL},
+		},
+		Updated: f.Updated().Format(time.RFC3339),
+	}
+	if f.Author != "" {
+		doc.Author = &atomPerson{Name: f.Author}
+	}
+	for _, e := range f.entries {
+		entry := atomEntry{
+			Title:     e.Title,
+			ID:        e.URL,
+			Link:      atomLink{Rel: "alternate", Type: "text/html", Href: e.URL},
+			Published: e.Published.Format(time.RFC3339),
+			Updated:   e.updated().Format(time.RFC3339),
+			Content:   atomContent{Type: "html", Body: e.ContentHTML},
+		}
+		for _, tag := range e.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+	return marshalXML(doc)
+}
+
+func marshalXML(doc any) (string, error) {
+	sb := &strings.Builder{}
+	sb.WriteString(xml.Header)
+	enc := xml.NewEncoder(sb)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return "", fmt.Errorf("encode feed xml: %w", err)
+	}
+	sb.WriteByte('\n')
+	return sb.String(), nil
+}
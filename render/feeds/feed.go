@@ -0,0 +1,170 @@
+// Package feeds renders syndication feeds of posts as Atom, RSS 2.0 and JSON
+// Feed documents.
+package feeds
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// Feed is a list of entries, like every published post, that's rendered in
+// each feed format.
+type Feed struct {
+	Title       string
+	Description string
+	// HomeURL is the absolute URL of the HTML page with the entries of the
+	// feed, like the home page.
+	HomeURL string
+	// Author is the name of the author of every entry.
+	Author  string
+	entries []Entry
+}
+
+// Entry is a single post in a feed.
+type Entry struct {
+	Title string
+	// URL is the absolute URL of the post. Also used as the ID of the entry.
+	URL       string
+	Published time.Time
+	Updated   time.Time
+	// ContentHTML is the full HTML of the post. URLs must be absolute since
+	// feed readers show the content outside the site; see AbsoluteURLs.
+	ContentHTML string
+	Tags        []string
+}
+
+// Add adds an entry to the feed. Entries are rendered in the order they were
+// added.
+func (f *Feed) Add(e Entry) {
+	f.entries = append(f.entries, e)
+}
+
+// Updated returns the most recent updated time of any entry.
+func (f *Feed) Updated() time.Time {
+	var updated time.Time
+	for _, e := range f.entries {
+		if t := e.updated(); t.After(updated) {
+			updated = t
+		}
+	}
+	return updated
+}
+
+func (f *Feed) validate(selfURL string) error {
+	if f.Title == "" {
+		return fmt.Errorf("missing feed title")
+	}
+	if f.HomeURL == "" {
+		return fmt.Errorf("missing feed home url")
+	}
+	if selfURL == "" {
+		return fmt.Errorf("missing feed self url")
+	}
+	for _, e := range f.entries {
+		if e.URL == "" {
+			return fmt.Errorf("missing url for entry %q", e.Title)
+		}
+		if e.Published.IsZero() {
+			return fmt.Errorf("missing published time for entry %s", e.URL)
+		}
+	}
+	return nil
+}
+
+// updated returns the updated time of the entry, defaulting to the
+// published time.
+func (e Entry) updated() time.Time {
+	if e.Updated.After(e.Published) {
+		return e.Updated
+	}
+	return e.Published
+}
+
+// AbsoluteURLs resolves every relative URL in href, src and srcset attributes
+// of the HTML against base, the absolute URL of the page with the HTML. A feed
+// reader would otherwise resolve relative URLs against the feed URL, if at all.
+// Text, like the contents of a code block, is never rewritten.
+func AbsoluteURLs(s string, base string) (string, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("parse base url: %w", err)
+	}
+	z := html.NewTokenizer(strings.NewReader(s))
+	sb := &strings.Builder{}
+	sb.Grow(len(s))
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if err := z.Err(); !errors.Is(err, io.EOF) {
+				return "", fmt.Errorf("tokenize html: %w", err)
+			}
+			return sb.String(), nil
+		case html.StartTagToken, html.SelfClosingTagToken:
+			// Copy the raw bytes before z.Token since the tokenizer reuses
+			// the buffer.
+			raw := string(z.Raw())
+			tok := z.Token()
+			if resolveAttrs(tok.Attr, baseURL) {
+				sb.WriteString(tok.String())
+			} else {
+				sb.WriteString(raw)
+			}
+		default:
+			sb.Write(z.Raw())
+		}
+	}
+}
+
+// resolveAttrs resolves the relative URLs in the URL attributes against base.
+// Returns true if any attribute changed.
+func resolveAttrs(attrs []html.Attribute, base *url.URL) bool {
+	changed := false
+	for i, a := range attrs {
+		var val string
+		switch a.Key {
+		case "href", "src":
+			val = resolveURL(a.Val, base)
+		case "srcset":
+			val = resolveSrcset(a.Val, base)
+		default:
+			continue
+		}
+		if val != a.Val {
+			attrs[i].Val = val
+			changed = true
+		}
+	}
+	return changed
+}
+
+// resolveURL resolves the URL against base. Returns the URL unchanged if it's
+// absolute or invalid.
+func resolveURL(s string, base *url.URL) string {
+	ref, err := url.Parse(strings.TrimSpace(s))
+	if err != nil || ref.IsAbs() {
+		return s
+	}
+	return base.ResolveReference(ref).String()
+}
+
+// resolveSrcset resolves the URL of each image candidate in a srcset, like
+// "a.png 1x, b.png 2x".
+func resolveSrcset(s string, base *url.URL) string {
+	candidates := strings.Split(s, ",")
+	for i, c := range candidates {
+		fields := strings.Fields(c)
+		if len(fields) == 0 {
+			continue
+		}
+		fields[0] = resolveURL(fields[0], base)
+		candidates[i] = strings.Join(fields, " ")
+	}
+	return strings.Join(candidates, ", ")
+}
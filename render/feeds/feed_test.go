@@ -0,0 +1,150 @@
+package feeds
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jschaf/jsc/pkg/testing/difftest"
+	"github.com/jschaf/jsc/pkg/testing/require"
+	"github.com/jschaf/jsc/pkg/texts"
+)
+
+func newTestFeed() *Feed {
+	f := &Feed{
+		Title:       "Blog",
+		Description: "Posts",
+		HomeURL:     "https://example.com/",
+		Author:      "Alice",
+	}
+	f.Add(Entry{
+		Title:       "First <post>",
+		URL:         "https://example.com/first",
+		Published:   time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC),
+		Updated:     time.Date(2023, 10, 2, 0, 0, 0, 0, time.UTC),
+		ContentHTML: "<p>Hello</p>",
+		Tags:        []string{"go"},
+	})
+	return f
+}
+
+func TestFeed_BuildAtom(t *testing.T) {
+	got, err := newTestFeed().BuildAtom("https://example.com/atom.xml")
+	require.NoError(t, err)
+	want := texts.Dedent(`
+		<?xml version="1.0" encoding="UTF-8"?>
+		<feed xmlns="http://www.w3.org/2005/Atom">
+		  <title>Blog</title>
+		  <id>https://example.com/atom.xml</id>
+		  <link rel="self" type="application/atom+xml" href="https://example.com/atom.xml"></link>
+		  <link rel="alternate" type="text/html" href="https://example.com/"></link>
+		  <updated>2023-10-02T00:00:00Z</updated>
+		  <author>
+		    <name>Alice</name>
+		  </author>
+		  <entry>
+		    <title>First &lt;post&gt;</title>
+		    <id>https://example.com/first</id>
+		    <link rel="alternate" type="text/html" href="https://example.com/first"></link>
+		    <published>2023-10-01T00:00:00Z</published>
+		    <updated>2023-10-02T00:00:00Z</updated>
+		    <category term="go"></category>
+		    <content type="html"><![CDATA[<p>Hello</p>]]></content>
+		  </entry>
+		</feed>
+	`) + "\n"
+	difftest.AssertSame(t, want, got)
+}
+
+func TestFeed_BuildRSS(t *testing.T) {
+	got, err := newTestFeed().BuildRSS("https://example.com/rss.xml")
+	require.NoError(t, err)
+	want := texts.Dedent(`
+		<?xml version="1.0" encoding="UTF-8"?>
+		<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">
+		  <channel>
+		    <title>Blog</title>
+		    <link>https://example.com/</link>
+		    <description>Posts</description>
+		    <atom:link rel="self" type="application/rss+xml" href="https://example.com/rss.xml"></atom:link>
+		    <lastBuildDate>Mon, 02 Oct 2023 00:00:00 +0000</lastBuildDate>
+		    <item>
+		      <title>First &lt;post&gt;</title>
+		      <link>https://example.com/first</link>
+		      <guid isPermaLink="true">https://example.com/first</guid>
+		      <pubDate>Sun, 01 Oct 2023 00:00:00 +0000</pubDate>
+		      <category>go</category>
+		      <description><![CDATA[<p>Hello</p>]]></description>
+		    </item>
+		  </channel>
+		</rss>
+	`) + "\n"
+	difftest.AssertSame(t, want, got)
+}
+
+func TestFeed_BuildJSON(t *testing.T) {
+	got, err := newTestFeed().BuildJSON("https://example.com/feed.json")
+	require.NoError(t, err)
+	want := texts.Dedent(`
+		{
+		  "version": "https://jsonfeed.org/version/1.1",
+		  "title": "Blog",
+		  "home_page_url": "https://example.com/",
+		  "feed_url": "https://example.com/feed.json",
+		  "description": "Posts",
+		  "authors": [
+		    {
+		      "name": "Alice"
+		    }
+		  ],
+		  "items": [
+		    {
+		      "id": "https://example.com/first",
+		      "url": "https://example.com/first",
+		      "title": "First <post>",
+		      "content_html": "<p>Hello</p>",
+		      "date_published": "2023-10-01T00:00:00Z",
+		      "date_modified": "2023-10-02T00:00:00Z",
+		      "tags": [
+		        "go"
+		      ]
+		    }
+		  ]
+		}
+	`) + "\n"
+	difftest.AssertSame(t, want, got)
+}
+
+func TestFeed_MissingURL(t *testing.T) {
+	f := &Feed{Title: "Blog", HomeURL: "https://example.com/"}
+	f.Add(Entry{Title: "First", Published: time.Now()})
+	if _, err := f.BuildAtom("https://example.com/atom.xml"); err == nil {
+		t.Error("BuildAtom with entry without URL; want error")
+	}
+}
+
+func TestAbsoluteURLs(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"root relative", `<a href="/other">x</a>`, `<a href="https://example.com/other">x</a>`},
+		{"dir relative", `<img src="img.png">`, `<img src="https://example.com/post/img.png">`},
+		{"fragment", `<a href="#fn1">1</a>`, `<a href="https://example.com/post/#fn1">1</a>`},
+		{"absolute", `<a href="https://go.dev/">go</a>`, `<a href="https://go.dev/">go</a>`},
+		{"single quotes", `<a href='/other'>x</a>`, `<a href="https://example.com/other">x</a>`},
+		{"unquoted", `<a href=/other>x</a>`, `<a href="https://example.com/other">x</a>`},
+		{"uppercase", `<IMG SRC="img.png">`, `<img src="https://example.com/post/img.png">`},
+		{"srcset", `<img srcset="a.png 1x, /b.png 2x">`, `<img srcset="https://example.com/post/a.png 1x, https://example.com/b.png 2x">`},
+		{"unchanged tag", `<p class='x'>hi</p>`, `<p class='x'>hi</p>`},
+		{"not attribute", `<p>href="/other"</p>`, `<p>href="/other"</p>`},
+		{"code", `<pre><code>&lt;a href="/other"&gt;</code></pre>`, `<pre><code>&lt;a href="/other"&gt;</code></pre>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AbsoluteURLs(tt.html, "https://example.com/post/")
+			require.NoError(t, err)
+			difftest.AssertSame(t, tt.want, got)
+		})
+	}
+}
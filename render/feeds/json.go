@@ -0,0 +1,74 @@
+package feeds
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// jsonFeedVersion is the JSON Feed spec version: https://jsonfeed.org/version/1.1.
+const jsonFeedVersion = "https://jsonfeed.org/version/1.1"
+
+type jsonFeed struct {
+	Version     string       `json:"version"`
+	Title       string       `json:"title"`
+	HomePageURL string       `json:"home_page_url"`
+	FeedURL     string       `json:"feed_url"`
+	Description string       `json:"description,omitempty"`
+	Authors     []jsonAuthor `json:"authors,omitempty"`
+	Items       []jsonItem   `json:"items"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonItem struct {
+	ID            string   `json:"id"`
+	URL           string   `json:"url"`
+	Title         string   `json:"title"`
+	ContentHTML   string   `json:"content_html"`
+	DatePublished string   `json:"date_published"`
+	DateModified  string   `json:"date_modified"`
+	Tags          []string `json:"tags,omitempty"`
+}
+
+// BuildJSON renders the feed as a JSON Feed document served at selfURL.
+func (f *Feed) BuildJSON(selfURL string) (string, error) {
+	if err := f.validate(selfURL); err != nil {
+		return "", err
+	}
+	doc := jsonFeed{
+		Version:     jsonFeedVersion,
+		Title:       f.Title,
+		HomePageURL: f.HomeURL,
+		FeedURL:     selfURL,
+		Description: f.Description,
+		Items:       make([]jsonItem, 0, len(f.entries)),
+	}
+	if f.Author != "" {
+		doc.Authors = []jsonAuthor{{Name: f.Author}}
+	}
+	for _, e := range f.entries {
+		doc.Items = append(doc.Items, jsonItem{
+			ID:            e.URL,
+			URL:           e.URL,
+			Title:         e.Title,
+			ContentHTML:   e.ContentHTML,
+			DatePublished: e.Published.Format(time.RFC3339),
+			DateModified:  e.updated().Format(time.RFC3339),
+			Tags:          e.Tags,
+		})
+	}
+	sb := &strings.Builder{}
+	enc := json.NewEncoder(sb)
+	// Keep the HTML content readable; JSON Feed readers don't embed the
+	// document in HTML.
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return "", fmt.Errorf("encode json feed: %w", err)
+	}
+	return sb.String(), nil
+}
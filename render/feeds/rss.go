@@ -0,0 +1,80 @@
+package feeds
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+type rssDoc struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	SelfLink      rssLink   `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+// rssLink is an atom:link element, which RSS readers use to find the
+// canonical feed URL.
+type rssLink struct {
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+	Href string `xml:"href,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Categories  []string `xml:"category"`
+	Description rssCDATA `xml:"description"`
+}
+
+// rssCDATA is text in a CDATA section so that HTML content is readable
+// instead of escaped.
+type rssCDATA struct {
+	Value string `xml:",cdata"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// BuildRSS renders the feed as an RSS 2.0 document served at selfURL. RSS
+// has no updated time for items, so items only have the published time.
+func (f *Feed) BuildRSS(selfURL string) (string, error) {
+	if err := f.validate(selfURL); err != nil {
+		return "", err
+	}
+	doc := rssDoc{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.HomeURL,
+			Description:   f.Description,
+			SelfLink:      rssLink{Rel: "self", Type: "application/rss+xml", Href: selfURL},
+			LastBuildDate: f.Updated().Format(time.RFC1123Z),
+		},
+	}
+	for _, e := range f.entries {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       e.Title,
+			Link:        e.URL,
+			GUID:        rssGUID{IsPermaLink: true, Value: e.URL},
+			PubDate:     e.Published.Format(time.RFC1123Z),
+			Categories:  e.Tags,
+			Description: rssCDATA{Value: e.ContentHTML},
+		})
+	}
+	return marshalXML(doc)
+}
@@ -0,0 +1,138 @@
+// Package search builds a compact inverted index of posts for client-side
+// full-text search. The index is serialized as JSON and queried by
+// static/main.ts.
+package search
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Weights of a term by where it appears. Title and heading matches rank above
+// matches in the body text.
+const (
+	titleWeight   = 10
+	headingWeight = 5
+	textWeight    = 1
+)
+
+// stopWords are common words excluded from the index. Must match the stop
+// words in static/main.ts.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "in": true, "is": true,
+	"it": true, "of": true, "on": true, "or": true, "that": true, "the": true,
+	"this": true, "to": true, "was": true, "with": true,
+}
+
+// Tokenize splits s into lowercase words on any rune that's not a letter or
+// number, drops stop words and stems each word. The tokenizer in
+// static/main.ts must match, so numbers include the \p{N} runes, like "²".
+func Tokenize(s string) []string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	terms := words[:0]
+	for _, w := range words {
+		if stopWords[w] {
+			continue
+		}
+		terms = append(terms, Stem(w))
+	}
+	return terms
+}
+
+// Doc is a searchable page, like a post, split into sections at each heading.
+type Doc struct {
+	// URL is the URL path of the page, like "/foo".
+	URL   string `json:"url"`
+	Title string `json:"title"`
+	// Date is the publish date of the page as YYYY-MM-DD.
+	Date     string    `json:"date,omitempty"`
+	Sections []Section `json:"sections"`
+}
+
+// Section is the text under a heading. The first section of a doc is the text
+// before the first heading and has no ID or heading.
+type Section struct {
+	// ID is the HTML ID of the heading, used to deep link to the section.
+	ID      string `json:"id,omitempty"`
+	Heading string `json:"heading,omitempty"`
+	// Text is the plain text of the section, without code or math. Used to
+	// show snippets of matches.
+	Text string `json:"text"`
+}
+
+type posting struct{ doc, section int }
+
+// Index is an inverted index from each term to the doc sections containing the
+// term.
+type Index struct {
+	docs  []Doc
+	terms map[string]map[posting]int
+}
+
+func NewIndex() *Index {
+	return &Index{terms: make(map[string]map[posting]int)}
+}
+
+// Add indexes a doc. The title is indexed as part of the first section.
+func (idx *Index) Add(d Doc) {
+	if len(d.Sections) == 0 {
+		d.Sections = []Section{{}}
+	}
+	docIdx := len(idx.docs)
+	idx.docs = append(idx.docs, d)
+	idx.addTerms(d.Title, posting{docIdx, 0}, titleWeight)
+	for i, s := range d.Sections {
+		idx.addTerms(s.Heading, posting{docIdx, i}, headingWeight)
+		idx.addTerms(s.Text, posting{docIdx, i}, textWeight)
+	}
+}
+
+func (idx *Index) addTerms(s string, p posting, weight int) {
+	for _, t := range Tokenize(s) {
+		ps, ok := idx.terms[t]
+		if !ok {
+			ps = make(map[posting]int)
+			idx.terms[t] = ps
+		}
+		ps[p] += weight
+	}
+}
+
+// jsonIndex is the serialized index. Postings are flattened into triples of
+// doc index, section index and weight to keep the index small.
+type jsonIndex struct {
+	Docs  []Doc            `json:"docs"`
+	Terms map[string][]int `json:"terms"`
+}
+
+// MarshalJSON serializes the index with postings sorted by doc and section so
+// the output is deterministic.
+func (idx *Index) MarshalJSON() ([]byte, error) {
+	ji := jsonIndex{Docs: idx.docs, Terms: make(map[string][]int, len(idx.terms))}
+	if ji.Docs == nil {
+		ji.Docs = []Doc{}
+	}
+	for term, ps := range idx.terms {
+		keys := make([]posting, 0, len(ps))
+		for p := range ps {
+			keys = append(keys, p)
+		}
+		slices.SortFunc(keys, func(a, b posting) int {
+			if a.doc != b.doc {
+				return a.doc - b.doc
+			}
+			return a.section - b.section
+		})
+		flat := make([]int, 0, 3*len(keys))
+		for _, p := range keys {
+			flat = append(flat, p.doc, p.section, ps[p])
+		}
+		ji.Terms[term] = flat
+	}
+	return json.Marshal(ji)
+}
@@ -0,0 +1,40 @@
+package search
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jschaf/jsc/pkg/testing/difftest"
+	"github.com/jschaf/jsc/pkg/testing/require"
+)
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("The Connected-Graphs of 2020, naïvely! Solve x² in ½ of Ⅻ hours.")
+	difftest.AssertSame(t, []string{"connect", "graph", "2020", "naïvely", "solv", "x²", "½", "ⅻ", "hour"}, got)
+}
+
+func TestIndex_MarshalJSON(t *testing.T) {
+	idx := NewIndex()
+	idx.Add(Doc{
+		URL:   "/graphs",
+		Title: "Graphs",
+		Sections: []Section{
+			{Text: "About graphs."},
+			{ID: "trees", Heading: "Trees", Text: "A tree is a graph."},
+		},
+	})
+	idx.Add(Doc{URL: "/trees", Title: "Trees"})
+
+	bs, err := json.Marshal(idx)
+	require.NoError(t, err)
+	got := jsonIndex{}
+	require.NoError(t, json.Unmarshal(bs, &got))
+
+	difftest.AssertSame(t, 2, len(got.Docs))
+	difftest.AssertSame(t, []Section{{}}, got.Docs[1].Sections)
+	difftest.AssertSame(t, map[string][]int{
+		"about": {0, 0, textWeight},
+		"graph": {0, 0, titleWeight + textWeight, 0, 1, textWeight},
+		"tree":  {0, 1, headingWeight + textWeight, 1, 0, titleWeight},
+	}, got.Terms)
+}
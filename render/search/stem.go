@@ -0,0 +1,231 @@
+package search
+
+// Stem returns the stem of a lowercase word using the Porter stemming
+// algorithm so that words like "connect", "connected" and "connection" share
+// the same index term. Returns the word unchanged if it's not all lowercase
+// ASCII letters.
+//
+// The stemmer in static/main.ts must match this implementation so that query
+// terms stem to the same index terms.
+//
+// https://tartarus.org/martin/PorterStemmer/def.txt
+func Stem(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || 'z' < word[i] {
+			return word
+		}
+	}
+	s := &stemmer{b: []byte(word)}
+	s.step1ab()
+	s.step1c()
+	s.step2()
+	s.step3()
+	s.step4()
+	s.step5()
+	return string(s.b)
+}
+
+// stemmer is the state of the Porter algorithm. b is the word being stemmed
+// and j is the last index of the stem before a suffix matched by ends.
+type stemmer struct {
+	b []byte
+	j int
+}
+
+func (s *stemmer) last() int { return len(s.b) - 1 }
+
+// cons returns true if b[i] is a consonant. A 'y' is a consonant at the start
+// of a word or after a vowel.
+func (s *stemmer) cons(i int) bool {
+	switch s.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !s.cons(i-1)
+	default:
+		return true
+	}
+}
+
+// measure returns the number of vowel-consonant sequences in b[0:j+1]. With
+// C a run of consonants and V a run of vowels, every word has the form
+// [C](VC){m}[V] and measure returns m.
+func (s *stemmer) measure() int {
+	n, i := 0, 0
+	for ; i <= s.j && s.cons(i); i++ {
+	}
+	for i <= s.j {
+		for ; i <= s.j && !s.cons(i); i++ {
+		}
+		if i > s.j {
+			break
+		}
+		n++
+		for ; i <= s.j && s.cons(i); i++ {
+		}
+	}
+	return n
+}
+
+// vowelInStem returns true if b[0:j+1] contains a vowel.
+func (s *stemmer) vowelInStem() bool {
+	for i := 0; i <= s.j; i++ {
+		if !s.cons(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// doubleCons returns true if b[i-1:i+1] is a double consonant.
+func (s *stemmer) doubleCons(i int) bool {
+	return i >= 1 && s.b[i] == s.b[i-1] && s.cons(i)
+}
+
+// cvc returns true if b[i-2:i+1] is consonant-vowel-consonant and the second
+// consonant isn't w, x or y. Restores an 'e' for short words like "hop(e)".
+func (s *stemmer) cvc(i int) bool {
+	if i < 2 || !s.cons(i) || s.cons(i-1) || !s.cons(i-2) {
+		return false
+	}
+	c := s.b[i]
+	return c != 'w' && c != 'x' && c != 'y'
+}
+
+// ends returns true if b ends with suffix and sets j to the end of the stem
+// before the suffix.
+func (s *stemmer) ends(suffix string) bool {
+	if len(suffix) > len(s.b) || string(s.b[len(s.b)-len(suffix):]) != suffix {
+		return false
+	}
+	s.j = len(s.b) - len(suffix) - 1
+	return true
+}
+
+// setTo replaces the suffix after j with repl.
+func (s *stemmer) setTo(repl string) {
+	s.b = append(s.b[:s.j+1], repl...)
+}
+
+func (s *stemmer) trimLast() { s.b = s.b[:s.last()] }
+
+// replaceSuffixes replaces the first matching suffix if the measure of the
+// stem is positive.
+func (s *stemmer) replaceSuffixes(pairs [][2]string) {
+	for _, p := range pairs {
+		if s.ends(p[0]) {
+			if s.measure() > 0 {
+				s.setTo(p[1])
+			}
+			return
+		}
+	}
+}
+
+// step1ab removes plurals and -ed or -ing.
+func (s *stemmer) step1ab() {
+	if s.b[s.last()] == 's' {
+		switch {
+		case s.ends("sses"):
+			s.setTo("ss")
+		case s.ends("ies"):
+			s.setTo("i")
+		case s.b[s.last()-1] != 's':
+			s.trimLast()
+		}
+	}
+	if s.ends("eed") {
+		if s.measure() > 0 {
+			s.trimLast()
+		}
+		return
+	}
+	if !(s.ends("ed") || s.ends("ing")) || !s.vowelInStem() {
+		return
+	}
+	s.b = s.b[:s.j+1]
+	switch {
+	case s.ends("at"):
+		s.setTo("ate")
+	case s.ends("bl"):
+		s.setTo("ble")
+	case s.ends("iz"):
+		s.setTo("ize")
+	case s.doubleCons(s.last()):
+		if c := s.b[s.last()]; c != 'l' && c != 's' && c != 'z' {
+			s.trimLast()
+		}
+	default:
+		s.j = s.last()
+		if s.measure() == 1 && s.cvc(s.last()) {
+			s.b = append(s.b, 'e')
+		}
+	}
+}
+
+// step1c turns a terminal y to i when there's another vowel in the stem.
+func (s *stemmer) step1c() {
+	if s.ends("y") && s.vowelInStem() {
+		s.b[s.last()] = 'i'
+	}
+}
+
+// step2 maps double suffixes to single ones, like -ization to -ize.
+func (s *stemmer) step2() {
+	s.replaceSuffixes([][2]string{
+		{"ational", "ate"}, {"tional", "tion"},
+		{"enci", "ence"}, {"anci", "ance"},
+		{"izer", "ize"},
+		{"bli", "ble"}, {"alli", "al"}, {"entli", "ent"}, {"eli", "e"}, {"ousli", "ous"},
+		{"ization", "ize"}, {"ation", "ate"}, {"ator", "ate"},
+		{"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"}, {"ousness", "ous"},
+		{"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"},
+		{"logi", "log"},
+	})
+}
+
+// step3 handles -ic-, -full, -ness and similar suffixes.
+func (s *stemmer) step3() {
+	s.replaceSuffixes([][2]string{
+		{"icate", "ic"}, {"ative", ""}, {"alize", "al"}, {"iciti", "ic"},
+		{"ical", "ic"}, {"ful", ""}, {"ness", ""},
+	})
+}
+
+// step4 removes -ant, -ence and similar suffixes from stems with a measure
+// greater than one.
+func (s *stemmer) step4() {
+	suffixes := []string{
+		"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment",
+		"ent", "ion", "ou", "ism", "ate", "iti", "ous", "ive", "ize",
+	}
+	for _, suffix := range suffixes {
+		if !s.ends(suffix) {
+			continue
+		}
+		if suffix == "ion" && (s.j < 0 || (s.b[s.j] != 's' && s.b[s.j] != 't')) {
+			return
+		}
+		if s.measure() > 1 {
+			s.b = s.b[:s.j+1]
+		}
+		return
+	}
+}
+
+// step5 removes a final -e and changes -ll to -l for stems with a measure
+// greater than one.
+func (s *stemmer) step5() {
+	s.j = s.last()
+	if s.b[s.last()] == 'e' {
+		if m := s.measure(); m > 1 || (m == 1 && !s.cvc(s.last()-1)) {
+			s.trimLast()
+		}
+	}
+	if s.b[s.last()] == 'l' && s.doubleCons(s.last()) && s.measure() > 1 {
+		s.trimLast()
+	}
+}
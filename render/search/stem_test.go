@@ -0,0 +1,45 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/jschaf/jsc/pkg/testing/difftest"
+)
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"a", "a"},
+		{"caresses", "caress"},
+		{"ponies", "poni"},
+		{"cats", "cat"},
+		{"agreed", "agre"},
+		{"plastered", "plaster"},
+		{"motoring", "motor"},
+		{"hopping", "hop"},
+		{"falling", "fall"},
+		{"filing", "file"},
+		{"happy", "happi"},
+		{"relational", "relat"},
+		{"conditional", "condit"},
+		{"generalization", "gener"},
+		{"hopeful", "hope"},
+		{"goodness", "good"},
+		{"adjustable", "adjust"},
+		{"effective", "effect"},
+		{"controlling", "control"},
+		{"connection", "connect"},
+		{"connected", "connect"},
+		{"running", "run"},
+		{"café", "café"},
+		{"x86", "x86"},
+		{"x²", "x²"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			difftest.AssertSame(t, tt.want, Stem(tt.word))
+		})
+	}
+}
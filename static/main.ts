@@ -678,3 +678,456 @@ class PreviewLifecycle {
     }, delayOnHover);
   }, { capture: true, passive: true });
 })();
+
+/** The search index built at compile time by render/search. */
+interface SearchIndex {
+  docs: SearchDoc[];
+  // Maps a stemmed term to a flat list of triples of doc index, section index
+  // and weight.
+  terms: Record<string, number[]>;
+}
+
+interface SearchDoc {
+  url: string;
+  title: string;
+  date?: string;
+  sections: SearchSection[];
+}
+
+interface SearchSection {
+  id?: string;
+  heading?: string;
+  text: string;
+}
+
+interface SearchResult {
+  doc: SearchDoc;
+  // The best matching section of the doc.
+  section: SearchSection;
+  score: number;
+}
+
+/**
+ * Stems a lowercase word with the Porter stemming algorithm. Must match Stem
+ * in render/search/stem.go so that query terms stem to the index terms.
+ */
+const porterStem = (word: string): string => {
+  if (word.length <= 2 || !/^[a-z]+$/.test(word)) {
+    return word;
+  }
+  let b = word;
+  let j = 0;
+
+  const cons = (i: number): boolean => {
+    switch (b[i]) {
+      case 'a':
+      case 'e':
+      case 'i':
+      case 'o':
+      case 'u':
+        return false;
+      case 'y':
+        return i === 0 || !cons(i - 1);
+      default:
+        return true;
+    }
+  };
+  // The number of vowel-consonant sequences in b[0..j].
+  const measure = (): number => {
+    let n = 0;
+    let i = 0;
+    for (; i <= j && cons(i); i++) {
+    }
+    while (i <= j) {
+      for (; i <= j && !cons(i); i++) {
+      }
+      if (i > j) {
+        break;
+      }
+      n++;
+      for (; i <= j && cons(i); i++) {
+      }
+    }
+    return n;
+  };
+  const vowelInStem = (): boolean => {
+    for (let i = 0; i <= j; i++) {
+      if (!cons(i)) {
+        return true;
+      }
+    }
+    return false;
+  };
+  const doubleCons = (i: number): boolean => i >= 1 && b[i] === b[i - 1] && cons(i);
+  const cvc = (i: number): boolean => {
+    if (i < 2 || !cons(i) || cons(i - 1) || !cons(i - 2)) {
+      return false;
+    }
+    return !'wxy'.includes(b[i]);
+  };
+  const ends = (suffix: string): boolean => {
+    if (!b.endsWith(suffix)) {
+      return false;
+    }
+    j = b.length - suffix.length - 1;
+    return true;
+  };
+  const setTo = (repl: string) => {
+    b = b.slice(0, j + 1) + repl;
+  };
+  const trimLast = () => {
+    b = b.slice(0, -1);
+  };
+  const replaceSuffixes = (pairs: [string, string][]) => {
+    for (const [suffix, repl] of pairs) {
+      if (ends(suffix)) {
+        if (measure() > 0) {
+          setTo(repl);
+        }
+        return;
+      }
+    }
+  };
+
+  // Step 1ab: plurals and -ed or -ing.
+  if (b.endsWith('s')) {
+    if (ends('sses')) {
+      setTo('ss');
+    } else if (ends('ies')) {
+      setTo('i');
+    } else if (b[b.length - 2] !== 's') {
+      trimLast();
+    }
+  }
+  if (ends('eed')) {
+    if (measure() > 0) {
+      trimLast();
+    }
+  } else if ((ends('ed') || ends('ing')) && vowelInStem()) {
+    b = b.slice(0, j + 1);
+    if (ends('at')) {
+      setTo('ate');
+    } else if (ends('bl')) {
+      setTo('ble');
+    } else if (ends('iz')) {
+      setTo('ize');
+    } else if (doubleCons(b.length - 1)) {
+      if (!'lsz'.includes(b[b.length - 1])) {
+        trimLast();
+      }
+    } else {
+      j = b.length - 1;
+      if (measure() === 1 && cvc(b.length - 1)) {
+        b += 'e';
+      }
+    }
+  }
+
+  // Step 1c: terminal y to i.
+  if (ends('y') && vowelInStem()) {
+    b = b.slice(0, -1) + 'i';
+  }
+
+  // Step 2: double suffixes to single ones.
+  replaceSuffixes([
+    ['ational', 'ate'], ['tional', 'tion'],
+    ['enci', 'ence'], ['anci', 'ance'],
+    ['izer', 'ize'],
+    ['bli', 'ble'], ['alli', 'al'], ['entli', 'ent'], ['eli', 'e'], ['ousli', 'ous'],
+    ['ization', 'ize'], ['ation', 'ate'], ['ator', 'ate'],
+    ['alism', 'al'], ['iveness', 'ive'], ['fulness', 'ful'], ['ousness', 'ous'],
+    ['aliti', 'al'], ['iviti', 'ive'], ['biliti', 'ble'],
+    ['logi', 'log'],
+  ]);
+
+  // Step 3: -ic-, -full, -ness and similar.
+  replaceSuffixes([
+    ['icate', 'ic'], ['ative', ''], ['alize', 'al'], ['iciti', 'ic'],
+    ['ical', 'ic'], ['ful', ''], ['ness', ''],
+  ]);
+
+  // Step 4: -ant, -ence and similar.
+  const step4Suffixes = [
+    'al', 'ance', 'ence', 'er', 'ic', 'able', 'ible', 'ant', 'ement', 'ment',
+    'ent', 'ion', 'ou', 'ism', 'ate', 'iti', 'ous', 'ive', 'ize',
+  ];
+  for (const suffix of step4Suffixes) {
+    if (!ends(suffix)) {
+      continue;
+    }
+    if (suffix === 'ion' && (j < 0 || (b[j] !== 's' && b[j] !== 't'))) {
+      break;
+    }
+    if (measure() > 1) {
+      b = b.slice(0, j + 1);
+    }
+    break;
+  }
+
+  // Step 5: final -e and -ll.
+  j = b.length - 1;
+  if (b.endsWith('e')) {
+    const m = measure();
+    if (m > 1 || (m === 1 && !cvc(b.length - 2))) {
+      trimLast();
+    }
+  }
+  if (b.endsWith('l') && doubleCons(b.length - 1) && measure() > 1) {
+    trimLast();
+  }
+  return b;
+};
+
+// Must match the stop words in render/search/search.go.
+const searchStopWords = new Set([
+  'a', 'an', 'and', 'are', 'as', 'at', 'be', 'by', 'for', 'from', 'in', 'is',
+  'it', 'of', 'on', 'or', 'that', 'the', 'this', 'to', 'was', 'with',
+]);
+
+const searchWordSep = /[^\p{L}\p{N}]+/u;
+
+/**
+ * Splits text into lowercase words, drops stop words and stems each word.
+ * Must match Tokenize in render/search/search.go.
+ */
+const searchTokenize = (s: string): string[] => s.toLowerCase()
+    .split(searchWordSep)
+    .filter(w => w !== '' && !searchStopWords.has(w))
+    .map(porterStem);
+
+/** Ranks docs in the search index against a query. */
+class SearchEngine {
+  static readonly maxResults = 10;
+  private readonly terms: string[];
+
+  private constructor(private readonly index: SearchIndex) {
+    this.terms = Object.keys(index.terms);
+  }
+
+  /** Fetches the search index. */
+  static async load(url: string): Promise<SearchEngine> {
+    const resp = await fetch(url);
+    if (!resp.ok) {
+      throw new Error(`fetch search index: status ${resp.status}`);
+    }
+    return new SearchEngine(await resp.json() as SearchIndex);
+  }
+
+  /**
+   * Returns the index terms matching each query token. The last token is
+   * matched as a prefix since the user is probably still typing it.
+   */
+  matchTerms(query: string): string[][] {
+    const tokens = searchTokenize(query);
+    return tokens.map((tok, i) => {
+      if (i < tokens.length - 1) {
+        return tok in this.index.terms ? [tok] : [];
+      }
+      return this.terms.filter(t => t.startsWith(tok));
+    });
+  }
+
+  /**
+   * Returns the docs containing every query token, best first. Weighs each
+   * term by its inverse document frequency so rare terms rank higher.
+   */
+  search(query: string): SearchResult[] {
+    const matches = this.matchTerms(query);
+    if (matches.length === 0 || matches.some(ts => ts.length === 0)) {
+      return [];
+    }
+    const numDocs = this.index.docs.length;
+    let docScores: Map<number, number> | null = null;
+    const sectionScores = new Map<string, number>();
+    for (const terms of matches) {
+      const tokenScores = new Map<number, number>();
+      for (const term of terms) {
+        const postings = this.index.terms[term];
+        const docs = new Set<number>();
+        for (let i = 0; i < postings.length; i += 3) {
+          docs.add(postings[i]);
+        }
+        const idf = Math.log(1 + numDocs / docs.size);
+        for (let i = 0; i < postings.length; i += 3) {
+          const [doc, section, weight] = [postings[i], postings[i + 1], postings[i + 2]];
+          const score = weight * idf;
+          tokenScores.set(doc, (tokenScores.get(doc) ?? 0) + score);
+          const key = `${doc}:${section}`;
+          sectionScores.set(key, (sectionScores.get(key) ?? 0) + score);
+        }
+      }
+      // Keep only the docs matching every token.
+      const prev: Map<number, number> | null = docScores;
+      docScores = new Map();
+      for (const [doc, score] of tokenScores) {
+        if (prev === null || prev.has(doc)) {
+          docScores.set(doc, (prev?.get(doc) ?? 0) + score);
+        }
+      }
+    }
+
+    const results: SearchResult[] = [];
+    for (const [docIdx, score] of docScores ?? []) {
+      const doc = this.index.docs[docIdx];
+      let best = 0;
+      let bestScore = -1;
+      doc.sections.forEach((_, i) => {
+        const s = sectionScores.get(`${docIdx}:${i}`) ?? 0;
+        if (s > bestScore) {
+          best = i;
+          bestScore = s;
+        }
+      });
+      results.push({ doc, section: doc.sections[best], score });
+    }
+    results.sort((a, b) => b.score - a.score);
+    return results.slice(0, SearchEngine.maxResults);
+  }
+}
+
+/**
+ * Appends a snippet of the text around the first word matching a term to
+ * parent, wrapping matching words in a mark element.
+ */
+const appendSnippet = (parent: HTMLElement, text: string, terms: Set<string>) => {
+  const snippetWords = 30;
+  const leadingWords = 8;
+  // Alternating words and separators since the regex has a capture group.
+  const parts = text.split(/([^\p{L}\p{N}]+)/u);
+  const isMatch = (word: string): boolean => {
+    const stem = porterStem(word.toLowerCase());
+    return stem !== '' && terms.has(stem);
+  };
+  let first = parts.findIndex((p, i) => i % 2 === 0 && isMatch(p));
+  if (first < 0) {
+    first = 0;
+  }
+  const start = Math.max(0, first - 2 * leadingWords);
+  const end = Math.min(parts.length, start + 2 * snippetWords);
+  if (start > 0) {
+    parent.append('…');
+  }
+  for (let i = start; i < end; i++) {
+    if (i % 2 === 0 && isMatch(parts[i])) {
+      const mark = document.createElement('mark');
+      mark.textContent = parts[i];
+      parent.append(mark);
+    } else {
+      parent.append(parts[i]);
+    }
+  }
+  if (end < parts.length) {
+    parent.append('…');
+  }
+};
+
+// Full-text search of every post.
+// The search form in the site nav is hidden until this script runs. The index
+// is fetched on the first focus of the search input to keep page loads fast.
+(() => {
+  const formEl = document.getElementById('site-search') as HTMLFormElement | null;
+  const inputEl = document.getElementById('site-search-input') as HTMLInputElement | null;
+  const resultsEl = document.getElementById('site-search-results');
+  if (formEl == null || inputEl == null || resultsEl == null) {
+    log.debug('search: no search form, skipping search');
+    return;
+  }
+  formEl.hidden = false;
+
+  let engine: Promise<SearchEngine> | null = null;
+  const loadEngine = (): Promise<SearchEngine> => {
+    if (engine === null) {
+      log.debug('search: loading index');
+      engine = SearchEngine.load('/search.json');
+      engine.catch((err) => {
+        log.warn('search: failed to load index', err);
+        engine = null;
+      });
+    }
+    return engine;
+  };
+
+  const hideResults = () => {
+    resultsEl.hidden = true;
+    resultsEl.replaceChildren();
+  };
+
+  const showResults = (eng: SearchEngine, query: string) => {
+    const results = eng.search(query);
+    resultsEl.replaceChildren();
+    if (query.trim() === '') {
+      hideResults();
+      return;
+    }
+    if (results.length === 0) {
+      const li = document.createElement('li');
+      li.className = 'site-search-empty';
+      li.textContent = 'No results';
+      resultsEl.append(li);
+      resultsEl.hidden = false;
+      return;
+    }
+    const terms = new Set(eng.matchTerms(query).flat());
+    for (const r of results) {
+      const li = document.createElement('li');
+      const link = document.createElement('a');
+      link.href = r.section.id ? `${r.doc.url}#${r.section.id}` : r.doc.url;
+      const titleEl = document.createElement('span');
+      titleEl.className = 'site-search-title';
+      titleEl.textContent = r.doc.title;
+      link.append(titleEl);
+      if (r.section.heading) {
+        const headingEl = document.createElement('span');
+        headingEl.className = 'site-search-heading';
+        headingEl.textContent = r.section.heading;
+        link.append(' › ', headingEl);
+      }
+      li.append(link);
+      if (r.section.text !== '') {
+        const snippetEl = document.createElement('p');
+        snippetEl.className = 'site-search-snippet';
+        appendSnippet(snippetEl, r.section.text, terms);
+        li.append(snippetEl);
+      }
+      resultsEl.append(li);
+    }
+    resultsEl.hidden = false;
+  };
+
+  let inputTimer = 0;
+  const debounceMs = 100;
+  inputEl.addEventListener('focus', () => void loadEngine(), { passive: true });
+  inputEl.addEventListener('input', () => {
+    clearTimeout(inputTimer);
+    inputTimer = setTimeout(async () => {
+      try {
+        showResults(await loadEngine(), inputEl.value);
+      } catch (err) {
+        log.warn('search: failed to search', err);
+      }
+    }, debounceMs);
+  }, { passive: true });
+
+  // Go to the first result on enter.
+  formEl.addEventListener('submit', (ev) => {
+    ev.preventDefault();
+    const first = resultsEl.querySelector('a');
+    if (first) {
+      location.href = first.href;
+    }
+  });
+
+  inputEl.addEventListener('keydown', (ev) => {
+    if (ev.key === 'Escape') {
+      inputEl.value = '';
+      hideResults();
+    }
+  });
+
+  // Hide the results when clicking outside the search form.
+  document.addEventListener('click', (ev) => {
+    if (ev.target instanceof Node && !formEl.contains(ev.target)) {
+      resultsEl.hidden = true;
+    }
+  }, { passive: true });
+})();